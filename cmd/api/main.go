@@ -1,26 +1,35 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	stdhttp "net/http"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 
 	"github.com/sonsonha/eng-noting/internal/config"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
 	httphandler "github.com/sonsonha/eng-noting/internal/http"
 	infraai "github.com/sonsonha/eng-noting/internal/infrastructure/ai"
-	"github.com/sonsonha/eng-noting/internal/infrastructure/ai/openai"
 	infrarepo "github.com/sonsonha/eng-noting/internal/infrastructure/repository"
 	"github.com/sonsonha/eng-noting/internal/usecase"
 )
 
 func main() {
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Database setup
 	db, err := sql.Open("postgres", cfg.DatabaseURL)
@@ -43,22 +52,78 @@ func main() {
 	reviewRepo := infrarepo.NewReviewRepository(db)
 	reviewQueueRepo := infrarepo.NewReviewQueueRepository(db)
 	wordStatsRepo := infrarepo.NewWordStatsRepository(db)
+	tagRepo := infrarepo.NewTagRepository(db)
+	jobRepo := infrarepo.NewJobRepository(db)
+	distractorRepo := infrarepo.NewDistractorRepository(db)
+	clozeRepo := infrarepo.NewClozeRepository(db)
+	scheduleRepo := infrarepo.NewScheduleRepository(db)
+	settingsRepo := infrarepo.NewSettingsRepository(db)
+	txManager := infrarepo.NewTxManager(db)
+
+	// Infrastructure layer: Session store
+	var sessionRepo session.SessionRepository
+	switch cfg.SessionStore {
+	case "postgres":
+		sessionRepo = infrarepo.NewSessionRepository(db)
+	case "redis":
+		opts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			log.Fatalf("Invalid REDIS_URL: %v", err)
+		}
+		redisClient := redis.NewClient(opts)
+		defer redisClient.Close()
+		if err := redisClient.Ping(context.Background()).Err(); err != nil {
+			log.Fatalf("Failed to ping redis: %v", err)
+		}
+		sessionRepo = infrarepo.NewRedisSessionRepository(redisClient)
+	default:
+		log.Fatalf("Unknown SESSION_STORE %q (want postgres or redis)", cfg.SessionStore)
+	}
+	log.Printf("Session store: %s", cfg.SessionStore)
 
 	// Infrastructure layer: AI Service
-	aiClient := openai.NewClient(cfg.AIAPIKey)
-	if aiClient == nil {
-		log.Println("Warning: AI client not initialized (AI_API_KEY not set)")
+	aiClient, err := infraai.NewClient(infraai.ProviderConfig{
+		Provider:    cfg.AIProvider,
+		APIKey:      cfg.AIAPIKey,
+		Model:       cfg.AIModel,
+		BaseURL:     cfg.AIBaseURL,
+		Temperature: cfg.AITemperature,
+		Timeout:     cfg.AITimeout,
+	})
+	if err != nil {
+		log.Printf("Warning: AI client not initialized: %v", err)
+	} else {
+		log.Printf("AI provider: %s", cfg.AIProvider)
 	}
 	aiService := infraai.NewAIService(aiClient)
 
 	// Use case layer
 	mpsService := usecase.NewMPSService()
-	wordUseCase := usecase.NewWordUseCase(wordRepo, aiService)
-	reviewUseCase := usecase.NewReviewUseCase(reviewRepo, wordRepo)
-	sessionUseCase := usecase.NewSessionUseCase(reviewQueueRepo, wordStatsRepo, reviewRepo, mpsService)
+	reviewScheduler := usecase.NewReviewScheduler(settingsRepo, scheduleRepo)
+	wordUseCase := usecase.NewWordUseCase(wordRepo, jobRepo, reviewRepo, reviewScheduler, txManager)
+	sessionExpiry := session.Expiry{Idle: cfg.SessionTTL, Finished: cfg.SessionRetention}
+	reviewUseCase := usecase.NewReviewUseCase(reviewRepo, wordRepo, clozeRepo, sessionRepo, sessionExpiry, reviewScheduler, txManager)
+	exerciseBuilder := usecase.NewExerciseBuilder(wordRepo, distractorRepo, clozeRepo, aiService)
+	sessionUseCase := usecase.NewSessionUseCase(sessionRepo, sessionExpiry, reviewQueueRepo, wordStatsRepo, reviewRepo, mpsService, exerciseBuilder, reviewScheduler, settingsRepo)
+	tagUseCase := usecase.NewTagUseCase(tagRepo)
+	settingsUseCase := usecase.NewSettingsUseCase(settingsRepo)
+
+	if n, err := wordUseCase.RefreshLemmas(context.Background()); err != nil {
+		log.Printf("Warning: word lemmas not refreshed: %v", err)
+	} else if n > 0 {
+		log.Printf("Refreshed the lemmas of %d words", n)
+	}
+
+	// Background workers
+	explanationWorker := usecase.NewExplanationWorker(jobRepo, wordRepo, distractorRepo, aiService, usecase.ExplanationWorkerConfig{
+		Concurrency:  cfg.AIWorkers,
+		PollInterval: cfg.AIPollInterval,
+		// ExplainWordSafe makes up to two calls, each bounded by the timeout
+		StaleAfter: 3*cfg.AITimeout + time.Minute,
+	})
 
 	// Presentation layer: HTTP handlers
-	handler := httphandler.NewHandler(wordUseCase, reviewUseCase, sessionUseCase)
+	handler := httphandler.NewHandler(wordUseCase, reviewUseCase, sessionUseCase, tagUseCase, settingsUseCase)
 
 	// Router setup
 	r := chi.NewRouter()
@@ -73,13 +138,47 @@ func main() {
 		// Word endpoints
 		r.Post("/words", handler.CreateWord)
 		r.Get("/words", handler.ListWords)
+		r.Post("/words/explanations/retry", handler.RetryFailedExplanations)
 		r.Get("/words/{id}", handler.GetWord)
+		r.Patch("/words/{id}", handler.UpdateWord)
+		r.Delete("/words/{id}", handler.DeleteWord)
+		r.Post("/words/{id}/archive", handler.ArchiveWord)
+		r.Post("/words/{id}/unarchive", handler.UnarchiveWord)
+		r.Post("/words/{id}/merge", handler.MergeWords)
+		r.Post("/words/{id}/explanation/retry", handler.RetryExplanation)
+		r.Post("/words/{id}/explanation/regenerate", handler.RegenerateExplanation)
+		r.Patch("/words/{id}/explanation", handler.EditExplanation)
+		r.Get("/words/{id}/explanation/versions", handler.ListExplanationVersions)
+		r.Post("/words/{id}/explanation/versions/{version}/restore", handler.RestoreExplanationVersion)
+		r.Post("/words/{id}/contexts", handler.AddWordContext)
+		r.Get("/words/{id}/reviews", handler.ListWordReviews)
+		r.Patch("/words/{id}/contexts/{contextID}", handler.UpdateWordContext)
+		r.Delete("/words/{id}/contexts/{contextID}", handler.DeleteWordContext)
+		r.Put("/words/{id}/tags/{tagID}", handler.TagWord)
+		r.Delete("/words/{id}/tags/{tagID}", handler.UntagWord)
+
+		// Tag (deck) endpoints
+		r.Post("/tags", handler.CreateTag)
+		r.Get("/tags", handler.ListTags)
+		r.Patch("/tags/{id}", handler.RenameTag)
+		r.Delete("/tags/{id}", handler.DeleteTag)
 
 		// Review endpoints
 		r.Post("/reviews/session", handler.StartSession)
 		r.Get("/reviews/session/current", handler.GetCurrentItem)
 		r.Post("/reviews/session/advance", handler.AdvanceSession)
+		r.Post("/reviews/session/abandon", handler.AbandonSession)
+		r.Get("/reviews/session/summary", handler.GetSessionSummary)
+		r.Get("/reviews/sessions", handler.ListSessions)
+		r.Get("/reviews/history", handler.ListReviewHistory)
 		r.Post("/reviews/submit", handler.SubmitReview)
+		r.Post("/reviews/sync", handler.SyncReviews)
+		r.Post("/reviews/undo", handler.UndoReview)
+		r.Post("/reviews/session/match", handler.SubmitMatch)
+
+		// Settings endpoints
+		r.Get("/settings", handler.GetSettings)
+		r.Patch("/settings", handler.UpdateSettings)
 	})
 
 	// Health check endpoint (no auth)
@@ -88,10 +187,56 @@ func main() {
 		w.Write([]byte("OK"))
 	})
 
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		explanationWorker.Run(ctx)
+	}()
+
+	// Redis expires sessions by itself
+	if cfg.SessionStore == "postgres" {
+		sessionCleaner := usecase.NewSessionCleaner(sessionRepo, cfg.SessionCleanupInterval)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			sessionCleaner.Run(ctx)
+		}()
+	}
+
 	// Start server
 	addr := fmt.Sprintf(":%s", cfg.Port)
-	log.Printf("Server starting on %s", addr)
-	if err := stdhttp.ListenAndServe(addr, r); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	server := &stdhttp.Server{Addr: addr, Handler: r}
+
+	go func() {
+		log.Printf("Server starting on %s", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, stdhttp.ErrServerClosed) {
+			log.Fatalf("Server failed to start: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown failed: %v", err)
+	}
+
+	// Let workers finish the jobs they hold; anything cut off here is
+	// picked up again once it goes stale
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		log.Println("Workers did not stop in time")
 	}
 }
@@ -1,35 +1,110 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
 	DatabaseURL string
 	Port        string
-	AIAPIKey    string
+
+	AIProvider    string // openai, openai-compatible, ollama or fake
+	AIAPIKey      string
+	AIModel       string // empty for the provider's default
+	AIBaseURL     string // empty for the provider's default
+	AITemperature float32
+	AITimeout     time.Duration
+
+	AIWorkers      int           // explanation jobs processed concurrently
+	AIPollInterval time.Duration // how often idle workers look for new jobs
+
+	SessionStore           string        // postgres or redis
+	SessionTTL             time.Duration // how long an idle review session is kept
+	SessionRetention       time.Duration // how long a finished review session is kept
+	SessionCleanupInterval time.Duration // how often expired sessions are deleted (postgres only)
+	RedisURL               string        // e.g. redis://localhost:6379/0
 }
 
-func LoadConfig() *Config {
-	return &Config{
-		DatabaseURL: mustEnv("DATABASE_URL"),
-		Port:        envOr("PORT", "8080"),
-		AIAPIKey:    os.Getenv("AI_API_KEY"),
+// LoadConfig reads the configuration from the environment. It reports
+// every missing or malformed variable at once.
+func LoadConfig() (*Config, error) {
+	var e env
+	cfg := &Config{
+		DatabaseURL: e.required("DATABASE_URL"),
+		Port:        e.or("PORT", "8080"),
+
+		AIProvider:    e.or("AI_PROVIDER", "openai"),
+		AIAPIKey:      os.Getenv("AI_API_KEY"),
+		AIModel:       os.Getenv("AI_MODEL"),
+		AIBaseURL:     os.Getenv("AI_BASE_URL"),
+		AITemperature: float32(e.float("AI_TEMPERATURE", 0.3)),
+		AITimeout:     e.duration("AI_TIMEOUT", 60*time.Second),
+
+		AIWorkers:      e.int("AI_WORKERS", 2),
+		AIPollInterval: e.duration("AI_POLL_INTERVAL", 2*time.Second),
+
+		SessionStore:           e.or("SESSION_STORE", "postgres"),
+		SessionTTL:             e.duration("SESSION_TTL", 24*time.Hour),
+		SessionRetention:       e.duration("SESSION_RETENTION", 30*24*time.Hour),
+		SessionCleanupInterval: e.duration("SESSION_CLEANUP_INTERVAL", 10*time.Minute),
+		RedisURL:               e.or("REDIS_URL", "redis://localhost:6379/0"),
 	}
+	if err := errors.Join(e.errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// env reads environment variables, collecting the problems it finds
+type env struct {
+	errs []error
 }
 
-func mustEnv(key string) string {
+func (e *env) required(key string) string {
 	value := os.Getenv(key)
 	if value == "" {
-		panic("missing env:" + key)
+		e.errs = append(e.errs, fmt.Errorf("missing env %s", key))
 	}
 	return value
 }
 
-func envOr(key, defaultValue string) string {
+func (e *env) or(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
 		return defaultValue
 	}
 	return value
 }
+
+func (e *env) int(key string, defaultValue int) int {
+	return parseEnv(e, key, defaultValue, strconv.Atoi)
+}
+
+func (e *env) float(key string, defaultValue float64) float64 {
+	return parseEnv(e, key, defaultValue, func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	})
+}
+
+func (e *env) duration(key string, defaultValue time.Duration) time.Duration {
+	return parseEnv(e, key, defaultValue, time.ParseDuration)
+}
+
+// parseEnv parses the variable key, returning defaultValue if it is unset
+// or malformed
+func parseEnv[T any](e *env, key string, defaultValue T, parse func(string) (T, error)) T {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	v, err := parse(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("invalid env %s=%q", key, value))
+		return defaultValue
+	}
+	return v
+}
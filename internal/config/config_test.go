@@ -0,0 +1,35 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigDefaults(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/eng")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Port != "8080" || cfg.AIWorkers != 2 || cfg.SessionTTL != 24*time.Hour || cfg.SessionRetention != 30*24*time.Hour {
+		t.Errorf("unexpected defaults %+v", cfg)
+	}
+}
+
+func TestLoadConfigReportsEveryProblem(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("AI_WORKERS", "two")
+	t.Setenv("AI_TIMEOUT", "60")
+
+	_, err := LoadConfig()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, key := range []string{"DATABASE_URL", "AI_WORKERS", "AI_TIMEOUT"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected %s reported, got %v", key, err)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package ai
 
+import "context"
+
 // AIExplanation represents AI-generated explanation for a word
 type AIExplanation struct {
 	Definition   string
@@ -7,9 +9,25 @@ type AIExplanation struct {
 	ExampleBad   string
 	PartOfSpeech string
 	CEFRLevel    string
+
+	Model         string // model that produced the explanation
+	PromptVersion string // PromptVersion at the time it was produced
+}
+
+// ExplainRequest describes the word to explain
+type ExplainRequest struct {
+	Word     string
+	Contexts []string // sentences the user met the word in, oldest first
+	Hint     string   // optional steer from the user, e.g. "the finance meaning"
 }
 
 // AIService defines the interface for AI operations
 type AIService interface {
-	ExplainWord(word, context string) (*AIExplanation, error)
+	ExplainWord(ctx context.Context, req ExplainRequest) (*AIExplanation, error)
+	// SuggestDistractors returns up to n wrong options for a multiple-choice
+	// question about word
+	SuggestDistractors(ctx context.Context, word, definition string, n int) ([]string, error)
+	// WriteClozeSentence returns an example sentence using word, for a
+	// fill-in-the-blank exercise; definition may be empty
+	WriteClozeSentence(ctx context.Context, word, definition string) (string, error)
 }
@@ -1,5 +1,11 @@
 package ai
 
+import "context"
+
+// Client sends a single chat completion to a language model provider and
+// returns the raw text of its answer
 type Client interface {
-	ExplainWord(systemPrompt, userPrompt string) (string, error)
+	Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error)
+	// Model returns the name of the model answering the completions
+	Model() string
 }
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type clozeResponse struct {
+	Sentence string `json:"sentence"`
+}
+
+// WriteClozeSentenceSafe asks the AI client for an example sentence that uses
+// word in the given meaning, to be turned into a fill-in-the-blank exercise.
+// The caller checks that the word can actually be found in the sentence.
+func WriteClozeSentenceSafe(ctx context.Context, client Client, word, definition string) (string, error) {
+	if client == nil {
+		return "", fmt.Errorf("AI client is nil")
+	}
+
+	response, err := client.Complete(ctx, clozeSystemPrompt, clozePrompt(word, definition))
+	if err != nil {
+		return "", fmt.Errorf("AI call failed: %w", err)
+	}
+
+	var resp clozeResponse
+	if err := json.Unmarshal([]byte(response), &resp); err != nil {
+		return "", fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	sentence := strings.TrimSpace(resp.Sentence)
+	if sentence == "" {
+		return "", fmt.Errorf("no sentence in response")
+	}
+
+	return sentence, nil
+}
+
+const clozeSystemPrompt = `
+You write fill-in-the-blank vocabulary exercises for non-native English learners.
+
+Rules:
+- Write ONE natural sentence of 8 to 20 words
+- Use the target word exactly once, in the given meaning
+- The rest of the sentence must give enough clues to guess the word
+- Use simple English, suitable for CEFR A2–B1 learners
+`
+
+func clozePrompt(word, definition string) string {
+	meaning := ""
+	if definition != "" {
+		meaning = `Meaning to use: "` + definition + `"
+`
+	}
+
+	return `
+Target word: "` + word + `"
+` + meaning + `
+Output in JSON only: {"sentence": "..."}
+`
+}
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type distractorResponse struct {
+	Distractors []string `json:"distractors"`
+}
+
+// SuggestDistractorsSafe asks the AI client for n wrong but plausible options
+// for a multiple-choice question about word, and keeps the usable ones
+func SuggestDistractorsSafe(ctx context.Context, client Client, word, definition string, n int) ([]string, error) {
+	if client == nil {
+		return nil, fmt.Errorf("AI client is nil")
+	}
+
+	response, err := client.Complete(ctx, distractorSystemPrompt, distractorPrompt(word, definition, n))
+	if err != nil {
+		return nil, fmt.Errorf("AI call failed: %w", err)
+	}
+
+	var resp distractorResponse
+	if err := json.Unmarshal([]byte(response), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	var distractors []string
+	for _, d := range resp.Distractors {
+		d = strings.TrimSpace(d)
+		if d == "" || strings.Contains(strings.ToLower(d), strings.ToLower(word)) {
+			continue
+		}
+		distractors = append(distractors, d)
+	}
+	if len(distractors) == 0 {
+		return nil, fmt.Errorf("no usable distractors in response")
+	}
+
+	return distractors, nil
+}
+
+const distractorSystemPrompt = `
+You write multiple-choice vocabulary questions for non-native English learners.
+
+Rules:
+- Wrong options must be real English words or expressions
+- They must have the same part of speech and a similar level as the target
+- They must clearly NOT fit the given meaning
+- Never use the target word or any form of it
+`
+
+func distractorPrompt(word, definition string, n int) string {
+	return `
+Target word: "` + word + `"
+Meaning being tested: "` + definition + `"
+
+Task:
+Give ` + strconv.Itoa(n) + ` wrong options a learner might confuse with the target word.
+
+Output in JSON only: {"distractors": ["...", "..."]}
+`
+}
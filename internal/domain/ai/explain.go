@@ -1,6 +1,7 @@
 package ai
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -15,8 +16,9 @@ type Explanation struct {
 }
 
 // ExplainWordSafe calls the AI client, parses and validates the response,
-// and retries once on failure
-func ExplainWordSafe(client Client, word, context string) (*Explanation, error) {
+// and retries once on failure. The most recent of req.Contexts are used to
+// pick the intended meaning, unless req.Hint asks for another one.
+func ExplainWordSafe(ctx context.Context, client Client, req ExplainRequest) (*Explanation, error) {
 	if client == nil {
 		return nil, fmt.Errorf("AI client is nil")
 	}
@@ -30,7 +32,7 @@ func ExplainWordSafe(client Client, word, context string) (*Explanation, error)
 			time.Sleep(time.Second * time.Duration(attempt))
 		}
 
-		response, err := client.ExplainWord(systemPrompt, explanationPrompt(word, context))
+		response, err := client.Complete(ctx, systemPrompt, explanationPrompt(req))
 		if err != nil {
 			lastErr = fmt.Errorf("AI call failed: %w", err)
 			continue
@@ -42,7 +44,7 @@ func ExplainWordSafe(client Client, word, context string) (*Explanation, error)
 			continue
 		}
 
-		if !ValidateExplanation(word, exp) {
+		if !ValidateExplanation(req.Word, exp) {
 			lastErr = fmt.Errorf("validation failed for explanation")
 			continue
 		}
@@ -1,5 +1,7 @@
 package ai
 
+import "strings"
+
 const systemPrompt = `
 You are an English teacher for non-native learners.
 Your explanations must be:
@@ -16,13 +18,22 @@ Rules:
 - Keep sentences short
 `
 
-func explanationPrompt(word, context string) string {
+// PromptVersion identifies the prompts below. Bump it whenever they change
+// so stored explanations can be traced back to the prompt that made them.
+const PromptVersion = "explain-v2"
+
+// maxPromptContexts caps how many of the user's contexts go into a prompt
+const maxPromptContexts = 3
+
+func explanationPrompt(req ExplainRequest) string {
 	return `
-Word: "` + word + `"
-Context sentence (if any): "` + context + `"
+Word: "` + req.Word + `"
+Context sentences from the learner's reading (if any):
+` + contextLines(PickContexts(req.Contexts, maxPromptContexts)) + `
+` + hintLine(req.Hint) + `
 
 Task:
-1. Give a simple definition
+1. Give a simple definition of the meaning used in the context sentences
 2. Give ONE correct example sentence
 3. Give ONE incorrect or unnatural example sentence
 4. State the part of speech
@@ -31,3 +42,43 @@ Task:
 Output in JSON only.
 `
 }
+
+// PickContexts returns up to n distinct, non-empty contexts, preferring the
+// most recent ones (contexts are passed oldest first)
+func PickContexts(contexts []string, n int) []string {
+	picked := make([]string, 0, n)
+	seen := make(map[string]bool)
+
+	for i := len(contexts) - 1; i >= 0 && len(picked) < n; i-- {
+		c := strings.TrimSpace(contexts[i])
+		key := strings.ToLower(c)
+		if c == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		picked = append(picked, c)
+	}
+
+	return picked
+}
+
+func hintLine(hint string) string {
+	hint = strings.TrimSpace(hint)
+	if hint == "" {
+		return ""
+	}
+	return `The learner asks for this meaning: "` + hint + `". Explain that meaning even if the context sentences suggest another one.
+`
+}
+
+func contextLines(contexts []string) string {
+	if len(contexts) == 0 {
+		return "- (none)"
+	}
+
+	lines := make([]string, len(contexts))
+	for i, c := range contexts {
+		lines[i] = `- "` + c + `"`
+	}
+	return strings.Join(lines, "\n")
+}
@@ -3,5 +3,15 @@ package domain
 import "errors"
 
 var (
-	ErrWordNotFound = errors.New("word not found")
+	ErrWordNotFound    = errors.New("word not found")
+	ErrContextNotFound = errors.New("word context not found")
+	ErrTagNotFound     = errors.New("tag not found")
+	ErrTagExists       = errors.New("tag already exists")
+	ErrVersionNotFound = errors.New("explanation version not found")
+	ErrAIDataChanged   = errors.New("explanation changed in the meantime")
+	ErrSessionNotFound = errors.New("review session not found")
+	ErrSessionMoved    = errors.New("review session moved on")
+	ErrReviewExists    = errors.New("word already reviewed in this session")
+	ErrReviewKeyUsed   = errors.New("idempotency key already used")
+	ErrReviewNotFound  = errors.New("review not found")
 )
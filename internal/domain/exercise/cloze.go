@@ -0,0 +1,79 @@
+package exercise
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// MinClozeWords is the fewest words a sentence needs to make a cloze; a
+// shorter one gives too little context to guess the gap from
+const MinClozeWords = 6
+
+// ClozeSource tells where a cloze sentence came from
+type ClozeSource string
+
+const (
+	ClozeFromContext ClozeSource = "context" // one of the user's own contexts
+	ClozeFromAI      ClozeSource = "ai"      // written by the AI for the word
+)
+
+// Cloze is a fill-in-the-blank exercise: a sentence with the word blanked out
+type Cloze struct {
+	Text     string // the word text the cloze was built for
+	Sentence string // the sentence with Blank in place of the word
+	Answer   string // the word as it appeared in the sentence, possibly inflected
+	Hint     string // first letter of the answer
+	Source   ClozeSource
+}
+
+// NewCloze blanks text out of sentence. It returns nil if text does not
+// occur in the sentence or the sentence is shorter than MinClozeWords.
+func NewCloze(sentence, text string, source ClozeSource) *Cloze {
+	sentence = word.CleanText(sentence)
+	if len(tokenize(sentence)) < MinClozeWords {
+		return nil
+	}
+
+	span, ok := Locate(sentence, text)
+	if !ok {
+		return nil
+	}
+
+	answer := sentence[span.Start:span.End]
+	return &Cloze{
+		Text:     text,
+		Sentence: sentence[:span.Start] + Blank + sentence[span.End:],
+		Answer:   answer,
+		Hint:     firstLetter(answer),
+		Source:   source,
+	}
+}
+
+// ContextCloze builds a cloze from the most recent of w's contexts that
+// can make one, or returns nil if none can
+func ContextCloze(w *word.Word) *Cloze {
+	for i := len(w.Contexts) - 1; i >= 0; i-- {
+		if c := NewCloze(w.Contexts[i].Sentence, w.Text, ClozeFromContext); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+func firstLetter(s string) string {
+	for _, r := range s {
+		return strings.ToLower(string(r))
+	}
+	return ""
+}
+
+// ClozeRepository caches the cloze of each word, so that a session shows
+// and grades the same sentence without rebuilding or regenerating it
+type ClozeRepository interface {
+	// Get returns the cached cloze of a word, or nil if there is none
+	Get(ctx context.Context, wordID string) (*Cloze, error)
+	// Store caches the cloze of a word, replacing the previous one
+	Store(ctx context.Context, wordID string, c *Cloze) error
+}
@@ -0,0 +1,171 @@
+package exercise
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"unicode"
+
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// Blank marks the gap in a gapped sentence
+const Blank = "_____"
+
+// MCQOptions is the number of choices in a multiple-choice question
+const MCQOptions = 4
+
+// PromptKind tells what an MCQ prompt shows
+type PromptKind string
+
+const (
+	PromptDefinition PromptKind = "definition" // pick the word that has this meaning
+	PromptContext    PromptKind = "context"    // pick the word that fills the gap
+)
+
+// MCQ is a multiple-choice question whose answer is the word's text.
+// The options are word texts in random order, one of them the answer.
+type MCQ struct {
+	Prompt     string
+	PromptKind PromptKind
+	Options    []string
+}
+
+// NewMCQ builds a question for w from its definition or, failing that,
+// from one of its contexts with the word blanked out. It returns nil if
+// there is nothing to ask or fewer than MCQOptions-1 distractors.
+func NewMCQ(w *word.Word, distractors []string) *MCQ {
+	distractors = MergeDistractors(w.Text, MCQOptions-1, distractors)
+	if len(distractors) < MCQOptions-1 {
+		return nil
+	}
+
+	q := &MCQ{}
+	if w.AIData != nil && w.AIData.Definition != "" {
+		q.Prompt = w.AIData.Definition
+		q.PromptKind = PromptDefinition
+	} else if gapped, ok := gappedContext(w); ok {
+		q.Prompt = gapped
+		q.PromptKind = PromptContext
+	} else {
+		return nil
+	}
+
+	q.Options = append([]string{w.Text}, distractors...)
+	rand.Shuffle(len(q.Options), func(i, j int) {
+		q.Options[i], q.Options[j] = q.Options[j], q.Options[i]
+	})
+	return q
+}
+
+// gappedContext blanks the word out of its most recent context that contains it
+func gappedContext(w *word.Word) (string, bool) {
+	for i := len(w.Contexts) - 1; i >= 0; i-- {
+		if gapped, ok := BlankOut(w.Contexts[i].Sentence, w.Text); ok {
+			return gapped, true
+		}
+	}
+	return "", false
+}
+
+// CheckChoice returns true if the chosen option is the word
+func CheckChoice(choice, text string) bool {
+	return word.Normalize(choice) == word.Normalize(text)
+}
+
+// MergeDistractors returns up to n distinct options from the given lists,
+// in order, skipping anything that is the target word or an inflection of it
+func MergeDistractors(target string, n int, lists ...[]string) []string {
+	targetLemma := word.Lemma(target)
+	seen := map[string]bool{targetLemma: true}
+
+	var merged []string
+	for _, list := range lists {
+		for _, d := range list {
+			if len(merged) == n {
+				return merged
+			}
+			d = word.CleanText(d)
+			lemma := word.Lemma(d)
+			if d == "" || seen[lemma] {
+				continue
+			}
+			seen[lemma] = true
+			merged = append(merged, d)
+		}
+	}
+	return merged
+}
+
+// Span is a token range [Start, End) of a sentence in bytes
+type Span struct {
+	Start, End int
+}
+
+// Locate finds the first occurrence of text in sentence, matching token by
+// token on lemmas so inflected forms are found too ("ran" for "run",
+// "gave up" for "give up")
+func Locate(sentence, text string) (Span, bool) {
+	want := strings.Fields(word.Lemma(text))
+	if len(want) == 0 {
+		return Span{}, false
+	}
+
+	tokens := tokenize(sentence)
+	for i := 0; i+len(want) <= len(tokens); i++ {
+		match := true
+		for j, lemma := range want {
+			if word.Lemma(sentence[tokens[i+j].Start:tokens[i+j].End]) != lemma {
+				match = false
+				break
+			}
+		}
+		if match {
+			return Span{Start: tokens[i].Start, End: tokens[i+len(want)-1].End}, true
+		}
+	}
+	return Span{}, false
+}
+
+// BlankOut replaces the first occurrence of text in sentence with Blank
+func BlankOut(sentence, text string) (string, bool) {
+	span, ok := Locate(sentence, text)
+	if !ok {
+		return "", false
+	}
+	return sentence[:span.Start] + Blank + sentence[span.End:], true
+}
+
+// tokenize splits a sentence into word tokens (letters, digits, apostrophes
+// and inner hyphens), returning their byte ranges
+func tokenize(sentence string) []Span {
+	var tokens []Span
+	start := -1
+	for i, r := range sentence {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’' ||
+			(r == '-' && start >= 0)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			tokens = append(tokens, Span{Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Span{Start: start, End: len(sentence)})
+	}
+	return tokens
+}
+
+// DistractorRepository supplies wrong options for multiple-choice questions
+type DistractorRepository interface {
+	// Candidates returns texts of the user's other active words that look
+	// like plausible confusions for w: same part of speech if known,
+	// closest CEFR level first
+	Candidates(ctx context.Context, w *word.Word, limit int) ([]string, error)
+	// Cached returns the AI-generated distractors stored for a word
+	Cached(ctx context.Context, wordID string) ([]string, error)
+	// Store caches AI-generated distractors for a word
+	Store(ctx context.Context, wordID string, distractors []string) error
+}
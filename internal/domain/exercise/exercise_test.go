@@ -0,0 +1,145 @@
+package exercise
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+func TestBlankOutFindsInflectedForms(t *testing.T) {
+	cases := []struct {
+		sentence, text, want string
+	}{
+		{"She stayed resilient after the failure.", "resilient", "She stayed _____ after the failure."},
+		{"He ran to the station.", "run", "He _____ to the station."},
+		{"They gave up too early.", "give up", "They _____ too early."},
+		{"Studies show it works.", "study", "_____ show it works."},
+	}
+
+	for _, c := range cases {
+		got, ok := BlankOut(c.sentence, c.text)
+		if !ok || got != c.want {
+			t.Errorf("BlankOut(%q, %q) = %q, %v; want %q", c.sentence, c.text, got, ok, c.want)
+		}
+	}
+
+	if _, ok := BlankOut("Nothing to see here.", "resilient"); ok {
+		t.Error("expected no match for a missing word")
+	}
+}
+
+func TestMergeDistractorsSkipsTargetAndDuplicates(t *testing.T) {
+	got := MergeDistractors("run", 3,
+		[]string{"running", "walk", "Walk"},
+		[]string{"jump", "swim", "fly"},
+	)
+
+	want := []string{"walk", "jump", "swim"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestNewMCQIncludesAnswerOnce(t *testing.T) {
+	w := &word.Word{
+		Text:   "resilient",
+		AIData: &word.WordAIData{Definition: "able to recover quickly"},
+	}
+
+	q := NewMCQ(w, []string{"fragile", "anxious", "reluctant", "eager"})
+	if q == nil {
+		t.Fatal("expected a question")
+	}
+	if q.PromptKind != PromptDefinition || len(q.Options) != MCQOptions {
+		t.Fatalf("unexpected question %+v", q)
+	}
+
+	answers := 0
+	for _, o := range q.Options {
+		if CheckChoice(o, w.Text) {
+			answers++
+		}
+	}
+	if answers != 1 {
+		t.Fatalf("expected the answer exactly once, got %d in %v", answers, q.Options)
+	}
+}
+
+func TestNewMCQFallsBackToContext(t *testing.T) {
+	w := &word.Word{
+		Text:     "resilient",
+		Contexts: []word.WordContext{{Sentence: "Kids are resilient."}},
+	}
+
+	q := NewMCQ(w, []string{"fragile", "anxious", "reluctant"})
+	if q == nil || q.PromptKind != PromptContext || q.Prompt != "Kids are _____." {
+		t.Fatalf("unexpected question %+v", q)
+	}
+
+	if NewMCQ(w, []string{"fragile"}) != nil {
+		t.Fatal("expected no question without enough distractors")
+	}
+}
+
+func TestNewClozeKeepsInflectedAnswer(t *testing.T) {
+	c := NewCloze("  He ran all the way to  the station. ", "run", ClozeFromContext)
+	if c == nil {
+		t.Fatal("expected a cloze")
+	}
+	if c.Sentence != "He _____ all the way to the station." || c.Answer != "ran" || c.Hint != "r" {
+		t.Fatalf("unexpected cloze %+v", c)
+	}
+}
+
+func TestNewClozeRejectsShortOrUnrelatedSentences(t *testing.T) {
+	if c := NewCloze("He ran away.", "run", ClozeFromContext); c != nil {
+		t.Errorf("expected no cloze for a short sentence, got %+v", c)
+	}
+	if c := NewCloze("She walked all the way to the station.", "run", ClozeFromContext); c != nil {
+		t.Errorf("expected no cloze without the word, got %+v", c)
+	}
+}
+
+func TestContextClozePrefersRecentUsableContext(t *testing.T) {
+	w := &word.Word{
+		Text: "give up",
+		Contexts: []word.WordContext{
+			{Sentence: "Never give up on the things you love."},
+			{Sentence: "They gave up too early in the game."},
+			{Sentence: "Don't give up."},
+		},
+	}
+
+	c := ContextCloze(w)
+	if c == nil || c.Sentence != "They _____ too early in the game." || c.Answer != "gave up" {
+		t.Fatalf("unexpected cloze %+v", c)
+	}
+}
+
+func TestMergeDistractorsKeepsLookalikeWords(t *testing.T) {
+	// Different words that only look like inflections of each other are
+	// valid options; real inflections are not
+	cases := []struct {
+		target string
+		lists  [][]string
+		want   []string
+	}{
+		{"plan", [][]string{{"plane", "planned", "plant"}}, []string{"plane", "plant"}},
+		{"plane", [][]string{{"plan", "planes"}, {"plans"}}, []string{"plan"}},
+		{"hope", [][]string{{"hop", "hoping", "hopping"}}, []string{"hop"}},
+		{"care", [][]string{{"car", "cars", "caring"}}, []string{"car"}},
+	}
+
+	for _, c := range cases {
+		got := MergeDistractors(c.target, 3, c.lists...)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("%s: expected %v, got %v", c.target, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package exercise
+
+import (
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// Bounds on the number of words on a matching board
+const (
+	MinMatchPairs = 4
+	MaxMatchPairs = 6
+)
+
+// MatchCard is one card on a matching board
+type MatchCard struct {
+	ID   string
+	Text string
+}
+
+// MatchBoard is a matching exercise over several words: the words on one
+// side, their definitions in random order on the other. Definition cards
+// get board-local IDs so the client cannot tell which word they belong to.
+type MatchBoard struct {
+	Words       []MatchCard       // ID is the word ID
+	Definitions []MatchCard       // ID is local to the board
+	Key         map[string]string // word ID -> definition ID; never sent to the client
+}
+
+// NewMatchBoard builds a board from words that all have a definition. It
+// returns nil for fewer than MinMatchPairs or more than MaxMatchPairs words.
+func NewMatchBoard(words []*word.Word) *MatchBoard {
+	if len(words) < MinMatchPairs || len(words) > MaxMatchPairs {
+		return nil
+	}
+
+	order := rand.Perm(len(words))
+	b := &MatchBoard{
+		Words:       make([]MatchCard, len(words)),
+		Definitions: make([]MatchCard, len(words)),
+		Key:         make(map[string]string, len(words)),
+	}
+	for i, w := range words {
+		if w.AIData == nil || w.AIData.Definition == "" {
+			return nil
+		}
+		defID := "d" + strconv.Itoa(order[i]+1)
+		b.Words[i] = MatchCard{ID: w.ID, Text: w.Text}
+		b.Definitions[order[i]] = MatchCard{ID: defID, Text: w.AIData.Definition}
+		b.Key[w.ID] = defID
+	}
+	return b
+}
+
+// Definition returns the definition card with the given ID
+func (b *MatchBoard) Definition(id string) (MatchCard, bool) {
+	for _, d := range b.Definitions {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return MatchCard{}, false
+}
+
+// Check grades pairs of word ID -> definition ID. A word is right when it
+// is paired with its own definition, or one with the same text; unpaired
+// words are wrong. It returns false if pairs mention cards not on the board.
+func (b *MatchBoard) Check(pairs map[string]string) (map[string]bool, bool) {
+	for wordID, defID := range pairs {
+		if _, ok := b.Key[wordID]; !ok {
+			return nil, false
+		}
+		if _, ok := b.Definition(defID); !ok {
+			return nil, false
+		}
+	}
+
+	results := make(map[string]bool, len(b.Words))
+	for _, w := range b.Words {
+		chosen, ok := b.Definition(pairs[w.ID])
+		expected, _ := b.Definition(b.Key[w.ID])
+		results[w.ID] = ok && chosen.Text == expected.Text
+	}
+	return results, true
+}
+
+// BoardSizes splits n words into as many boards as possible within the
+// size bounds, as evenly as possible. Words beyond the returned sizes do
+// not fit on a board.
+func BoardSizes(n int) []int {
+	boards := (n + MaxMatchPairs - 1) / MaxMatchPairs
+	for boards > 0 && n < boards*MinMatchPairs {
+		boards--
+	}
+	if boards == 0 {
+		return nil
+	}
+
+	used := min(n, boards*MaxMatchPairs)
+	sizes := make([]int, boards)
+	for i := range sizes {
+		sizes[i] = used / boards
+		if i < used%boards {
+			sizes[i]++
+		}
+	}
+	return sizes
+}
@@ -0,0 +1,82 @@
+package exercise
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+func boardWords(n int) []*word.Word {
+	words := make([]*word.Word, n)
+	for i := range words {
+		words[i] = &word.Word{
+			ID:     fmt.Sprintf("w%d", i),
+			Text:   fmt.Sprintf("word%d", i),
+			AIData: &word.WordAIData{Definition: fmt.Sprintf("meaning %d", i)},
+		}
+	}
+	return words
+}
+
+func TestBoardSizes(t *testing.T) {
+	cases := map[int][]int{
+		0:  nil,
+		3:  nil,
+		4:  {4},
+		6:  {6},
+		7:  {6},
+		8:  {4, 4},
+		10: {5, 5},
+		13: {5, 4, 4},
+	}
+
+	for n, want := range cases {
+		got := BoardSizes(n)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("BoardSizes(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestNewMatchBoardRequiresDefinitions(t *testing.T) {
+	if b := NewMatchBoard(boardWords(3)); b != nil {
+		t.Error("expected no board for too few words")
+	}
+
+	words := boardWords(4)
+	words[2].AIData = nil
+	if b := NewMatchBoard(words); b != nil {
+		t.Error("expected no board when a word has no definition")
+	}
+}
+
+func TestMatchBoardCheck(t *testing.T) {
+	b := NewMatchBoard(boardWords(4))
+	if b == nil {
+		t.Fatal("expected a board")
+	}
+
+	pairs := map[string]string{
+		"w0": b.Key["w0"],
+		"w1": b.Key["w1"],
+		"w2": b.Key["w3"],
+	}
+	results, ok := b.Check(pairs)
+	if !ok {
+		t.Fatal("expected valid pairs")
+	}
+	want := map[string]bool{"w0": true, "w1": true, "w2": false, "w3": false}
+	for id, correct := range want {
+		if results[id] != correct {
+			t.Errorf("%s: expected correct=%v, got %v", id, correct, results[id])
+		}
+	}
+
+	if _, ok := b.Check(map[string]string{"w0": "d9"}); ok {
+		t.Error("expected unknown definition to be rejected")
+	}
+	if _, ok := b.Check(map[string]string{"other": b.Key["w0"]}); ok {
+		t.Error("expected unknown word to be rejected")
+	}
+}
@@ -0,0 +1,147 @@
+package grading
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// Match tells how an answer matched the expected one
+type Match string
+
+const (
+	MatchExact     Match = "exact"     // as expected, ignoring spacing
+	MatchFolded    Match = "folded"    // differs only in case, accents or surrounding punctuation
+	MatchInflected Match = "inflected" // another form of the same word ("ran" for "run")
+	MatchTypo      Match = "typo"      // within the typo tolerance
+	MatchWrong     Match = "wrong"
+)
+
+// Scores given to each kind of match. Typos lose typoPenalty per edit.
+const (
+	inflectedScore = 0.8
+	typoPenalty    = 0.15
+	minTypoScore   = 0.5
+	// wrongScoreMax caps the partial credit of a wrong but close answer
+	wrongScoreMax = 0.4
+)
+
+// Result is a graded answer
+type Result struct {
+	Match    Match
+	Correct  bool
+	Score    float64 // 0.0 - 1.0
+	Expected string  // the accepted answer the result was graded against
+}
+
+// Grade grades a typed answer against the accepted answers, the first of
+// which is shown as the expected answer. The best match wins.
+func Grade(answer string, accepted ...string) Result {
+	var best Result
+	for i, expected := range accepted {
+		r := gradeOne(answer, expected)
+		if i == 0 || r.Score > best.Score {
+			best = r
+		}
+	}
+	if len(accepted) > 0 {
+		best.Expected = word.CleanText(accepted[0])
+	}
+	return best
+}
+
+func gradeOne(answer, expected string) Result {
+	a, e := fold(answer), fold(expected)
+	if a == "" || e == "" {
+		return Result{Match: MatchWrong}
+	}
+
+	switch {
+	case word.CleanText(answer) == word.CleanText(expected):
+		return Result{Match: MatchExact, Correct: true, Score: 1}
+	case a == e:
+		return Result{Match: MatchFolded, Correct: true, Score: 1}
+	case word.Lemma(a) == word.Lemma(e):
+		return Result{Match: MatchInflected, Correct: true, Score: inflectedScore}
+	}
+
+	d := levenshtein(a, e)
+	n := len([]rune(e))
+	if d <= typoTolerance(n) && !otherWord(a, e) {
+		return Result{Match: MatchTypo, Correct: true, Score: round(math.Max(minTypoScore, 1-typoPenalty*float64(d)))}
+	}
+
+	// Close misses earn a little credit; anything further off is just wrong
+	if 2*d > n {
+		return Result{Match: MatchWrong}
+	}
+	return Result{Match: MatchWrong, Score: round(wrongScoreMax * (1 - float64(d)/float64(n)))}
+}
+
+// otherWord reports whether a differs from e only by a final silent "e",
+// which spells another word (plan/plane, car/care) more often than it
+// makes a typo
+func otherWord(a, e string) bool {
+	return strings.TrimSuffix(a, "e") == strings.TrimSuffix(e, "e")
+}
+
+// typoTolerance is the number of edits forgiven for an answer of n letters:
+// none for very short words, where one edit often makes another word
+func typoTolerance(n int) int {
+	switch {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fold lowercases, strips accents and surrounding punctuation and collapses
+// whitespace, so "  Café! " and "cafe" compare equal
+func fold(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	folded := word.Normalize(b.String())
+	return strings.TrimFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// levenshtein returns the edit distance between a and b in runes
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,50 @@
+package grading
+
+import "testing"
+
+func TestGradeMatches(t *testing.T) {
+	cases := []struct {
+		answer, expected string
+		match            Match
+		correct          bool
+		score            float64
+	}{
+		{"resilient", "resilient", MatchExact, true, 1},
+		{"Resilient.", "resilient", MatchFolded, true, 1},
+		{"cafe", "café", MatchFolded, true, 1},
+		{"ran", "run", MatchInflected, true, 0.8},
+		{"gave up", "give up", MatchInflected, true, 0.8},
+		{"resilent", "resilient", MatchTypo, true, 0.85},
+		{"resiliant", "resilient", MatchTypo, true, 0.85},
+		{"planned", "plan", MatchInflected, true, 0.8},
+		{"plane", "plan", MatchWrong, false, 0.3},
+		{"care", "car", MatchWrong, false, 0.27},
+		{"note", "not", MatchWrong, false, 0.27},
+		{"bite", "bit", MatchWrong, false, 0.27},
+		{"hope", "hop", MatchWrong, false, 0.27},
+		{"cat", "car", MatchWrong, false, 0.27},
+		{"resistant", "resilient", MatchWrong, false, 0.27},
+		{"fragile", "resilient", MatchWrong, false, 0},
+		{"", "resilient", MatchWrong, false, 0},
+	}
+
+	for _, c := range cases {
+		got := Grade(c.answer, c.expected)
+		if got.Match != c.match || got.Correct != c.correct || got.Score != c.score {
+			t.Errorf("Grade(%q, %q) = %+v, want %s/%v/%v", c.answer, c.expected, got, c.match, c.correct, c.score)
+		}
+	}
+}
+
+func TestGradePicksBestAcceptedAnswer(t *testing.T) {
+	got := Grade("ran", "ran", "run")
+	if got.Match != MatchExact || got.Expected != "ran" {
+		t.Fatalf("unexpected result %+v", got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	if d := levenshtein("kitten", "sitting"); d != 3 {
+		t.Fatalf("expected 3, got %d", d)
+	}
+}
@@ -0,0 +1,75 @@
+package job
+
+import (
+	"context"
+	"time"
+)
+
+// Status is the lifecycle state of a background job
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusFailed  Status = "failed" // gave up after MaxAttempts; only a manual retry runs it again
+	StatusDone    Status = "done"
+)
+
+// Job generates the AI explanation of one word
+type Job struct {
+	ID          string
+	WordID      string
+	UserID      string
+	Status      Status
+	Attempts    int // including the current one while running
+	MaxAttempts int
+	LastError   *string
+	Hint        *string // meaning the user asked for, passed on to the prompt
+	BaseVersion int     // explanation version the job was queued against, 0 for none
+}
+
+// CanRetry returns true if a failed attempt should be scheduled again
+func (j *Job) CanRetry() bool {
+	return j.Attempts < j.MaxAttempts
+}
+
+const (
+	backoffBase = 30 * time.Second
+	backoffMax  = time.Hour
+)
+
+// Backoff returns how long to wait before the next attempt after the given
+// number of failed attempts: 30s, 1m, 2m, 4m, ... capped at one hour
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+
+	d := backoffBase
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= backoffMax {
+			return backoffMax
+		}
+	}
+	return d
+}
+
+// JobRepository defines the interface for the job queue
+type JobRepository interface {
+	// Enqueue schedules the word's job to run now, replacing any earlier job
+	// for the word and resetting its attempts
+	Enqueue(ctx context.Context, job *Job) error
+	// Claim marks the next due job as running and returns it, or returns
+	// nil if there is none. Jobs left running for longer than staleAfter
+	// (e.g. by a crashed worker) are claimed again.
+	Claim(ctx context.Context, staleAfter time.Duration) (*Job, error)
+	// Complete marks a running job as done
+	Complete(ctx context.Context, jobID string) error
+	// Fail records a failed attempt. A positive retryIn schedules the job
+	// again after that delay; otherwise it is marked as failed for good.
+	Fail(ctx context.Context, jobID, lastError string, retryIn time.Duration) error
+	// RetryFailed re-enqueues the user's failed jobs, or only the given
+	// word's job if wordID is set, and returns how many were re-enqueued
+	RetryFailed(ctx context.Context, userID, wordID string) (int, error)
+}
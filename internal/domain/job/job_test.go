@@ -0,0 +1,36 @@
+package job
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDoublesUpToCap(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:  0,
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		5:  8 * time.Minute,
+		8:  time.Hour,
+		50: time.Hour,
+	}
+
+	for attempts, want := range cases {
+		if got := Backoff(attempts); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestCanRetryStopsAtMaxAttempts(t *testing.T) {
+	j := Job{Attempts: 4, MaxAttempts: 5}
+	if !j.CanRetry() {
+		t.Fatal("expected a retry after 4 of 5 attempts")
+	}
+
+	j.Attempts = 5
+	if j.CanRetry() {
+		t.Fatal("expected no retry after 5 of 5 attempts")
+	}
+}
@@ -6,6 +6,7 @@ type WordStats struct {
 	TotalReviews        int
 	RecentFailures      int
 	RecentReviews       int
+	RecentHesitant      int     // right answers that were rated hard or slow
 	Confidence          int     // 1 - 5
 	FrequencyScore      float64 // 0.0 - 1.0
 }
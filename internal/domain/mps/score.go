@@ -6,18 +6,23 @@ func CalculateMPS(s WordStats) (float64, string) {
 	confidenceFactor := float64(5-s.Confidence) / 4.0
 
 	failureFactor := 0.0
+	hesitationFactor := 0.0
 	if s.RecentReviews > 0 {
 		failureFactor = float64(s.RecentFailures) / float64(s.RecentReviews)
+		hesitationFactor = float64(s.RecentHesitant) / float64(s.RecentReviews)
 	}
 
+	// A hesitant right answer counts as half a failure
+	shakyFactor := min(failureFactor+hesitationFactor/2, 1.0)
+
 	score :=
 		(timeFactor * 30) +
 			(accuracyFactor * 30) +
 			(confidenceFactor * 15) +
-			(failureFactor * 15) +
+			(shakyFactor * 15) +
 			(s.FrequencyScore * 10)
 
-	reason := generateReason(timeFactor, accuracyFactor, confidenceFactor, failureFactor)
+	reason := generateReason(timeFactor, accuracyFactor, confidenceFactor, failureFactor, hesitationFactor)
 
 	return clamp(score, 0, 100), reason
 }
@@ -73,3 +73,24 @@ func TestNoReviewsHandledGracefully(t *testing.T) {
 		t.Fatal("expected non-zero score")
 	}
 }
+
+func TestHesitantAnswersRaiseScore(t *testing.T) {
+	s := WordStats{
+		DaysSinceLastReview: 2,
+		AccuracyRate:        0.9,
+		TotalReviews:        10,
+		RecentReviews:       5,
+		Confidence:          4,
+		FrequencyScore:      0.2,
+	}
+	confident, _ := CalculateMPS(s)
+
+	s.RecentHesitant = 4
+	hesitant, reason := CalculateMPS(s)
+	if hesitant <= confident {
+		t.Fatalf("expected hesitant recall to score higher, got %.2f <= %.2f", hesitant, confident)
+	}
+	if reason != "You recently hesitated over this word" {
+		t.Fatalf("unexpected reason: %s", reason)
+	}
+}
@@ -4,5 +4,6 @@ type Context struct {
 	MPS            float64
 	AccuracyRate   float64 // 0.0 - 1.0
 	TotalReviews   int
-	LastReviewType string // "mcq", "match", "typing", "fill_blank"
+	HesitationRate float64 // 0.0 - 1.0, share of recent right answers that were hesitant
+	LastReviewType string  // "mcq", "match", "typing", "fill_blank"
 }
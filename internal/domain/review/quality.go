@@ -0,0 +1,73 @@
+package review
+
+import "time"
+
+// Quality is the learner's own rating of how well they recalled a word
+type Quality string
+
+const (
+	QualityAgain Quality = "again" // did not know it; a right answer was a guess
+	QualityHard  Quality = "hard"  // recalled with effort
+	QualityGood  Quality = "good"
+	QualityEasy  Quality = "easy"
+)
+
+// ParseQuality returns the quality named s
+func ParseQuality(s string) (Quality, bool) {
+	switch q := Quality(s); q {
+	case QualityAgain, QualityHard, QualityGood, QualityEasy:
+		return q, true
+	}
+	return "", false
+}
+
+// MaxLatency is the longest response time taken into account; anything
+// longer most likely means the learner stepped away
+const MaxLatency = 10 * time.Minute
+
+// LatencyLimit is the longest response time accepted at all; it keeps
+// latency_ms well within the column's range
+const LatencyLimit = 24 * time.Hour
+
+// ParseLatency returns the response time of ms milliseconds, if it is
+// neither negative nor over LatencyLimit
+func ParseLatency(ms int) (time.Duration, bool) {
+	if ms < 0 || ms > int(LatencyLimit/time.Millisecond) {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// SlowAnswer returns how long an answer may take before it counts as
+// hesitant. Recognizing a word is quicker than typing it.
+func SlowAnswer(reviewType string) time.Duration {
+	switch reviewType {
+	case "typing", "fill_blank":
+		return 12 * time.Second
+	default:
+		return 6 * time.Second
+	}
+}
+
+// Hesitant reports whether a right answer was shaky: rated hard, or slow
+// without being rated easy
+func Hesitant(reviewType string, quality *Quality, latency *time.Duration) bool {
+	if quality != nil {
+		switch *quality {
+		case QualityHard:
+			return true
+		case QualityEasy:
+			return false
+		}
+	}
+	return latency != nil && *latency > SlowAnswer(reviewType) && *latency <= MaxLatency
+}
+
+// WithQuality folds the learner's rating into the outcome: a right answer
+// rated "again" was a lucky guess and is recorded as a miss
+func (o Outcome) WithQuality(quality *Quality) Outcome {
+	if quality != nil && *quality == QualityAgain {
+		return Outcome{}
+	}
+	return o
+}
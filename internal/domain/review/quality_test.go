@@ -0,0 +1,80 @@
+package review
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHesitant(t *testing.T) {
+	hard, easy, good := QualityHard, QualityEasy, QualityGood
+	fast, slow, away := 2*time.Second, 20*time.Second, time.Hour
+
+	cases := []struct {
+		name       string
+		reviewType string
+		quality    *Quality
+		latency    *time.Duration
+		want       bool
+	}{
+		{"no signal", "mcq", nil, nil, false},
+		{"fast", "mcq", nil, &fast, false},
+		{"slow", "mcq", nil, &slow, true},
+		{"slow but rated easy", "mcq", &easy, &slow, false},
+		{"slow and rated good", "mcq", &good, &slow, true},
+		{"fast but rated hard", "mcq", &hard, &fast, true},
+		{"stepped away", "mcq", nil, &away, false},
+	}
+
+	for _, c := range cases {
+		if got := Hesitant(c.reviewType, c.quality, c.latency); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestAgainTurnsGuessIntoMiss(t *testing.T) {
+	again, good := QualityAgain, QualityGood
+
+	if o := BinaryOutcome(true).WithQuality(&again); o.Correct || o.Score != 0 {
+		t.Fatalf("expected a miss, got %+v", o)
+	}
+	if o := BinaryOutcome(true).WithQuality(&good); !o.Correct || o.Score != 1 {
+		t.Fatalf("expected the outcome to stay, got %+v", o)
+	}
+}
+
+func TestHesitationStepsBackFromRecall(t *testing.T) {
+	ctx := Context{
+		TotalReviews:   6,
+		AccuracyRate:   0.85,
+		HesitationRate: 0.6,
+		LastReviewType: "mcq",
+	}
+
+	if got := SelectType(ctx); got != "typing" {
+		t.Fatalf("expected typing, got %s", got)
+	}
+}
+
+func TestParseLatency(t *testing.T) {
+	limit := int(LatencyLimit / time.Millisecond)
+	cases := []struct {
+		ms   int
+		want time.Duration
+		ok   bool
+	}{
+		{0, 0, true},
+		{3200, 3200 * time.Millisecond, true},
+		{limit, LatencyLimit, true},
+		{-1, 0, false},
+		{limit + 1, 0, false},
+		{1 << 40, 0, false}, // would overflow latency_ms
+	}
+
+	for _, c := range cases {
+		got, ok := ParseLatency(c.ms)
+		if got != c.want || ok != c.ok {
+			t.Errorf("%d: expected %v, %v, got %v, %v", c.ms, c.want, c.ok, got, ok)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package review
 
 func Reason(ctx Context, selected string) string {
+	if ctx.HesitationRate >= hesitantRate {
+		return "You got this right but hesitated — let’s make recall quicker"
+	}
+
 	switch selected {
 	case "mcq":
 		if ctx.TotalReviews == 0 {
@@ -17,6 +21,9 @@ func Reason(ctx Context, selected string) string {
 	case "fill_blank":
 		return "You’ve mastered this word — use it in context"
 
+	case Recall:
+		return "Do you remember what this word means?"
+
 	default:
 		return "Quick review"
 	}
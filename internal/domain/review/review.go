@@ -5,14 +5,50 @@ import (
 	"time"
 )
 
+// UndoWindow is how long after a review it can still be undone
+const UndoWindow = 10 * time.Minute
+
 // Review represents a review entity in the domain
 type Review struct {
 	ID         string
 	WordID     string
 	UserID     string
 	Result     bool
+	Score      float64 // 0.0 - 1.0; 1 or 0 for ungraded reviews
+	Answer     *string // what the user typed or picked, for graded reviews
+	Quality    *Quality
+	Latency    *time.Duration // time taken to answer
+	Hesitant   bool           // a right answer that was rated hard or slow
 	ReviewType string
+	SessionID  *string // the session the review was made in, if any
 	ReviewedAt time.Time
+	// IdempotencyKey is the client's key for the submission, so that
+	// retries are recorded once; unique per user
+	IdempotencyKey *string
+}
+
+// Outcome is the graded result of a review
+type Outcome struct {
+	Correct bool
+	Score   float64 // 0.0 - 1.0, partial credit included
+}
+
+// BinaryOutcome is the outcome of a review graded only right or wrong
+func BinaryOutcome(correct bool) Outcome {
+	if correct {
+		return Outcome{Correct: true, Score: 1}
+	}
+	return Outcome{}
+}
+
+// Flipped is the outcome of the review with the opposite result. A
+// partial score was graded from the answer and is kept; a full or zero
+// score follows the result.
+func (r *Review) Flipped() Outcome {
+	if r.Score > 0 && r.Score < 1 {
+		return Outcome{Correct: !r.Result, Score: r.Score}
+	}
+	return BinaryOutcome(!r.Result)
 }
 
 // ReviewStats represents aggregated statistics for a word's reviews
@@ -21,14 +57,73 @@ type ReviewStats struct {
 	TotalReviews   int
 	CorrectReviews int
 	LastReviewedAt *time.Time
-	AccuracyRate   float64
-	MemoryScore    float64
+	AccuracyRate   float64 // mean score of all reviews
+	HesitationRate float64 // share of the last right answers that were hesitant
+	MemoryScore    float64 // stability of the word's schedule, in days
+}
+
+// AccuracyChange is a word's mean score before and after a session
+type AccuracyChange struct {
+	Before *float64 // nil if the word had no reviews before the session
+	After  float64  // up to the word's last review in the session
+}
+
+// ListFilter selects a user's reviews, newest first
+type ListFilter struct {
+	UserID string
+	WordID string     // only reviews of this word, if set
+	From   *time.Time // reviewed at or after
+	To     *time.Time // reviewed before
+	Limit  int
+	After  *Cursor // resume after this position
+}
+
+// Cursor is the position of the last review of a page
+type Cursor struct {
+	ReviewedAt time.Time
+	ID         string
+}
+
+// Page is one page of listed reviews
+type Page struct {
+	Reviews []*Review
+	Next    *Cursor // nil on the last page
 }
 
 // ReviewRepository defines the interface for review persistence
 type ReviewRepository interface {
+	// Create stores a review; a second review of the same word in the same
+	// session fails with domain.ErrReviewExists, and a second review with
+	// the same idempotency key with domain.ErrReviewKeyUsed. A zero
+	// ReviewedAt is set to now. The word's statistics are left to
+	// UpdateStats or RecomputeStats.
 	Create(ctx context.Context, review *Review) error
-	GetStats(ctx context.Context, wordID string) (*ReviewStats, error)
-	UpdateStats(ctx context.Context, wordID string, result bool) error
-	GetLastReviewType(ctx context.Context, wordID string) (string, error)
+	// GetByKey returns the user's review with the idempotency key, or nil
+	GetByKey(ctx context.Context, userID, key string) (*Review, error)
+	// ListStats returns the statistics of each word, zero for words never
+	// reviewed
+	ListStats(ctx context.Context, wordIDs []string) (map[string]*ReviewStats, error)
+	UpdateStats(ctx context.Context, wordID string, outcome Outcome) error
+	// RecomputeStats rebuilds a word's statistics from its reviews; a word
+	// left without reviews has none
+	RecomputeStats(ctx context.Context, wordID string) error
+	// Latest returns the most recent review of a word, or nil
+	Latest(ctx context.Context, wordID string) (*Review, error)
+	// ListByWord returns every review of a word, oldest first
+	ListByWord(ctx context.Context, wordID string) ([]*Review, error)
+	// UpdateOutcome changes the result and score of a review; a review
+	// that is no longer right is no longer hesitant either
+	UpdateOutcome(ctx context.Context, reviewID string, outcome Outcome) error
+	// Delete removes a review
+	Delete(ctx context.Context, reviewID string) error
+	// LastReviewTypes returns the last review type of each word that has
+	// been reviewed
+	LastReviewTypes(ctx context.Context, wordIDs []string) (map[string]string, error)
+	// List returns a page of a user's reviews, newest first
+	List(ctx context.Context, filter ListFilter) (*Page, error)
+	// ListBySession returns the reviews made in a session, oldest first
+	ListBySession(ctx context.Context, sessionID string) ([]*Review, error)
+	// SessionAccuracy returns the accuracy change of each word reviewed in
+	// a session
+	SessionAccuracy(ctx context.Context, sessionID string) (map[string]AccuracyChange, error)
 }
@@ -0,0 +1,22 @@
+package review
+
+import "testing"
+
+func TestFlipped(t *testing.T) {
+	cases := []struct {
+		name   string
+		review Review
+		want   Outcome
+	}{
+		{"right", Review{Result: true, Score: 1}, Outcome{Correct: false, Score: 0}},
+		{"wrong", Review{Result: false, Score: 0}, Outcome{Correct: true, Score: 1}},
+		{"typo", Review{Result: true, Score: 0.8}, Outcome{Correct: false, Score: 0.8}},
+		{"near miss", Review{Result: false, Score: 0.3}, Outcome{Correct: true, Score: 0.3}},
+	}
+
+	for _, c := range cases {
+		if got := c.review.Flipped(); got != c.want {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.want, got)
+		}
+	}
+}
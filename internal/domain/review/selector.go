@@ -1,5 +1,9 @@
 package review
 
+// hesitantRate is the share of hesitant right answers from which a word
+// counts as shakily known
+const hesitantRate = 0.5
+
 func SelectType(ctx Context) string {
 	var selected string
 
@@ -20,6 +24,11 @@ func SelectType(ctx Context) string {
 		selected = "typing"
 	}
 
+	// Right but hesitant answers are not yet firm recall: step back one format
+	if ctx.HesitationRate >= hesitantRate && selected != "mcq" {
+		selected = fallback(selected)
+	}
+
 	if selected == ctx.LastReviewType {
 		return fallback(selected)
 	}
@@ -61,3 +61,22 @@ func TestNoRepeatFormat(t *testing.T) {
 		t.Fatal("should not repeat same format")
 	}
 }
+
+func TestRestrictStepsToNearestAllowedType(t *testing.T) {
+	cases := []struct {
+		selected string
+		allowed  []string
+		want     string
+	}{
+		{"typing", nil, "typing"},
+		{"typing", []string{"typing", "mcq"}, "typing"},
+		{"fill_blank", []string{"mcq", "match"}, "match"},
+		{"mcq", []string{"typing", "fill_blank"}, "typing"},
+	}
+
+	for _, c := range cases {
+		if got := Restrict(c.selected, c.allowed); got != c.want {
+			t.Errorf("Restrict(%s, %v): expected %s, got %s", c.selected, c.allowed, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package review
+
+import (
+	"slices"
+	"time"
+)
+
+// Types lists the review types from easiest to hardest
+var Types = []string{"mcq", "match", "typing", "fill_blank"}
+
+// Recall is the review type of a word no exercise can be built for yet,
+// e.g. a new word without a definition: the learner is shown the word and
+// reports whether they remember what it means. It is never selected, so it
+// is not among Types.
+const Recall = "recall"
+
+// ValidType reports whether t is a known review type
+func ValidType(t string) bool {
+	return slices.Contains(Types, t)
+}
+
+// Restrict returns t if it is allowed, otherwise the nearest allowed type,
+// preferring easier ones. An empty allowed list allows every type.
+func Restrict(t string, allowed []string) string {
+	if len(allowed) == 0 || slices.Contains(allowed, t) {
+		return t
+	}
+
+	at := slices.Index(Types, t)
+	for i := at - 1; i >= 0; i-- {
+		if slices.Contains(allowed, Types[i]) {
+			return Types[i]
+		}
+	}
+	for i := at + 1; i < len(Types); i++ {
+		if slices.Contains(allowed, Types[i]) {
+			return Types[i]
+		}
+	}
+	return t
+}
+
+// EstimatedTime is roughly how long a review of one word takes, used to
+// fit sessions into a time budget
+func EstimatedTime(reviewType string) time.Duration {
+	switch reviewType {
+	case "typing":
+		return 20 * time.Second
+	case "fill_blank":
+		return 25 * time.Second
+	default:
+		return 10 * time.Second
+	}
+}
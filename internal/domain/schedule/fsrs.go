@@ -0,0 +1,109 @@
+package schedule
+
+import (
+	"math"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/mps"
+)
+
+// fsrsWeights are the default FSRS-4.5 parameters
+var fsrsWeights = [17]float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031,
+	1.6474, 0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// Shape of the FSRS-4.5 forgetting curve
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+)
+
+// FSRS is the Free Spaced Repetition Scheduler (version 4.5). It models
+// each word's stability (days until recall probability falls to 90%) and
+// difficulty, and schedules the next review when the probability of
+// recall is expected to reach the desired retention.
+type FSRS struct {
+	Weights   [17]float64
+	Retention float64 // desired probability of recall when a word is due
+	MaxDays   float64 // longest interval
+}
+
+// NewFSRS returns FSRS with the default parameters
+func NewFSRS() FSRS {
+	return FSRS{Weights: fsrsWeights, Retention: 0.9, MaxDays: 36500}
+}
+
+func (FSRS) Algorithm() Algorithm {
+	return AlgorithmFSRS
+}
+
+func (f FSRS) Next(s State, rating Rating, now time.Time) State {
+	w := f.Weights
+	g := float64(rating)
+
+	if s.Algorithm != AlgorithmFSRS || s.Stability == 0 {
+		s = started(s, AlgorithmFSRS)
+		s.Stability = w[rating-1]
+		s.Difficulty = f.initialDifficulty(g)
+	} else {
+		r := Retrievability(elapsedDays(s, now), s.Stability)
+		if rating == Again {
+			s.Stability = min(
+				w[11]*math.Pow(s.Difficulty, -w[12])*(math.Pow(s.Stability+1, w[13])-1)*math.Exp(w[14]*(1-r)),
+				s.Stability,
+			)
+		} else {
+			bonus := 1.0
+			if rating == Hard {
+				bonus = w[15]
+			} else if rating == Easy {
+				bonus = w[16]
+			}
+			s.Stability *= 1 + math.Exp(w[8])*(11-s.Difficulty)*math.Pow(s.Stability, -w[9])*(math.Exp(w[10]*(1-r))-1)*bonus
+		}
+
+		// Difficulty moves with the rating and reverts slowly to the mean
+		d := s.Difficulty - w[6]*(g-3)
+		s.Difficulty = clampDifficulty(w[7]*f.initialDifficulty(3) + (1-w[7])*d)
+	}
+
+	if rating == Again {
+		s.Repetitions = 0
+		s.Lapses++
+	} else {
+		s.Repetitions++
+	}
+
+	s.LastReviewedAt = &now
+	s.DueAt = dueIn(now, f.interval(s.Stability))
+	return s
+}
+
+func (f FSRS) Priority(c Card, now time.Time) (float64, string) {
+	if c.State.Algorithm != AlgorithmFSRS {
+		return mps.CalculateMPS(c.Stats)
+	}
+	return duePriority(c.State, now)
+}
+
+// Retrievability is the probability of recalling a word of the given
+// stability after the given number of days
+func Retrievability(days, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*days/stability, fsrsDecay)
+}
+
+// interval returns the days after which recall probability falls to the
+// desired retention, rounded to whole days
+func (f FSRS) interval(stability float64) float64 {
+	days := stability / fsrsFactor * (math.Pow(f.Retention, 1/fsrsDecay) - 1)
+	return min(max(math.Round(days), 1), f.MaxDays)
+}
+
+func (f FSRS) initialDifficulty(g float64) float64 {
+	return clampDifficulty(f.Weights[4] - (g-3)*f.Weights[5])
+}
+
+func clampDifficulty(d float64) float64 {
+	return min(max(d, 1), 10)
+}
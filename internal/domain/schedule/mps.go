@@ -0,0 +1,32 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/mps"
+)
+
+// MPS ranks words by Memory Priority Score. It keeps count of repetitions
+// and lapses but sets no due dates: a word is reviewed whenever its score
+// is high enough.
+type MPS struct{}
+
+func (MPS) Algorithm() Algorithm {
+	return AlgorithmMPS
+}
+
+func (MPS) Next(s State, rating Rating, now time.Time) State {
+	s = started(s, AlgorithmMPS)
+	if rating == Again {
+		s.Repetitions = 0
+		s.Lapses++
+	} else {
+		s.Repetitions++
+	}
+	s.LastReviewedAt = &now
+	return s
+}
+
+func (MPS) Priority(c Card, now time.Time) (float64, string) {
+	return mps.CalculateMPS(c.Stats)
+}
@@ -0,0 +1,171 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/mps"
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+)
+
+// Algorithm names a scheduling algorithm
+type Algorithm string
+
+const (
+	AlgorithmMPS  Algorithm = "mps"  // rank by Memory Priority Score, no due dates
+	AlgorithmSM2  Algorithm = "sm2"  // SuperMemo 2
+	AlgorithmFSRS Algorithm = "fsrs" // Free Spaced Repetition Scheduler
+)
+
+// DefaultAlgorithm is used until the user picks another one
+const DefaultAlgorithm = AlgorithmMPS
+
+// Rating is how well a word was recalled in a review
+type Rating int
+
+const (
+	Again Rating = iota + 1
+	Hard
+	Good
+	Easy
+)
+
+// RatingFor rates a review from its outcome and the learner's own quality
+// rating, if any. Without one, a hesitant right answer is rated Hard.
+func RatingFor(correct, hesitant bool, quality *review.Quality) Rating {
+	if !correct {
+		return Again
+	}
+	if quality != nil {
+		switch *quality {
+		case review.QualityAgain:
+			return Again
+		case review.QualityHard:
+			return Hard
+		case review.QualityEasy:
+			return Easy
+		}
+		return Good
+	}
+	if hesitant {
+		return Hard
+	}
+	return Good
+}
+
+// State is the memory state of a word as tracked by a scheduler
+type State struct {
+	Algorithm      Algorithm  // scheduler that produced the state; "" if never scheduled
+	Stability      float64    // days the word stays recallable; the interval for SM-2
+	Difficulty     float64    // 1 - 10 for FSRS; the ease factor for SM-2
+	Repetitions    int        // successful reviews in a row
+	Lapses         int        // times the word was forgotten
+	DueAt          *time.Time // nil if the scheduler does not set due dates
+	LastReviewedAt *time.Time
+}
+
+// Card is what a scheduler knows about a word when ranking it
+type Card struct {
+	State State
+	Stats mps.WordStats // review history summary
+}
+
+// Scheduler decides when words are reviewed
+type Scheduler interface {
+	Algorithm() Algorithm
+	// Next returns the memory state after a review. A state produced by
+	// another algorithm is ignored and the word starts over.
+	Next(s State, rating Rating, now time.Time) State
+	// Priority ranks a word for review now, 0 - 100, and says why. Words
+	// not yet scheduled by this algorithm are ranked by MPS.
+	Priority(c Card, now time.Time) (float64, string)
+}
+
+// Rated is a past review as a scheduler sees it
+type Rated struct {
+	Rating Rating
+	At     time.Time
+}
+
+// Replay returns the state a scheduler reaches over a word's reviews,
+// oldest first; the zero State if there are none
+func Replay(s Scheduler, reviews []Rated) State {
+	var state State
+	for _, r := range reviews {
+		state = s.Next(state, r.Rating, r.At)
+	}
+	return state
+}
+
+// New returns the scheduler for an algorithm
+func New(alg Algorithm) (Scheduler, bool) {
+	switch alg {
+	case AlgorithmMPS:
+		return MPS{}, true
+	case AlgorithmSM2:
+		return SM2{}, true
+	case AlgorithmFSRS:
+		return NewFSRS(), true
+	}
+	return nil, false
+}
+
+// ParseAlgorithm returns the algorithm named s
+func ParseAlgorithm(s string) (Algorithm, bool) {
+	alg := Algorithm(s)
+	_, ok := New(alg)
+	return alg, ok
+}
+
+// Repository persists the memory state of words
+type Repository interface {
+	// Get returns the state of a word, the zero State if it was never scheduled
+	Get(ctx context.Context, wordID string) (State, error)
+	// List returns the states of all scheduled words of a user by word ID
+	List(ctx context.Context, userID string) (map[string]State, error)
+	Save(ctx context.Context, wordID string, s State) error
+	// Delete forgets the state of a word, as if it was never scheduled
+	Delete(ctx context.Context, wordID string) error
+}
+
+// started returns s, or a fresh state if s belongs to another algorithm
+func started(s State, alg Algorithm) State {
+	if s.Algorithm != alg {
+		return State{Algorithm: alg}
+	}
+	return s
+}
+
+// elapsedDays returns the days since the last review, 0 if there was none
+func elapsedDays(s State, now time.Time) float64 {
+	if s.LastReviewedAt == nil {
+		return 0
+	}
+	return max(now.Sub(*s.LastReviewedAt).Hours()/24, 0)
+}
+
+// dueIn returns the time days from now
+func dueIn(now time.Time, days float64) *time.Time {
+	due := now.Add(time.Duration(days * 24 * float64(time.Hour)))
+	return &due
+}
+
+// duePriority ranks a scheduled word by how far through its interval it
+// is: 50 when due, rising to 100 at twice the interval
+func duePriority(s State, now time.Time) (float64, string) {
+	if s.DueAt == nil || s.LastReviewedAt == nil {
+		return 100, "This word is due for review"
+	}
+
+	interval := max(s.DueAt.Sub(*s.LastReviewedAt).Hours()/24, 1.0/24)
+	priority := min(50*elapsedDays(s, now)/interval, 100)
+
+	switch overdue := now.Sub(*s.DueAt); {
+	case overdue >= 24*time.Hour:
+		return priority, "This word is overdue for review"
+	case overdue >= 0:
+		return priority, "This word is due for review"
+	default:
+		return priority, "This word is not due yet"
+	}
+}
@@ -0,0 +1,159 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/mps"
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+)
+
+var start = time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// runReviews runs a scheduler through ratings, each given on the due date of
+// the previous review
+func runReviews(s Scheduler, ratings ...Rating) State {
+	var state State
+	now := start
+	for _, rating := range ratings {
+		state = s.Next(state, rating, now)
+		if state.DueAt != nil {
+			now = *state.DueAt
+		}
+	}
+	return state
+}
+
+func days(s State) float64 {
+	return s.DueAt.Sub(*s.LastReviewedAt).Hours() / 24
+}
+
+func TestRatingFor(t *testing.T) {
+	again, easy := review.QualityAgain, review.QualityEasy
+
+	cases := []struct {
+		correct, hesitant bool
+		quality           *review.Quality
+		want              Rating
+	}{
+		{false, false, nil, Again},
+		{false, false, &easy, Again},
+		{true, false, &again, Again},
+		{true, true, nil, Hard},
+		{true, false, nil, Good},
+		{true, true, &easy, Easy},
+	}
+
+	for _, c := range cases {
+		if got := RatingFor(c.correct, c.hesitant, c.quality); got != c.want {
+			t.Errorf("RatingFor(%v, %v, %v) = %d, want %d", c.correct, c.hesitant, c.quality, got, c.want)
+		}
+	}
+}
+
+func TestSM2Intervals(t *testing.T) {
+	s := runReviews(SM2{}, Good, Good, Good)
+	if s.Repetitions != 3 || days(s) != 15 {
+		t.Fatalf("expected 3rd interval of 15 days, got %+v (%.1f days)", s, days(s))
+	}
+
+	lapsed := SM2{}.Next(s, Again, *s.DueAt)
+	if lapsed.Repetitions != 0 || lapsed.Lapses != 1 || days(lapsed) != 1 {
+		t.Fatalf("expected a lapse to restart, got %+v", lapsed)
+	}
+	if lapsed.Difficulty >= s.Difficulty {
+		t.Fatalf("expected ease to drop, got %.2f -> %.2f", s.Difficulty, lapsed.Difficulty)
+	}
+}
+
+func TestSM2EaseHasFloor(t *testing.T) {
+	s := runReviews(SM2{}, Again, Again, Again, Again, Again, Again, Again)
+	if s.Difficulty != sm2MinEase {
+		t.Fatalf("expected ease %.1f, got %.2f", sm2MinEase, s.Difficulty)
+	}
+}
+
+func TestFSRSIntervalsGrowWithRating(t *testing.T) {
+	f := NewFSRS()
+
+	hard := runReviews(f, Good, Hard)
+	good := runReviews(f, Good, Good)
+	easy := runReviews(f, Good, Easy)
+	if !(days(hard) < days(good) && days(good) < days(easy)) {
+		t.Fatalf("expected hard < good < easy, got %.0f, %.0f, %.0f", days(hard), days(good), days(easy))
+	}
+
+	longer := runReviews(f, Good, Good, Good)
+	if days(longer) <= days(good) {
+		t.Fatalf("expected intervals to grow, got %.0f then %.0f", days(good), days(longer))
+	}
+}
+
+func TestFSRSLapseShrinksStabilityAndRaisesDifficulty(t *testing.T) {
+	f := NewFSRS()
+	s := runReviews(f, Good, Good, Good)
+	lapsed := f.Next(s, Again, *s.DueAt)
+
+	if lapsed.Stability >= s.Stability || lapsed.Difficulty <= s.Difficulty || lapsed.Lapses != 1 {
+		t.Fatalf("unexpected state after lapse: %+v -> %+v", s, lapsed)
+	}
+}
+
+func TestRetrievabilityAtStabilityIsRetention(t *testing.T) {
+	if r := Retrievability(10, 10); r < 0.899 || r > 0.901 {
+		t.Fatalf("expected 0.9 after one stability, got %.3f", r)
+	}
+}
+
+func TestDuePriority(t *testing.T) {
+	s := runReviews(SM2{}, Good, Good) // due 6 days after the last review
+	card := Card{State: s}
+
+	early, reason := SM2{}.Priority(card, s.LastReviewedAt.Add(24*time.Hour))
+	if early >= 30 || reason != "This word is not due yet" {
+		t.Fatalf("expected low priority before due, got %.1f %q", early, reason)
+	}
+
+	due, _ := SM2{}.Priority(card, *s.DueAt)
+	if due != 50 {
+		t.Fatalf("expected 50 when due, got %.1f", due)
+	}
+
+	late, reason := SM2{}.Priority(card, s.DueAt.Add(30*24*time.Hour))
+	if late != 100 || reason != "This word is overdue for review" {
+		t.Fatalf("expected 100 when long overdue, got %.1f %q", late, reason)
+	}
+}
+
+func TestUnscheduledWordsFallBackToMPS(t *testing.T) {
+	card := Card{
+		State: State{Algorithm: AlgorithmSM2},
+		Stats: mps.WordStats{DaysSinceLastReview: 10, AccuracyRate: 0.3, Confidence: 2},
+	}
+	want, _ := mps.CalculateMPS(card.Stats)
+
+	if got, _ := NewFSRS().Priority(card, start); got != want {
+		t.Fatalf("expected MPS priority %.1f, got %.1f", want, got)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	if got := Replay(SM2{}, nil); got.Algorithm != "" {
+		t.Fatalf("expected the zero state without reviews, got %+v", got)
+	}
+
+	want := runReviews(SM2{}, Good, Again, Good)
+	var reviews []Rated
+	var state State
+	now := start
+	for _, rating := range []Rating{Good, Again, Good} {
+		reviews = append(reviews, Rated{Rating: rating, At: now})
+		state = SM2{}.Next(state, rating, now)
+		now = *state.DueAt
+	}
+
+	got := Replay(SM2{}, reviews)
+	if got.Repetitions != want.Repetitions || got.Lapses != want.Lapses || !got.DueAt.Equal(*want.DueAt) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,61 @@
+package schedule
+
+import (
+	"math"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/mps"
+)
+
+// SM-2 parameters
+const (
+	sm2InitialEase = 2.5
+	sm2MinEase     = 1.3
+)
+
+// SM2 is the SuperMemo 2 algorithm: intervals of 1 and 6 days, then the
+// previous interval times an ease factor that goes down with every
+// difficult answer. A lapse restarts the intervals.
+type SM2 struct{}
+
+func (SM2) Algorithm() Algorithm {
+	return AlgorithmSM2
+}
+
+func (SM2) Next(s State, rating Rating, now time.Time) State {
+	s = started(s, AlgorithmSM2)
+	if s.Difficulty == 0 {
+		s.Difficulty = sm2InitialEase
+	}
+
+	// SM-2 grades answers 0 - 5; 3 and above is a pass
+	q := map[Rating]float64{Again: 1, Hard: 3, Good: 4, Easy: 5}[rating]
+
+	if q < 3 {
+		s.Repetitions = 0
+		s.Lapses++
+		s.Stability = 1
+	} else {
+		switch s.Repetitions {
+		case 0:
+			s.Stability = 1
+		case 1:
+			s.Stability = 6
+		default:
+			s.Stability = math.Round(s.Stability * s.Difficulty)
+		}
+		s.Repetitions++
+	}
+
+	s.Difficulty = max(s.Difficulty+0.1-(5-q)*(0.08+(5-q)*0.02), sm2MinEase)
+	s.LastReviewedAt = &now
+	s.DueAt = dueIn(now, s.Stability)
+	return s
+}
+
+func (SM2) Priority(c Card, now time.Time) (float64, string) {
+	if c.State.Algorithm != AlgorithmSM2 {
+		return mps.CalculateMPS(c.Stats)
+	}
+	return duePriority(c.State, now)
+}
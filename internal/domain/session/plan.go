@@ -0,0 +1,156 @@
+package session
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+)
+
+const (
+	// CriticalPriority is the priority from which a word is critical
+	CriticalPriority = 60.0
+	// NormalPriority is the lowest priority a word is picked for a session at
+	NormalPriority = 40.0
+
+	// DefaultSize is the number of words in a session unless the user
+	// asks for another
+	DefaultSize = 10
+	// MaxSize is the largest session
+	MaxSize = 50
+	// MaxMinutes is the largest time budget
+	MaxMinutes = 120
+)
+
+// Plan shapes what goes into a session
+type Plan struct {
+	Size        int      // words in the session, at most
+	Minutes     int      // time budget in minutes; 0 for none
+	NewRatio    *float64 // share of the words that are new; nil to take words as they rank
+	ReviewTypes []string // allowed review types; empty for all
+	Filter      QueueFilter
+}
+
+// DefaultPlan is the plan of a user who never chose one
+func DefaultPlan() Plan {
+	return Plan{Size: DefaultSize}
+}
+
+// Valid reports whether every field of the plan is in range, and its
+// tag IDs are UUIDs
+func (p Plan) Valid() bool {
+	if p.Size < 1 || p.Size > MaxSize || p.Minutes < 0 || p.Minutes > MaxMinutes {
+		return false
+	}
+	if p.NewRatio != nil && (*p.NewRatio < 0 || *p.NewRatio > 1) {
+		return false
+	}
+	for _, t := range p.ReviewTypes {
+		if !review.ValidType(t) {
+			return false
+		}
+	}
+	for _, id := range p.Filter.TagIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Candidate is a queued word considered for a session, with its review
+// type already chosen
+type Candidate struct {
+	Item SessionItem
+	New  bool // never reviewed
+}
+
+// Pick chooses the session's words from candidates ranked highest
+// priority first, and returns them in that order. Words below
+// NormalPriority are left out. Critical words get up to half of the
+// places, rounded up; with NewRatio set, new words get that share of the
+// places. Places one kind of word cannot fill go to the other. With a
+// time budget, the session shrinks until its estimated time fits.
+func (p Plan) Pick(candidates []Candidate) []SessionItem {
+	var eligible []Candidate
+	for _, c := range candidates {
+		if c.Item.PriorityScore >= NormalPriority {
+			eligible = append(eligible, c)
+		}
+	}
+
+	size := p.Size
+	for {
+		picked := p.pick(eligible, size)
+		if size <= 1 || p.Minutes == 0 || estimate(picked) <= time.Duration(p.Minutes)*time.Minute {
+			return picked
+		}
+		size--
+	}
+}
+
+// pick chooses up to size words
+func (p Plan) pick(candidates []Candidate, size int) []SessionItem {
+	var chosen []int // indexes into candidates
+	if p.NewRatio == nil {
+		chosen = byUrgency(candidates, indexes(candidates, nil), size)
+	} else {
+		isNew := true
+		newOnes := indexes(candidates, &isNew)
+		isNew = false
+		seen := indexes(candidates, &isNew)
+
+		newSize := int(math.Round(float64(size) * *p.NewRatio))
+		newSize = max(newSize, size-len(seen))
+		chosen = append(byUrgency(candidates, newOnes, newSize), byUrgency(candidates, seen, size-min(newSize, len(newOnes)))...)
+	}
+
+	slices.Sort(chosen)
+	items := make([]SessionItem, len(chosen))
+	for i, at := range chosen {
+		items[i] = candidates[at].Item
+	}
+	return items
+}
+
+// indexes returns the positions of the candidates that are new, or not,
+// or all of them for a nil isNew
+func indexes(candidates []Candidate, isNew *bool) []int {
+	var at []int
+	for i, c := range candidates {
+		if isNew == nil || c.New == *isNew {
+			at = append(at, i)
+		}
+	}
+	return at
+}
+
+// byUrgency picks up to size of the candidates at the given positions:
+// critical words fill up to half of the places, rounded up, and normal
+// words the rest; either kind fills places the other leaves empty
+func byUrgency(candidates []Candidate, at []int, size int) []int {
+	var critical, normal []int
+	for _, i := range at {
+		if candidates[i].Item.PriorityScore >= CriticalPriority {
+			critical = append(critical, i)
+		} else {
+			normal = append(normal, i)
+		}
+	}
+
+	criticalSize := min(len(critical), max((size+1)/2, size-len(normal)))
+	normalSize := min(len(normal), size-criticalSize)
+	return append(critical[:criticalSize:criticalSize], normal[:normalSize]...)
+}
+
+// estimate is roughly how long reviewing the items takes
+func estimate(items []SessionItem) time.Duration {
+	var total time.Duration
+	for _, item := range items {
+		total += review.EstimatedTime(item.ReviewType)
+	}
+	return total
+}
@@ -0,0 +1,109 @@
+package session
+
+import (
+	"fmt"
+	"testing"
+)
+
+// candidates returns words ranked from the given priorities, highest
+// first; IDs are "w0", "w1", ... in that order
+func candidates(isNew func(i int) bool, priorities ...float64) []Candidate {
+	cs := make([]Candidate, len(priorities))
+	for i, p := range priorities {
+		cs[i] = Candidate{
+			Item: SessionItem{WordID: fmt.Sprintf("w%d", i), ReviewType: "mcq", PriorityScore: p},
+			New:  isNew != nil && isNew(i),
+		}
+	}
+	return cs
+}
+
+func ids(items []SessionItem) string {
+	s := ""
+	for _, item := range items {
+		s += item.WordID + " "
+	}
+	return s
+}
+
+func TestDefaultPlanSplitsCriticalAndNormal(t *testing.T) {
+	// 7 critical, 6 normal and one below the cut
+	cs := candidates(nil, 90, 85, 80, 75, 70, 65, 61, 55, 52, 50, 48, 45, 41, 35)
+
+	got := ids(DefaultPlan().Pick(cs))
+	if want := "w0 w1 w2 w3 w4 w7 w8 w9 w10 w11 "; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestPickFillsPlacesTheOtherKindLeaves(t *testing.T) {
+	cs := candidates(nil, 90, 85, 80, 75, 70, 65, 61, 55)
+
+	got := ids(DefaultPlan().Pick(cs))
+	if want := "w0 w1 w2 w3 w4 w5 w6 w7 "; got != want {
+		t.Fatalf("expected every eligible word, got %q", got)
+	}
+}
+
+func TestPickHonoursNewRatio(t *testing.T) {
+	// Even positions are new words
+	cs := candidates(func(i int) bool { return i%2 == 0 }, 90, 88, 86, 84, 82, 80, 78, 76, 74, 72)
+
+	ratio := 0.25
+	plan := Plan{Size: 4, NewRatio: &ratio}
+	got := plan.Pick(cs)
+
+	newCount := 0
+	for _, item := range got {
+		for _, c := range cs {
+			if c.Item.WordID == item.WordID && c.New {
+				newCount++
+			}
+		}
+	}
+	if len(got) != 4 || newCount != 1 {
+		t.Fatalf("expected 4 words with 1 new, got %q", ids(got))
+	}
+
+	ratio = 1
+	if got := ids(plan.Pick(candidates(nil, 90, 80))); got != "w0 w1 " {
+		t.Fatalf("expected seen words to fill in for missing new ones, got %q", got)
+	}
+}
+
+func TestPickFitsTimeBudget(t *testing.T) {
+	cs := candidates(nil, 90, 85, 80, 75, 70, 65, 61, 55, 52, 50)
+	for i := range cs {
+		cs[i].Item.ReviewType = "fill_blank" // 25s each
+	}
+
+	plan := Plan{Size: 10, Minutes: 1}
+	if got := plan.Pick(cs); len(got) != 2 {
+		t.Fatalf("expected 2 words to fit a minute, got %q", ids(got))
+	}
+}
+
+func TestPlanValid(t *testing.T) {
+	tooMuch := 1.5
+	cases := []struct {
+		name string
+		plan Plan
+		want bool
+	}{
+		{"default", DefaultPlan(), true},
+		{"empty", Plan{}, false},
+		{"too large", Plan{Size: MaxSize + 1}, false},
+		{"negative budget", Plan{Size: 5, Minutes: -1}, false},
+		{"ratio above one", Plan{Size: 5, NewRatio: &tooMuch}, false},
+		{"unknown type", Plan{Size: 5, ReviewTypes: []string{"essay"}}, false},
+		{"known types", Plan{Size: 5, ReviewTypes: []string{"mcq", "typing"}}, true},
+		{"malformed tag", Plan{Size: 5, Filter: QueueFilter{TagIDs: []string{"x"}}}, false},
+		{"tag", Plan{Size: 5, Filter: QueueFilter{TagIDs: []string{"5f0c8a52-3b1e-4c2a-9d7e-1a2b3c4d5e6f"}}}, true},
+	}
+
+	for _, c := range cases {
+		if got := c.plan.Valid(); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
@@ -1,42 +1,142 @@
 package session
 
-import "context"
+import (
+	"context"
+	"time"
 
-// SessionItem represents an item in a review session
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+)
+
+// SessionItem represents an item in a review session. A "match" item is a
+// board over several words and has no WordID of its own.
 type SessionItem struct {
 	WordID        string
 	ReviewType    string
 	PriorityScore float64
 	Reason        string
+	MCQ           *exercise.MCQ        // question for "mcq" items, if one could be built
+	Cloze         *exercise.Cloze      // sentence for "fill_blank" items, if one could be built
+	Match         *exercise.MatchBoard // board for "match" items
+}
+
+// WordIDs returns the words the item reviews
+func (i *SessionItem) WordIDs() []string {
+	if i.Match == nil {
+		return []string{i.WordID}
+	}
+	ids := make([]string, len(i.Match.Words))
+	for j, w := range i.Match.Words {
+		ids[j] = w.ID
+	}
+	return ids
+}
+
+// Status is where a session stands
+type Status string
+
+const (
+	StatusActive    Status = "active"    // items remain to be reviewed
+	StatusCompleted Status = "completed" // every item was reviewed
+	StatusAbandoned Status = "abandoned" // given up before the end
+)
+
+// Valid reports whether s is a known status
+func (s Status) Valid() bool {
+	switch s {
+	case StatusActive, StatusCompleted, StatusAbandoned:
+		return true
+	}
+	return false
 }
 
 // Session represents a review session
 type Session struct {
-	UserID string
-	Items  []SessionItem
-	Index  int
+	ID          string
+	UserID      string
+	TagID       string // the deck the session was built from, if any
+	Items       []SessionItem
+	Index       int
+	CreatedAt   time.Time
+	ExpiresAt   time.Time  // dropped after this; see Expiry
+	AbandonedAt *time.Time // set once the session is abandoned
 }
 
-// Current returns the current item in the session
+// Current returns the current item in the session; an abandoned session
+// has none
 func (s *Session) Current() *SessionItem {
-	if s.Index >= len(s.Items) {
+	if s.AbandonedAt != nil || s.Index >= len(s.Items) {
 		return nil
 	}
 	return &s.Items[s.Index]
 }
 
-// Advance moves to the next item in the session
-func (s *Session) Advance() {
-	if s.Index < len(s.Items) {
-		s.Index++
-	}
-}
-
 // Done returns true if the session is complete
 func (s *Session) Done() bool {
 	return s.Index >= len(s.Items)
 }
 
+// Status returns where the session stands
+func (s *Session) Status() Status {
+	return Summary{Index: s.Index, Total: len(s.Items), AbandonedAt: s.AbandonedAt}.Status()
+}
+
+// Expiry is how long sessions are kept
+type Expiry struct {
+	Idle     time.Duration // an unfinished session without activity
+	Finished time.Duration // a completed or abandoned one, so its summary can still be read
+}
+
+// At returns how long to keep a session that is now at item index
+func (e Expiry) At(s *Session, index int) time.Duration {
+	if index >= len(s.Items) {
+		return e.Finished
+	}
+	return e.Idle
+}
+
+// Summary describes a session without its items
+type Summary struct {
+	ID          string
+	TagID       string
+	Index       int
+	Total       int
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	AbandonedAt *time.Time
+}
+
+// Status returns where the session stands
+func (s Summary) Status() Status {
+	switch {
+	case s.AbandonedAt != nil:
+		return StatusAbandoned
+	case s.Index >= s.Total:
+		return StatusCompleted
+	default:
+		return StatusActive
+	}
+}
+
+// ListFilter selects a user's sessions, newest first
+type ListFilter struct {
+	UserID string
+	Status Status // only sessions with this status, if set
+	Limit  int
+	After  *Cursor // resume after this position
+}
+
+// Cursor is the position of the last session of a page
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Page is one page of listed sessions
+type Page struct {
+	Sessions []Summary
+	Next     *Cursor // nil on the last page
+}
+
 // ReviewQueueItem represents an item in the review queue
 type ReviewQueueItem struct {
 	UserID        string
@@ -45,8 +145,44 @@ type ReviewQueueItem struct {
 	Reason        string
 }
 
+// QueueFilter restricts which queue items a session is built from; empty
+// fields do not restrict
+type QueueFilter struct {
+	TagIDs        []string // only words in any of these tags (decks)
+	CEFRLevels    []string // only words at one of these levels
+	PartsOfSpeech []string // only words with one of these parts of speech
+}
+
 // ReviewQueueRepository defines the interface for review queue persistence
 type ReviewQueueRepository interface {
 	Rebuild(ctx context.Context, userID string, items []ReviewQueueItem) error
-	GetQueueItems(ctx context.Context, userID string) ([]ReviewQueueItem, error)
+	GetQueueItems(ctx context.Context, userID string, filter QueueFilter) ([]ReviewQueueItem, error)
+}
+
+// SessionRepository persists review sessions. Expiry is measured on the
+// store's clock, so replicas agree on it.
+type SessionRepository interface {
+	// Create stores a new session that expires after ttl of inactivity
+	Create(ctx context.Context, s *Session, ttl time.Duration) error
+	// Get returns a session that has not expired, or domain.ErrSessionNotFound
+	Get(ctx context.Context, sessionID string) (*Session, error)
+	// Advance moves a session from item from to the next and extends its
+	// expiry by ttl, returning the new index. It fails with
+	// domain.ErrSessionMoved if the session is no longer at from, or has no
+	// next item; it must be atomic, so of concurrent calls from the same
+	// item only one succeeds. A matching board is one item however many
+	// words it holds.
+	Advance(ctx context.Context, sessionID string, from int, ttl time.Duration) (int, error)
+	// Rewind moves a session from item from back to the one before and
+	// extends its expiry by ttl, returning the new index. Like Advance it
+	// fails with domain.ErrSessionMoved if the session is no longer at
+	// from, or has no item before it.
+	Rewind(ctx context.Context, sessionID string, from int, ttl time.Duration) (int, error)
+	// Abandon marks a session abandoned and keeps it for ttl from now;
+	// abandoning it again is a no-op
+	Abandon(ctx context.Context, sessionID string, ttl time.Duration) error
+	// List returns a page of a user's sessions that have not expired
+	List(ctx context.Context, filter ListFilter) (*Page, error)
+	// DeleteExpired removes expired sessions, returning how many there were
+	DeleteExpired(ctx context.Context) (int, error)
 }
@@ -0,0 +1,88 @@
+package session
+
+import "time"
+
+// QueueCutoff is the priority below which a word is left out of the
+// review queue: it does not need reviewing yet
+const QueueCutoff = 30.0
+
+// WordOutcome is how one word fared in a session
+type WordOutcome struct {
+	WordID         string
+	Reviews        int            // reviews of the word made in the session
+	Correct        bool           // result of the last of them
+	Score          float64        // score of the last of them
+	PriorityBefore float64        // priority when the session was built
+	PriorityAfter  float64        // priority now
+	AccuracyBefore *float64       // mean score before the session; nil for a new word
+	AccuracyAfter  float64        // mean score after the session
+	TimeSpent      *time.Duration // answer time summed over the reviews, if reported
+	wrong          bool           // any review in the session was wrong
+}
+
+// Reviewed reports whether the word was reviewed in the session
+func (o WordOutcome) Reviewed() bool {
+	return o.Reviews > 0
+}
+
+// Mastered reports whether the word was answered right every time and no
+// longer needs reviewing
+func (o WordOutcome) Mastered() bool {
+	return o.Reviewed() && !o.wrong && o.PriorityAfter < QueueCutoff
+}
+
+// Slipped reports whether a word the user had seen before was missed and
+// lost accuracy
+func (o WordOutcome) Slipped() bool {
+	return o.wrong && o.AccuracyBefore != nil && o.AccuracyAfter < *o.AccuracyBefore
+}
+
+// Record adds a review made in the session; reviews are recorded in the
+// order they were made
+func (o *WordOutcome) Record(correct bool, score float64, latency *time.Duration) {
+	o.Reviews++
+	o.Correct = correct
+	o.Score = score
+	if !correct {
+		o.wrong = true
+	}
+	if latency != nil {
+		spent := *latency
+		if o.TimeSpent != nil {
+			spent += *o.TimeSpent
+		}
+		o.TimeSpent = &spent
+	}
+}
+
+// ItemOutcome is how one item of a session fared; a matching board has
+// one outcome per word
+type ItemOutcome struct {
+	Position   int
+	ReviewType string
+	Words      []WordOutcome
+}
+
+// Suggestion is what to do after a session
+type Suggestion struct {
+	DueNow    int        // words that need reviewing now
+	NextDueAt *time.Time // earliest due date ahead, for schedulers that set them
+	Message   string
+}
+
+// Suggest recommends the next session from the words that slipped, the
+// words due now and the next due date, if any
+func Suggest(slipped, dueNow int, nextDueAt *time.Time) Suggestion {
+	s := Suggestion{DueNow: dueNow, NextDueAt: nextDueAt}
+	switch {
+	case slipped > 0:
+		s.Message = "Some words slipped — start another session later today to go over them again"
+	case dueNow > 0:
+		s.Message = "More words are ready — start another session whenever you like"
+	case nextDueAt != nil:
+		s.Message = "You're all caught up — come back when your next words are due"
+	default:
+		s.Message = "You're all caught up — add new words to keep learning"
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestWordOutcomeRecord(t *testing.T) {
+	var o WordOutcome
+	o.Record(false, 0, ptr(3*time.Second))
+	o.Record(true, 1, nil)
+	o.Record(true, 0.8, ptr(2*time.Second))
+
+	if o.Reviews != 3 || !o.Correct || o.Score != 0.8 {
+		t.Fatalf("expected the last review to count, got %+v", o)
+	}
+	if o.TimeSpent == nil || *o.TimeSpent != 5*time.Second {
+		t.Fatalf("expected 5s spent, got %v", o.TimeSpent)
+	}
+
+	var silent WordOutcome
+	silent.Record(true, 1, nil)
+	if silent.TimeSpent != nil {
+		t.Fatalf("expected no time spent without latencies, got %v", *silent.TimeSpent)
+	}
+}
+
+func TestWordOutcomeMastered(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []bool
+		after   float64
+		want    bool
+	}{
+		{"right and no longer due", []bool{true}, 10, true},
+		{"right but still due", []bool{true}, QueueCutoff, false},
+		{"missed once", []bool{false, true}, 10, false},
+		{"not reviewed", nil, 10, false},
+	}
+	for _, tt := range tests {
+		o := WordOutcome{PriorityAfter: tt.after}
+		for _, correct := range tt.results {
+			o.Record(correct, 0, nil)
+		}
+		if got := o.Mastered(); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestWordOutcomeSlipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		correct bool
+		before  *float64
+		after   float64
+		want    bool
+	}{
+		{"missed a known word", false, ptr(0.8), 0.6, true},
+		{"missed a new word", false, nil, 0, false},
+		{"answered right", true, ptr(0.8), 0.9, false},
+	}
+	for _, tt := range tests {
+		o := WordOutcome{AccuracyBefore: tt.before, AccuracyAfter: tt.after}
+		o.Record(tt.correct, 0, nil)
+		if got := o.Slipped(); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	due := time.Now().Add(time.Hour)
+	tests := []struct {
+		name    string
+		slipped int
+		dueNow  int
+		next    *time.Time
+		want    string
+	}{
+		{"slipped words come first", 1, 5, &due, "Some words slipped — start another session later today to go over them again"},
+		{"words due", 0, 5, &due, "More words are ready — start another session whenever you like"},
+		{"nothing due yet", 0, 0, &due, "You're all caught up — come back when your next words are due"},
+		{"nothing scheduled", 0, 0, nil, "You're all caught up — add new words to keep learning"},
+	}
+	for _, tt := range tests {
+		if got := Suggest(tt.slipped, tt.dueNow, tt.next); got.Message != tt.want || got.DueNow != tt.dueNow {
+			t.Errorf("%s: unexpected suggestion %+v", tt.name, got)
+		}
+	}
+}
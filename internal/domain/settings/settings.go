@@ -0,0 +1,33 @@
+package settings
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/schedule"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+)
+
+// Settings are a user's preferences
+type Settings struct {
+	UserID    string
+	Scheduler schedule.Algorithm // how reviews are scheduled
+	Session   session.Plan       // how sessions are planned unless asked otherwise
+	UpdatedAt time.Time
+}
+
+// Default returns the settings of a user who never changed them
+func Default(userID string) *Settings {
+	return &Settings{
+		UserID:    userID,
+		Scheduler: schedule.DefaultAlgorithm,
+		Session:   session.DefaultPlan(),
+	}
+}
+
+// SettingsRepository persists user settings
+type SettingsRepository interface {
+	// Get returns the settings of a user, the defaults if none were saved
+	Get(ctx context.Context, userID string) (*Settings, error)
+	Save(ctx context.Context, s *Settings) error
+}
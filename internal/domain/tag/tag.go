@@ -0,0 +1,30 @@
+package tag
+
+import (
+	"context"
+	"time"
+)
+
+// MaxNameLength limits tag names to something that fits in a chip
+const MaxNameLength = 64
+
+// Tag groups words, e.g. "IELTS" or "Book: Dune". A deck is a tag used
+// to restrict a review session.
+type Tag struct {
+	ID        string
+	UserID    string
+	Name      string
+	WordCount int
+	CreatedAt time.Time
+}
+
+// TagRepository defines the interface for tag persistence
+type TagRepository interface {
+	Create(ctx context.Context, tag *Tag) error
+	GetByID(ctx context.Context, tagID, userID string) (*Tag, error)
+	List(ctx context.Context, userID string) ([]*Tag, error)
+	Rename(ctx context.Context, tagID, userID, name string) error
+	Delete(ctx context.Context, tagID, userID string) error
+	AddWord(ctx context.Context, tagID, wordID, userID string) error
+	RemoveWord(ctx context.Context, tagID, wordID, userID string) error
+}
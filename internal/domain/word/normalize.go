@@ -0,0 +1,288 @@
+package word
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize trims the text, collapses inner whitespace and lowercases it
+func Normalize(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// CleanText trims the text and collapses inner whitespace, keeping the case
+// the user typed
+func CleanText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// LemmaVersion identifies the rules Lemma applies. Lemmas stored under an
+// older version are recomputed, so that words keep matching their new
+// captures when the rules change.
+const LemmaVersion = 2
+
+// Lemma returns the key used to detect that two captures are the same word.
+//
+// It is a small, conservative rule-based lemmatizer: irregular forms are
+// looked up, and regular inflections (-s, -es, -ies, -ed, -ied, -ing) are
+// stripped with the spelling changes they make undone, so "make", "makes",
+// "making" and "made" all share one key. A word that is not inflected is
+// its own key: "plane" never becomes "plan". Where the spelling does not
+// tell the base form, the stem is kept as it is, so an inflection may miss
+// its base form but never matches another word. Multi-word expressions are
+// lemmatized token by token ("gave up" -> "give up").
+func Lemma(text string) string {
+	tokens := strings.Fields(Normalize(text))
+	for i, token := range tokens {
+		tokens[i] = lemmatizeToken(token)
+	}
+	return strings.Join(tokens, " ")
+}
+
+func lemmatizeToken(token string) string {
+	token = strings.TrimFunc(token, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if base, ok := irregularForms[token]; ok {
+		return base
+	}
+	if invariantForms[token] || !isAlpha(token) || len(token) <= 3 {
+		return token
+	}
+
+	return stripSuffix(token)
+}
+
+// stripSuffix removes a single regular inflectional suffix
+func stripSuffix(w string) string {
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+
+	case strings.HasSuffix(w, "ied") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+
+	case strings.HasSuffix(w, "ing"):
+		if stem := w[:len(w)-3]; len(stem) >= 2 && hasVowel(stem) {
+			return restoreBase(stem)
+		}
+
+	case strings.HasSuffix(w, "eed"):
+		// need, speed, exceed: the "ed" is part of the word
+
+	case strings.HasSuffix(w, "ed"):
+		if stem := w[:len(w)-2]; len(stem) >= 2 && hasVowel(stem) {
+			return restoreBase(stem)
+		}
+
+	case strings.HasSuffix(w, "sses"),
+		strings.HasSuffix(w, "shes"),
+		strings.HasSuffix(w, "ches"),
+		strings.HasSuffix(w, "xes"),
+		strings.HasSuffix(w, "zzes"):
+		return w[:len(w)-2]
+
+	case strings.HasSuffix(w, "s") &&
+		!strings.HasSuffix(w, "ss") &&
+		!strings.HasSuffix(w, "us") &&
+		!strings.HasSuffix(w, "is"):
+		return w[:len(w)-1]
+	}
+
+	return w
+}
+
+// restoreBase undoes the spelling changes -ed and -ing make to the base
+// form of a stem: a doubled final consonant is undoubled ("stopp" ->
+// "stop") or a dropped silent "e" is put back ("hop" -> "hope", "decid"
+// -> "decide")
+func restoreBase(stem string) string {
+	n := len(stem)
+	if last := stem[n-1]; n >= 4 && last == stem[n-2] && strings.IndexByte("bdgmnprt", last) >= 0 {
+		return stem[:n-1]
+	}
+	if droppedE(stem) {
+		return stem + "e"
+	}
+	return stem
+}
+
+// droppedE reports whether a stem lost the silent "e" of its base form.
+// No base form ends in a bare "c", "u" or "v", and a single vowel before
+// a final consonant is long only when an "e" followed it: in a word of one
+// syllable ("hop" from "hoping", but "hopp" from "hopping"), or after the
+// endings that spell a long final syllable ("decid", "relat").
+func droppedE(stem string) bool {
+	n := len(stem)
+	switch last := stem[n-1]; {
+	case last == 'c' || last == 'u' || last == 'v':
+		return true
+	case last == 'i':
+		return n == 2 // die, lie, tie
+	case isVowel(rune(last)) || last == 'w' || last == 'x':
+		return false
+	}
+
+	if !isVowel(rune(stem[n-2])) || (n > 2 && isVowel(rune(stem[n-3]))) {
+		return false
+	}
+	if vowelGroups(stem) == 1 {
+		return true
+	}
+	for _, ending := range longEndings {
+		if strings.HasSuffix(stem, ending) {
+			return true
+		}
+	}
+	return false
+}
+
+// longEndings are the final syllables of stems whose base form ends in a
+// silent "e" (decide, relate, realize, include, compare, require), and
+// that base forms without one rarely end in
+var longEndings = []string{
+	"ag", "ap", "ar", "at", "ib", "id", "in", "ir", "is", "iz",
+	"ok", "om", "os", "ud", "ul", "um", "ur", "us", "ut",
+}
+
+// vowelGroups counts the runs of vowels in w, roughly its syllables
+func vowelGroups(w string) int {
+	groups := 0
+	inVowel := false
+	for _, r := range w {
+		v := isVowel(r)
+		if v && !inVowel {
+			groups++
+		}
+		inVowel = v
+	}
+	return groups
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiouy", r)
+}
+
+func hasVowel(s string) bool {
+	return strings.ContainsAny(s, "aeiouy")
+}
+
+// invariantForms look inflected but are not
+var invariantForms = map[string]bool{
+	"news":       true,
+	"always":     true,
+	"perhaps":    true,
+	"series":     true,
+	"species":    true,
+	"lens":       true,
+	"during":     true,
+	"nothing":    true,
+	"everything": true,
+	"something":  true,
+	"anything":   true,
+	"evening":    true,
+	"morning":    true,
+	"ceiling":    true,
+	"wicked":     true,
+	"clothes":    true,
+	"bias":       true,
+	"alias":      true,
+	"atlas":      true,
+	"canvas":     true,
+	"christmas":  true,
+	"whereas":    true,
+	"chaos":      true,
+	"cosmos":     true,
+	"ethos":      true,
+	"physics":    true,
+	"economics":  true,
+	"politics":   true,
+}
+
+// irregularBases lists common irregular inflections by base form
+var irregularBases = map[string][]string{
+	"be":         {"am", "is", "are", "was", "were", "been", "being"},
+	"have":       {"has", "had", "having"},
+	"do":         {"does", "did", "done"},
+	"go":         {"goes", "went", "gone"},
+	"make":       {"made"},
+	"say":        {"said"},
+	"pay":        {"paid"},
+	"take":       {"took", "taken"},
+	"come":       {"came"},
+	"see":        {"saw", "seen", "seeing"},
+	"know":       {"knew", "known"},
+	"get":        {"got", "gotten"},
+	"give":       {"gave", "given"},
+	"find":       {"found"},
+	"think":      {"thought"},
+	"tell":       {"told"},
+	"become":     {"became"},
+	"leave":      {"left"},
+	"feel":       {"felt"},
+	"bring":      {"brought"},
+	"buy":        {"bought"},
+	"catch":      {"caught"},
+	"teach":      {"taught"},
+	"fight":      {"fought"},
+	"seek":       {"sought"},
+	"begin":      {"began", "begun"},
+	"keep":       {"kept"},
+	"hold":       {"held"},
+	"stand":      {"stood"},
+	"write":      {"wrote", "written"},
+	"hear":       {"heard"},
+	"mean":       {"meant"},
+	"meet":       {"met"},
+	"run":        {"ran"},
+	"sit":        {"sat"},
+	"lead":       {"led"},
+	"speak":      {"spoke", "spoken"},
+	"grow":       {"grew", "grown"},
+	"lose":       {"lost"},
+	"send":       {"sent"},
+	"spend":      {"spent"},
+	"build":      {"built"},
+	"fall":       {"fell", "fallen"},
+	"understand": {"understood"},
+	"draw":       {"drew", "drawn"},
+	"break":      {"broke", "broken"},
+	"rise":       {"rose", "risen"},
+	"drive":      {"drove", "driven"},
+	"wear":       {"wore", "worn"},
+	"choose":     {"chose", "chosen"},
+	"throw":      {"threw", "thrown"},
+	"eat":        {"ate", "eaten"},
+	"forget":     {"forgot", "forgotten"},
+	"die":        {"dying"},
+	"lie":        {"lying"},
+	"tie":        {"tying"},
+	"man":        {"men"},
+	"woman":      {"women"},
+	"child":      {"children"},
+	"mouse":      {"mice"},
+	"foot":       {"feet"},
+	"tooth":      {"teeth"},
+	"goose":      {"geese"},
+}
+
+// irregularForms maps each irregular inflection to its base form
+var irregularForms = func() map[string]string {
+	forms := make(map[string]string)
+	for base, inflections := range irregularBases {
+		for _, inflection := range inflections {
+			forms[inflection] = base
+		}
+	}
+	return forms
+}()
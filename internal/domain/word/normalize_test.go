@@ -0,0 +1,71 @@
+package word
+
+import "testing"
+
+func TestNormalizeFoldsCaseAndWhitespace(t *testing.T) {
+	if got := Normalize("  Give   UP \t"); got != "give up" {
+		t.Fatalf("expected %q, got %q", "give up", got)
+	}
+}
+
+func TestLemmaFoldsInflections(t *testing.T) {
+	groups := [][]string{
+		{"run", "runs", "running", "ran"},
+		{"resilient", "Resilient", " resilient. "},
+		{"study", "studies", "studied", "studying"},
+		{"make", "makes", "making", "made"},
+		{"hope", "hoped", "hoping"},
+		{"stop", "stopped", "stopping"},
+		{"box", "boxes"},
+		{"use", "used", "using", "uses"},
+		{"decide", "decided", "deciding"},
+		{"child", "children"},
+		{"give up", "gave up", "giving up"},
+		{"plan", "plans", "planned", "planning"},
+		{"plane", "planes", "planed"},
+		{"care", "cares", "cared", "caring"},
+		{"note", "notes", "noted", "noting"},
+		{"bite", "bites", "biting"},
+		{"argue", "argued", "arguing"},
+		{"relate", "related", "relating"},
+		{"repeat", "repeated", "repeating"},
+		{"visit", "visited", "visiting"},
+		{"open", "opened", "opening"},
+		{"play", "played", "playing"},
+		{"tie", "ties", "tied"},
+	}
+
+	for _, group := range groups {
+		want := Lemma(group[0])
+		for _, form := range group[1:] {
+			if got := Lemma(form); got != want {
+				t.Errorf("Lemma(%q) = %q, want %q (same as %q)", form, got, want, group[0])
+			}
+		}
+	}
+}
+
+func TestLemmaKeepsDistinctWords(t *testing.T) {
+	pairs := [][2]string{
+		{"news", "new"},
+		{"bring", "br"},
+		{"need", "ne"},
+		{"resilient", "resilience"},
+		{"plan", "plane"},
+		{"car", "care"},
+		{"not", "note"},
+		{"hop", "hope"},
+		{"bit", "bite"},
+		{"hopping", "hoping"},
+		{"planned", "planed"},
+		{"bias", "bia"},
+		{"clothes", "cloth"},
+		{"evening", "even"},
+	}
+
+	for _, pair := range pairs {
+		if Lemma(pair[0]) == Lemma(pair[1]) {
+			t.Errorf("expected %q and %q to have different lemmas", pair[0], pair[1])
+		}
+	}
+}
@@ -3,6 +3,8 @@ package word
 import (
 	"context"
 	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/job"
 )
 
 // Word represents a word entity in the domain
@@ -10,15 +12,154 @@ type Word struct {
 	ID         string
 	UserID     string
 	Text       string
-	Context    *string
+	Lemma      string
+	Contexts   []WordContext // oldest first
+	Tags       []WordTag
 	Source     *string
 	Confidence *int
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	ArchivedAt *time.Time
 	AIData     *WordAIData
+	AIStatus   job.Status // empty if no explanation was ever requested
+	AIError    *string    // last generation error, if any
+}
+
+// Archived returns true if the word has been soft-archived
+func (w *Word) Archived() bool {
+	return w.ArchivedAt != nil
+}
+
+// LatestContext returns the most recently captured context, if any
+func (w *Word) LatestContext() *WordContext {
+	if len(w.Contexts) == 0 {
+		return nil
+	}
+	return &w.Contexts[len(w.Contexts)-1]
+}
+
+// ContextSentences returns the sentences of all contexts, oldest first
+func (w *Word) ContextSentences() []string {
+	sentences := make([]string, len(w.Contexts))
+	for i, c := range w.Contexts {
+		sentences[i] = c.Sentence
+	}
+	return sentences
+}
+
+// WordContext is one sentence the user met the word in
+type WordContext struct {
+	ID          string
+	WordID      string
+	Sentence    string
+	SourceURL   *string
+	SourceTitle *string
+	CapturedAt  time.Time
+}
+
+// WordTag is a tag attached to a word
+type WordTag struct {
+	ID   string
+	Name string
+}
+
+// MatchMode controls how ListFilter.Query is matched
+type MatchMode string
+
+const (
+	// MatchSubstring matches the query anywhere in the text, contexts or definition
+	MatchSubstring MatchMode = "substring"
+	// MatchPrefix matches words whose text starts with the query
+	MatchPrefix MatchMode = "prefix"
+	// MatchFullText runs an English full-text search over text, contexts and definition
+	MatchFullText MatchMode = "fulltext"
+)
+
+// Valid returns true if m is a known match mode
+func (m MatchMode) Valid() bool {
+	switch m {
+	case MatchSubstring, MatchPrefix, MatchFullText:
+		return true
+	}
+	return false
 }
 
-// WordAIData represents AI-generated data for a word
+// SortField selects the order of listed words
+type SortField string
+
+const (
+	SortCreatedAt    SortField = "created_at"
+	SortPriority     SortField = "priority"
+	SortAccuracy     SortField = "accuracy"
+	SortAlphabetical SortField = "alphabetical"
+)
+
+// Valid returns true if f is a known sort field
+func (f SortField) Valid() bool {
+	switch f {
+	case SortCreatedAt, SortPriority, SortAccuracy, SortAlphabetical:
+		return true
+	}
+	return false
+}
+
+// DefaultDescending returns the natural direction of a sort: newest,
+// most urgent and weakest words first, and A to Z
+func (f SortField) DefaultDescending() bool {
+	switch f {
+	case SortCreatedAt, SortPriority:
+		return true
+	}
+	return false
+}
+
+// ListFilter narrows down and orders the listed words.
+// Nil and zero fields do not filter.
+type ListFilter struct {
+	UserID string
+	TagID  string // only words with this tag
+
+	Query string
+	Match MatchMode
+
+	CEFRLevels    []string
+	PartOfSpeech  string
+	MinConfidence *int
+	MaxConfidence *int
+	HasAIData     *bool
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	MinAccuracy   *float64 // 0.0 - 1.0, only words reviewed at least once
+	MaxAccuracy   *float64
+	Archived      bool // list archived words instead of active ones
+
+	Sort       SortField
+	Descending bool
+
+	Limit int
+	After *Cursor // continue after this position; nil for the first page
+}
+
+// Cursor is the position of the last word of a page in a given sort.
+// CreatedAt is the sort value of that word when sorting by creation time,
+// and Key the value as rendered by the database otherwise; ID breaks ties
+// between words with the same value.
+type Cursor struct {
+	Sort       SortField
+	Descending bool
+	Key        string
+	CreatedAt  time.Time
+	ID         string
+}
+
+// Page is one page of listed words
+type Page struct {
+	Words []*Word
+	Next  *Cursor // nil on the last page
+}
+
+// WordAIData represents AI-generated data for a word: the current
+// explanation, or one version of its history
 type WordAIData struct {
 	WordID       string
 	Definition   string
@@ -26,14 +167,53 @@ type WordAIData struct {
 	ExampleBad   *string
 	PartOfSpeech *string
 	CEFRLevel    *string
+	Translation  *string
 	GeneratedAt  time.Time
+
+	Version       int // 1 for the first explanation, incremented on every change
+	Source        AISource
+	PromptVersion *string // set for AISourceAI
+	Model         *string // set for AISourceAI
+	Hint          *string // meaning the user asked for when regenerating
+	RestoredFrom  *int    // set for AISourceRestore
 }
 
+// AISource tells where a version of an explanation came from
+type AISource string
+
+const (
+	AISourceAI      AISource = "ai"
+	AISourceManual  AISource = "manual"  // edited by the user
+	AISourceRestore AISource = "restore" // copy of an earlier version
+)
+
 // WordRepository defines the interface for word persistence
 type WordRepository interface {
 	Create(ctx context.Context, word *Word) error
 	GetByID(ctx context.Context, wordID, userID string) (*Word, error)
-	List(ctx context.Context, userID string, limit, offset int) ([]*Word, error)
-	Count(ctx context.Context, userID string) (int, error)
-	StoreAIData(ctx context.Context, wordID string, aiData *WordAIData) error
+	List(ctx context.Context, filter ListFilter) (*Page, error)
+	Count(ctx context.Context, filter ListFilter) (int, error)
+	// StoreAIData records aiData as the word's next explanation version. It
+	// fails with domain.ErrAIDataChanged if the current version is no
+	// longer baseVersion (0 for none).
+	StoreAIData(ctx context.Context, wordID string, baseVersion int, aiData *WordAIData) error
+	DeleteAIData(ctx context.Context, wordID string) error
+	Update(ctx context.Context, word *Word) error
+	Archive(ctx context.Context, wordID, userID string) error
+	Unarchive(ctx context.Context, wordID, userID string) error
+	Delete(ctx context.Context, wordID, userID string) error
+	// LockLemma holds a lock on the user's lemma until the transaction ctx
+	// runs in ends, so that looking a lemma up and then writing it is not
+	// raced by another request. It must be called within a transaction.
+	LockLemma(ctx context.Context, userID, lemma string) error
+	FindByLemma(ctx context.Context, userID, lemma string) (*Word, error)
+	// RefreshLemmas recomputes the lemmas stored under an older
+	// LemmaVersion, returning how many words it updated
+	RefreshLemmas(ctx context.Context) (int, error)
+	AddContext(ctx context.Context, userID string, wordContext *WordContext) error
+	UpdateContext(ctx context.Context, userID string, wordContext *WordContext) error
+	DeleteContext(ctx context.Context, userID, wordID, contextID string) error
+	Merge(ctx context.Context, userID, targetID, sourceID string) error
+	ListAIVersions(ctx context.Context, wordID, userID string) ([]*WordAIData, error)
+	RestoreAIVersion(ctx context.Context, wordID, userID string, version int) (*WordAIData, error)
 }
@@ -10,6 +10,7 @@ type WordStats struct {
 	TotalReviews   int
 	RecentFailures int
 	RecentReviews  int
+	RecentHesitant int
 	Confidence     int
 	FrequencyScore float64
 }
@@ -0,0 +1,267 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
+	"github.com/sonsonha/eng-noting/internal/usecase"
+)
+
+type ExplanationResponse struct {
+	Version       int     `json:"version"`
+	Source        string  `json:"source"`
+	Definition    string  `json:"definition"`
+	ExampleGood   string  `json:"example_good"`
+	ExampleBad    *string `json:"example_bad,omitempty"`
+	PartOfSpeech  *string `json:"part_of_speech,omitempty"`
+	CEFRLevel     *string `json:"cefr_level,omitempty"`
+	Translation   *string `json:"translation,omitempty"`
+	PromptVersion *string `json:"prompt_version,omitempty"`
+	Model         *string `json:"model,omitempty"`
+	Hint          *string `json:"hint,omitempty"`
+	RestoredFrom  *int    `json:"restored_from,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+func toExplanationResponse(d *wordDomain.WordAIData) ExplanationResponse {
+	return ExplanationResponse{
+		Version:       d.Version,
+		Source:        string(d.Source),
+		Definition:    d.Definition,
+		ExampleGood:   d.ExampleGood,
+		ExampleBad:    d.ExampleBad,
+		PartOfSpeech:  d.PartOfSpeech,
+		CEFRLevel:     d.CEFRLevel,
+		Translation:   d.Translation,
+		PromptVersion: d.PromptVersion,
+		Model:         d.Model,
+		Hint:          d.Hint,
+		RestoredFrom:  d.RestoredFrom,
+		CreatedAt:     d.GeneratedAt.Format(time.RFC3339),
+	}
+}
+
+type RegenerateExplanationRequest struct {
+	Hint string `json:"hint"`
+}
+
+// RegenerateExplanation queues a new AI explanation, optionally steered by a hint
+func (h *Handler) RegenerateExplanation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	if wordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word ID")
+		return
+	}
+
+	// The body is optional: an empty request regenerates without a hint
+	var req RegenerateExplanationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	input := usecase.RegenerateExplanationInput{
+		WordID: wordID,
+		UserID: userID,
+		Hint:   req.Hint,
+	}
+
+	if err := h.wordUseCase.RegenerateExplanation(ctx, input); err != nil {
+		switch err {
+		case usecase.ErrBadRequest:
+			writeError(w, http.StatusBadRequest, "hint is too long")
+		case usecase.ErrNotFound:
+			writeError(w, http.StatusNotFound, "word not found")
+		default:
+			h.logger.Error("failed to regenerate explanation", "err", err)
+			writeError(w, http.StatusInternalServerError, "failed to regenerate explanation")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"ai_status": "pending"})
+}
+
+type EditExplanationRequest struct {
+	Definition   *string `json:"definition"`
+	ExampleGood  *string `json:"example_good"`
+	ExampleBad   *string `json:"example_bad"`
+	PartOfSpeech *string `json:"part_of_speech"`
+	CEFRLevel    *string `json:"cefr_level"`
+	Translation  *string `json:"translation"`
+}
+
+// EditExplanation saves a hand-edited explanation as a new version
+func (h *Handler) EditExplanation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	if wordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word ID")
+		return
+	}
+
+	var req EditExplanationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	input := usecase.EditExplanationInput{
+		WordID:       wordID,
+		UserID:       userID,
+		Definition:   req.Definition,
+		ExampleGood:  req.ExampleGood,
+		ExampleBad:   req.ExampleBad,
+		PartOfSpeech: req.PartOfSpeech,
+		CEFRLevel:    req.CEFRLevel,
+		Translation:  req.Translation,
+	}
+
+	output, err := h.wordUseCase.EditExplanation(ctx, input)
+	if err != nil {
+		switch err {
+		case usecase.ErrBadRequest:
+			writeError(w, http.StatusBadRequest, "definition and example_good are required, cefr_level must be A1-C2")
+		case usecase.ErrNotFound:
+			writeError(w, http.StatusNotFound, "word not found")
+		case usecase.ErrConflict:
+			writeError(w, http.StatusConflict, "explanation changed in the meantime, reload and edit again")
+		default:
+			h.logger.Error("failed to edit explanation", "err", err)
+			writeError(w, http.StatusInternalServerError, "failed to edit explanation")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toExplanationResponse(output.AIData))
+}
+
+type ListExplanationVersionsResponse struct {
+	Versions []ExplanationResponse `json:"versions"`
+}
+
+// ListExplanationVersions returns the explanation history of a word, newest first
+func (h *Handler) ListExplanationVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	if wordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word ID")
+		return
+	}
+
+	output, err := h.wordUseCase.ListExplanationVersions(ctx, usecase.WordRefInput{WordID: wordID, UserID: userID})
+	if err != nil {
+		if err == usecase.ErrNotFound {
+			writeError(w, http.StatusNotFound, "word not found")
+			return
+		}
+		h.logger.Error("failed to list explanation versions", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to list explanation versions")
+		return
+	}
+
+	versions := make([]ExplanationResponse, len(output.Versions))
+	for i, v := range output.Versions {
+		versions[i] = toExplanationResponse(v)
+	}
+
+	writeJSON(w, http.StatusOK, ListExplanationVersionsResponse{Versions: versions})
+}
+
+// RestoreExplanationVersion makes an earlier explanation current again
+func (h *Handler) RestoreExplanationVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	if wordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word ID")
+		return
+	}
+
+	version, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil || version < 1 {
+		writeError(w, http.StatusBadRequest, "invalid version")
+		return
+	}
+
+	input := usecase.RestoreExplanationVersionInput{
+		WordID:  wordID,
+		UserID:  userID,
+		Version: version,
+	}
+
+	output, err := h.wordUseCase.RestoreExplanationVersion(ctx, input)
+	if err != nil {
+		switch err {
+		case usecase.ErrBadRequest:
+			writeError(w, http.StatusBadRequest, "invalid version")
+		case usecase.ErrNotFound:
+			writeError(w, http.StatusNotFound, "word or version not found")
+		default:
+			h.logger.Error("failed to restore explanation version", "err", err)
+			writeError(w, http.StatusInternalServerError, "failed to restore explanation version")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toExplanationResponse(output.AIData))
+}
+
+// RetryExplanation queues a new attempt at a word's failed AI explanation
+func (h *Handler) RetryExplanation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	if wordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word ID")
+		return
+	}
+
+	err := h.wordUseCase.RetryExplanation(ctx, usecase.WordRefInput{WordID: wordID, UserID: userID})
+	if err != nil {
+		switch err {
+		case usecase.ErrNotFound:
+			writeError(w, http.StatusNotFound, "word not found")
+		case usecase.ErrConflict:
+			writeError(w, http.StatusConflict, "explanation has not failed")
+		default:
+			h.logger.Error("failed to retry explanation", "err", err)
+			writeError(w, http.StatusInternalServerError, "failed to retry explanation")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"ai_status": "pending"})
+}
+
+type RetryExplanationsResponse struct {
+	Retried int `json:"retried"`
+}
+
+// RetryFailedExplanations queues a new attempt for all of the user's failed explanations
+func (h *Handler) RetryFailedExplanations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	n, err := h.wordUseCase.RetryFailedExplanations(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to retry explanations", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to retry explanations")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, RetryExplanationsResponse{Retried: n})
+}
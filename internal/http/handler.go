@@ -8,10 +8,12 @@ import (
 
 // Handler holds all HTTP handlers and their dependencies
 type Handler struct {
-	wordUseCase    *usecase.WordUseCase
-	reviewUseCase  *usecase.ReviewUseCase
-	sessionUseCase *usecase.SessionUseCase
-	logger         Logger
+	wordUseCase     *usecase.WordUseCase
+	reviewUseCase   *usecase.ReviewUseCase
+	sessionUseCase  *usecase.SessionUseCase
+	tagUseCase      *usecase.TagUseCase
+	settingsUseCase *usecase.SettingsUseCase
+	logger          Logger
 }
 
 // Logger interface for logging
@@ -40,11 +42,15 @@ func NewHandler(
 	wordUseCase *usecase.WordUseCase,
 	reviewUseCase *usecase.ReviewUseCase,
 	sessionUseCase *usecase.SessionUseCase,
+	tagUseCase *usecase.TagUseCase,
+	settingsUseCase *usecase.SettingsUseCase,
 ) *Handler {
 	return &Handler{
-		wordUseCase:    wordUseCase,
-		reviewUseCase:  reviewUseCase,
-		sessionUseCase: sessionUseCase,
-		logger:         &stdLogger{},
+		wordUseCase:     wordUseCase,
+		reviewUseCase:   reviewUseCase,
+		sessionUseCase:  sessionUseCase,
+		tagUseCase:      tagUseCase,
+		settingsUseCase: settingsUseCase,
+		logger:          &stdLogger{},
 	}
 }
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryParams parses typed URL query parameters and remembers the first
+// malformed one, so handlers can check for errors once
+type queryParams struct {
+	values url.Values
+	err    error
+}
+
+func newQueryParams(r *http.Request) *queryParams {
+	return &queryParams{values: r.URL.Query()}
+}
+
+func (q *queryParams) fail(key string) {
+	if q.err == nil {
+		q.err = fmt.Errorf("invalid %s", key)
+	}
+}
+
+func (q *queryParams) string(key string) string {
+	return strings.TrimSpace(q.values.Get(key))
+}
+
+// list splits a comma-separated parameter ("B1,B2")
+func (q *queryParams) list(key string) []string {
+	var items []string
+	for _, item := range strings.Split(q.values.Get(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (q *queryParams) intPtr(key string) *int {
+	raw := q.string(key)
+	if raw == "" {
+		return nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		q.fail(key)
+		return nil
+	}
+	return &v
+}
+
+func (q *queryParams) floatPtr(key string) *float64 {
+	raw := q.string(key)
+	if raw == "" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		q.fail(key)
+		return nil
+	}
+	return &v
+}
+
+func (q *queryParams) boolPtr(key string) *bool {
+	raw := q.string(key)
+	if raw == "" {
+		return nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		q.fail(key)
+		return nil
+	}
+	return &v
+}
+
+// timePtr accepts RFC3339 timestamps or plain dates (2006-01-02).
+// With endOfDay, a plain date means the end of that day, so that
+// "created_to=2024-01-31" includes words created on the 31st.
+func (q *queryParams) timePtr(key string, endOfDay bool) *time.Time {
+	raw := q.string(key)
+	if raw == "" {
+		return nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		q.fail(key)
+		return nil
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t
+}
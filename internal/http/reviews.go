@@ -2,24 +2,75 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
-	"sync"
+	"time"
 
-	"github.com/google/uuid"
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+	"github.com/sonsonha/eng-noting/internal/domain/review"
 	"github.com/sonsonha/eng-noting/internal/domain/session"
 	"github.com/sonsonha/eng-noting/internal/usecase"
 )
 
 type SubmitReviewRequest struct {
-	WordID     string `json:"word_id"`
-	Result     bool   `json:"result"`
-	ReviewType string `json:"review_type"`
+	WordID     string  `json:"word_id"`
+	Result     bool    `json:"result"`
+	Answer     *string `json:"answer"`
+	Quality    *string `json:"quality"`
+	LatencyMs  *int    `json:"latency_ms"`
+	ReviewType string  `json:"review_type"`
+	SessionID  string  `json:"session_id"`
 }
 
 type SubmitReviewResponse struct {
-	Success bool `json:"success"`
+	Success  bool                     `json:"success"`
+	Correct  bool                     `json:"correct"`
+	Score    float64                  `json:"score"`
+	Match    *string                  `json:"match,omitempty"`
+	Expected *string                  `json:"expected,omitempty"`
+	Session  *SessionProgressResponse `json:"session,omitempty"`  // for reviews made in a session
+	Replayed bool                     `json:"replayed,omitempty"` // a retry: nothing was recorded again
 }
 
+// maxIdempotencyKeyLength bounds client-supplied idempotency keys
+const maxIdempotencyKeyLength = 255
+
+func toSubmitReviewResponse(output *usecase.SubmitReviewOutput) SubmitReviewResponse {
+	resp := SubmitReviewResponse{
+		Success:  output.Success,
+		Correct:  output.Correct,
+		Score:    output.Score,
+		Session:  toSessionProgress(output.Session),
+		Replayed: output.Replayed,
+	}
+	if output.Match != "" {
+		resp.Match = &output.Match
+	}
+	if output.Expected != "" {
+		resp.Expected = &output.Expected
+	}
+	return resp
+}
+
+// SessionProgressResponse is where a session stands after an item was answered
+type SessionProgressResponse struct {
+	Position int  `json:"position"`
+	Done     bool `json:"done"`
+}
+
+func toSessionProgress(p *usecase.SessionProgress) *SessionProgressResponse {
+	if p == nil {
+		return nil
+	}
+	return &SessionProgressResponse{Position: p.Position, Done: p.Done}
+}
+
+// SubmitReview answers the current item of a session. session_id is
+// required: the session is what rejects an answer to the wrong item or a
+// second answer to the same one, and what moves on to the next item.
+// Reviews made without a session, offline, go through SyncReviews, whose
+// required idempotency keys keep a resent review from counting twice.
 func (h *Handler) SubmitReview(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := mustUserIDFromContext(ctx)
@@ -34,22 +85,52 @@ func (h *Handler) SubmitReview(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "missing required fields")
 		return
 	}
+	if req.SessionID == "" {
+		writeError(w, http.StatusBadRequest, "session_id is required: start a session with POST /api/reviews/session, or record offline reviews with POST /api/reviews/sync")
+		return
+	}
+
+	if req.Quality != nil {
+		if _, ok := review.ParseQuality(*req.Quality); !ok {
+			writeError(w, http.StatusBadRequest, "quality must be again, hard, good or easy")
+			return
+		}
+	}
+
+	key := r.Header.Get("Idempotency-Key")
+	if len(key) > maxIdempotencyKeyLength {
+		writeError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+		return
+	}
 
 	input := usecase.SubmitReviewInput{
-		UserID:     userID,
-		WordID:     req.WordID,
-		Result:     req.Result,
-		ReviewType: req.ReviewType,
+		UserID:         userID,
+		WordID:         req.WordID,
+		Result:         req.Result,
+		Answer:         req.Answer,
+		Quality:        req.Quality,
+		LatencyMs:      req.LatencyMs,
+		ReviewType:     req.ReviewType,
+		SessionID:      req.SessionID,
+		IdempotencyKey: key,
 	}
 
 	output, err := h.reviewUseCase.SubmitReview(ctx, input)
 	if err != nil {
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, "answer is required for this review type, and latency_ms must be 0-86400000")
+			return
+		}
 		if err == usecase.ErrNotFound {
-			writeError(w, http.StatusNotFound, "word not found")
+			writeError(w, http.StatusNotFound, "word or session not found")
 			return
 		}
 		if err == usecase.ErrForbidden {
-			writeError(w, http.StatusForbidden, "word does not belong to user")
+			writeError(w, http.StatusForbidden, "word or session does not belong to user")
+			return
+		}
+		if err == usecase.ErrConflict {
+			writeError(w, http.StatusConflict, "review does not answer the session's current item, or it was already answered, or Idempotency-Key was used for another review")
 			return
 		}
 		h.logger.Error("failed to submit review", "err", err)
@@ -57,39 +138,300 @@ func (h *Handler) SubmitReview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, SubmitReviewResponse{Success: output.Success})
+	writeJSON(w, http.StatusOK, toSubmitReviewResponse(output))
+}
+
+type UndoReviewRequest struct {
+	WordID string `json:"word_id"`
+	Action string `json:"action"` // "remove" (default) or "flip"
+}
+
+type UndoReviewResponse struct {
+	Success  bool                     `json:"success"`
+	ReviewID string                   `json:"review_id"`
+	Action   string                   `json:"action"`
+	Correct  *bool                    `json:"correct,omitempty"` // the flipped result
+	Session  *SessionProgressResponse `json:"session,omitempty"` // when the session moved back
+}
+
+// UndoReview removes or flips the latest review of a word
+func (h *Handler) UndoReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	var req UndoReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if req.WordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word_id")
+		return
+	}
+
+	input := usecase.UndoReviewInput{UserID: userID, WordID: req.WordID}
+	switch req.Action {
+	case "", "remove":
+		req.Action = "remove"
+	case "flip":
+		input.Flip = true
+	default:
+		writeError(w, http.StatusBadRequest, "action must be remove or flip")
+		return
+	}
+
+	output, err := h.reviewUseCase.UndoReview(ctx, input)
+	if err != nil {
+		if err == usecase.ErrNotFound {
+			writeError(w, http.StatusNotFound, "word not found")
+			return
+		}
+		if err == usecase.ErrConflict {
+			writeError(w, http.StatusConflict, fmt.Sprintf(
+				"no review of the word in the last %d minutes to undo, or it is part of a matching board and can only be flipped",
+				int(review.UndoWindow.Minutes())))
+			return
+		}
+		h.logger.Error("failed to undo review", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to undo review")
+		return
+	}
+
+	resp := UndoReviewResponse{
+		Success:  true,
+		ReviewID: output.ReviewID,
+		Action:   req.Action,
+		Session:  toSessionProgress(output.Session),
+	}
+	if output.Flipped {
+		resp.Correct = &output.Correct
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type SyncReviewsRequest struct {
+	Reviews []OfflineReviewRequest `json:"reviews"`
+}
+
+// OfflineReviewRequest is a review made offline, submitted later
+type OfflineReviewRequest struct {
+	WordID         string    `json:"word_id"`
+	Result         bool      `json:"result"`
+	Answer         *string   `json:"answer"`
+	Quality        *string   `json:"quality"`
+	LatencyMs      *int      `json:"latency_ms"`
+	ReviewType     string    `json:"review_type"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	ReviewedAt     time.Time `json:"reviewed_at"`
+}
+
+type SyncedReviewResponse struct {
+	IdempotencyKey string                `json:"idempotency_key"`
+	Status         string                `json:"status"`
+	Reason         string                `json:"reason,omitempty"`
+	Review         *SubmitReviewResponse `json:"review,omitempty"`
+}
+
+type SyncReviewsResponse struct {
+	Results []SyncedReviewResponse `json:"results"`
+}
+
+// SyncReviews records reviews made offline, at their original times
+func (h *Handler) SyncReviews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	var req SyncReviewsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	input := usecase.SyncReviewsInput{UserID: userID}
+	for _, rev := range req.Reviews {
+		if rev.WordID == "" || rev.ReviewType == "" || len(rev.IdempotencyKey) > maxIdempotencyKeyLength {
+			writeError(w, http.StatusBadRequest, "each review needs word_id, review_type and an idempotency_key of at most 255 characters")
+			return
+		}
+		input.Reviews = append(input.Reviews, usecase.OfflineReviewInput{
+			SubmitReviewInput: usecase.SubmitReviewInput{
+				WordID:         rev.WordID,
+				Result:         rev.Result,
+				Answer:         rev.Answer,
+				Quality:        rev.Quality,
+				LatencyMs:      rev.LatencyMs,
+				ReviewType:     rev.ReviewType,
+				IdempotencyKey: rev.IdempotencyKey,
+			},
+			ReviewedAt: rev.ReviewedAt,
+		})
+	}
+
+	output, err := h.reviewUseCase.SyncReviews(ctx, input)
+	if err != nil {
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf(
+				"send 1-%d reviews, each with idempotency_key and a reviewed_at that is not in the future",
+				usecase.MaxSyncReviews))
+			return
+		}
+		h.logger.Error("failed to sync reviews", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to sync reviews")
+		return
+	}
+
+	results := make([]SyncedReviewResponse, len(output.Results))
+	for i, res := range output.Results {
+		results[i] = SyncedReviewResponse{
+			IdempotencyKey: res.IdempotencyKey,
+			Status:         string(res.Status),
+			Reason:         res.Reason,
+		}
+		if res.Output != nil {
+			resp := toSubmitReviewResponse(res.Output)
+			results[i].Review = &resp
+		}
+	}
+
+	writeJSON(w, http.StatusOK, SyncReviewsResponse{Results: results})
+}
+
+// StartSessionRequest picks what goes into a session; fields left out
+// take the user's defaults
+type StartSessionRequest struct {
+	TagID         string   `json:"tag_id"`
+	Size          *int     `json:"size"`
+	Minutes       *int     `json:"minutes"`
+	NewRatio      *float64 `json:"new_ratio"`
+	ReviewTypes   []string `json:"review_types"`
+	TagIDs        []string `json:"tag_ids"`
+	CEFRLevels    []string `json:"cefr_levels"`
+	PartsOfSpeech []string `json:"parts_of_speech"`
 }
 
 type StartSessionResponse struct {
 	SessionID string        `json:"session_id"`
 	Items     []SessionItem `json:"items"`
 	Total     int           `json:"total"`
+	Position  int           `json:"position"` // index of the item to continue from
+	Resumed   bool          `json:"resumed"`
 }
 
 type SessionItem struct {
-	WordID        string  `json:"word_id"`
-	ReviewType    string  `json:"review_type"`
-	PriorityScore float64 `json:"priority_score"`
-	Reason        string  `json:"reason"`
+	WordID        string         `json:"word_id,omitempty"`
+	ReviewType    string         `json:"review_type"`
+	PriorityScore float64        `json:"priority_score"`
+	Reason        string         `json:"reason"`
+	MCQ           *MCQResponse   `json:"mcq,omitempty"`
+	Cloze         *ClozeResponse `json:"cloze,omitempty"`
+	Match         *MatchResponse `json:"match,omitempty"`
 }
 
-// Session storage for MVP (in-memory)
-// TODO: Replace with Redis for production
-var (
-	sessionStore = make(map[string]*session.Session)
-	sessionMutex sync.RWMutex
-)
+// MCQResponse is a multiple-choice question; the answer is checked on submit
+type MCQResponse struct {
+	Prompt     string   `json:"prompt"`
+	PromptKind string   `json:"prompt_kind"`
+	Options    []string `json:"options"`
+}
+
+// ClozeResponse is a fill-in-the-blank sentence; the answer is checked on submit
+type ClozeResponse struct {
+	Sentence string `json:"sentence"`
+	Hint     string `json:"hint"`
+	Source   string `json:"source"`
+}
+
+// MatchResponse is a matching board; the pairs are checked on submit
+type MatchResponse struct {
+	Words       []MatchCardResponse `json:"words"`
+	Definitions []MatchCardResponse `json:"definitions"`
+}
+
+type MatchCardResponse struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+func toMatchCards(cards []exercise.MatchCard) []MatchCardResponse {
+	resp := make([]MatchCardResponse, len(cards))
+	for i, c := range cards {
+		resp[i] = MatchCardResponse{ID: c.ID, Text: c.Text}
+	}
+	return resp
+}
+
+func toSessionItem(item *session.SessionItem) SessionItem {
+	resp := SessionItem{
+		WordID:        item.WordID,
+		ReviewType:    item.ReviewType,
+		PriorityScore: item.PriorityScore,
+		Reason:        item.Reason,
+	}
+	if item.MCQ != nil {
+		resp.MCQ = &MCQResponse{
+			Prompt:     item.MCQ.Prompt,
+			PromptKind: string(item.MCQ.PromptKind),
+			Options:    item.MCQ.Options,
+		}
+	}
+	if item.Cloze != nil {
+		resp.Cloze = &ClozeResponse{
+			Sentence: item.Cloze.Sentence,
+			Hint:     item.Cloze.Hint,
+			Source:   string(item.Cloze.Source),
+		}
+	}
+	if item.Match != nil {
+		resp.Match = &MatchResponse{
+			Words:       toMatchCards(item.Match.Words),
+			Definitions: toMatchCards(item.Match.Definitions),
+		}
+	}
+	return resp
+}
 
 func (h *Handler) StartSession(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := mustUserIDFromContext(ctx)
 
+	// The body is optional: an empty request starts a session over all words
+	var req StartSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	// force=new starts over instead of resuming an unfinished session
 	input := usecase.StartSessionInput{
 		UserID: userID,
+		TagID:  req.TagID,
+		Options: usecase.SessionOptions{
+			Size:          req.Size,
+			Minutes:       req.Minutes,
+			NewRatio:      req.NewRatio,
+			ReviewTypes:   req.ReviewTypes,
+			TagIDs:        req.TagIDs,
+			CEFRLevels:    req.CEFRLevels,
+			PartsOfSpeech: req.PartsOfSpeech,
+		},
+	}
+	switch r.URL.Query().Get("force") {
+	case "":
+	case "new":
+		input.ForceNew = true
+	default:
+		writeError(w, http.StatusBadRequest, "force must be new")
+		return
 	}
 
 	output, err := h.sessionUseCase.StartSession(ctx, input)
 	if err != nil {
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf(
+				"size must be 1-%d, minutes 0-%d, new_ratio 0-1, review_types among mcq, match, typing and fill_blank, and tag IDs UUIDs",
+				session.MaxSize, session.MaxMinutes))
+			return
+		}
 		h.logger.Error("failed to start session", "err", err)
 		writeError(w, http.StatusInternalServerError, "failed to create session")
 		return
@@ -97,35 +439,94 @@ func (h *Handler) StartSession(w http.ResponseWriter, r *http.Request) {
 
 	// Convert session items to response items
 	items := make([]SessionItem, len(output.Items))
-	for i, item := range output.Items {
-		items[i] = SessionItem{
-			WordID:        item.WordID,
-			ReviewType:    item.ReviewType,
-			PriorityScore: item.PriorityScore,
-			Reason:        item.Reason,
+	for i := range output.Items {
+		items[i] = toSessionItem(&output.Items[i])
+	}
+
+	writeJSON(w, http.StatusOK, StartSessionResponse{
+		SessionID: output.SessionID,
+		Items:     items,
+		Total:     output.Total,
+		Position:  output.Index,
+		Resumed:   output.Resumed,
+	})
+}
+
+type SessionSummaryResponse struct {
+	SessionID   string     `json:"session_id"`
+	Status      string     `json:"status"`
+	TagID       *string    `json:"tag_id"`
+	Position    int        `json:"position"`
+	Total       int        `json:"total"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AbandonedAt *time.Time `json:"abandoned_at,omitempty"`
+}
+
+type ListSessionsResponse struct {
+	Sessions   []SessionSummaryResponse `json:"sessions"`
+	NextCursor *string                  `json:"next_cursor"`
+}
+
+// ListSessions lists the user's sessions that have not expired, newest first
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	q := newQueryParams(r)
+	limit := 0
+	if l := q.intPtr("limit"); l != nil {
+		if *l < 1 || *l > usecase.MaxPageSize {
+			q.fail("limit")
 		}
+		limit = *l
+	}
+	if q.err != nil {
+		writeError(w, http.StatusBadRequest, q.err.Error())
+		return
 	}
 
-	// Store session in memory
-	sessionID := uuid.NewString()
-	session := &session.Session{
+	output, err := h.sessionUseCase.ListSessions(ctx, usecase.ListSessionsInput{
 		UserID: userID,
-		Items:  output.Items,
-		Index:  0,
+		Status: q.string("status"),
+		Limit:  limit,
+		Cursor: q.string("cursor"),
+	})
+	if err != nil {
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, "invalid status or cursor")
+			return
+		}
+		h.logger.Error("failed to list sessions", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to list sessions")
+		return
 	}
 
-	sessionMutex.Lock()
-	sessionStore[sessionID] = session
-	sessionMutex.Unlock()
+	sessions := make([]SessionSummaryResponse, len(output.Sessions))
+	for i, s := range output.Sessions {
+		sessions[i] = SessionSummaryResponse{
+			SessionID:   s.ID,
+			Status:      string(s.Status()),
+			Position:    s.Index,
+			Total:       s.Total,
+			CreatedAt:   s.CreatedAt,
+			ExpiresAt:   s.ExpiresAt,
+			AbandonedAt: s.AbandonedAt,
+		}
+		if s.TagID != "" {
+			sessions[i].TagID = &s.TagID
+		}
+	}
 
-	writeJSON(w, http.StatusOK, StartSessionResponse{
-		SessionID: sessionID,
-		Items:     items,
-		Total:     output.Total,
-	})
+	resp := ListSessionsResponse{Sessions: sessions}
+	if output.NextCursor != "" {
+		resp.NextCursor = &output.NextCursor
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
-func (h *Handler) GetCurrentItem(w http.ResponseWriter, r *http.Request) {
+// AbandonSession gives up on a session so that it is no longer resumed
+func (h *Handler) AbandonSession(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := mustUserIDFromContext(ctx)
 
@@ -135,35 +536,64 @@ func (h *Handler) GetCurrentItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionMutex.RLock()
-	sess, exists := sessionStore[sessionID]
-	sessionMutex.RUnlock()
+	if err := h.sessionUseCase.AbandonSession(ctx, userID, sessionID); err != nil {
+		if err == usecase.ErrConflict {
+			writeError(w, http.StatusConflict, "session is already completed")
+			return
+		}
+		if writeSessionError(w, err) {
+			return
+		}
+		h.logger.Error("failed to abandon session", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to abandon session")
+		return
+	}
 
-	if !exists {
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// writeSessionError writes the response for a session lookup that failed;
+// it returns false for errors that are not the caller's fault
+func writeSessionError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case usecase.ErrNotFound:
 		writeError(w, http.StatusNotFound, "session not found")
+	case usecase.ErrForbidden:
+		writeError(w, http.StatusForbidden, "session does not belong to user")
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *Handler) GetCurrentItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	sessionID := r.URL.Query().Get("session_id")
+	if sessionID == "" {
+		writeError(w, http.StatusBadRequest, "missing session_id")
 		return
 	}
 
-	// Verify session belongs to user
-	if sess.UserID != userID {
-		writeError(w, http.StatusForbidden, "session does not belong to user")
+	output, err := h.sessionUseCase.GetCurrentItem(ctx, userID, sessionID)
+	if err != nil {
+		if writeSessionError(w, err) {
+			return
+		}
+		h.logger.Error("failed to get session item", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to get session item")
 		return
 	}
 
-	item := sess.Current()
-	if item == nil {
+	if output.Item == nil {
 		writeJSON(w, http.StatusOK, map[string]interface{}{
 			"done": true,
 		})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, SessionItem{
-		WordID:        item.WordID,
-		ReviewType:    item.ReviewType,
-		PriorityScore: item.PriorityScore,
-		Reason:        item.Reason,
-	})
+	writeJSON(w, http.StatusOK, toSessionItem(output.Item))
 }
 
 func (h *Handler) AdvanceSession(w http.ResponseWriter, r *http.Request) {
@@ -176,25 +606,309 @@ func (h *Handler) AdvanceSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionMutex.Lock()
-	sess, exists := sessionStore[sessionID]
-	sessionMutex.Unlock()
+	output, err := h.sessionUseCase.AdvanceSession(ctx, userID, sessionID)
+	if err != nil {
+		if err == usecase.ErrConflict {
+			writeError(w, http.StatusConflict, "session was abandoned, or its current item was not answered")
+			return
+		}
+		if writeSessionError(w, err) {
+			return
+		}
+		h.logger.Error("failed to advance session", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to advance session")
+		return
+	}
 
-	if !exists {
-		writeError(w, http.StatusNotFound, "session not found")
+	writeJSON(w, http.StatusOK, map[string]bool{
+		"success": true,
+		"done":    output.Done,
+	})
+}
+
+type SubmitMatchRequest struct {
+	Pairs map[string]string `json:"pairs"` // word ID -> definition ID
+}
+
+type MatchPairResponse struct {
+	WordID   string `json:"word_id"`
+	Text     string `json:"text"`
+	Correct  bool   `json:"correct"`
+	Expected string `json:"expected"`
+}
+
+type SubmitMatchResponse struct {
+	Success bool                     `json:"success"`
+	Results []MatchPairResponse      `json:"results"`
+	Correct int                      `json:"correct"`
+	Total   int                      `json:"total"`
+	Session *SessionProgressResponse `json:"session"`
+}
+
+// SubmitMatch grades the matching board that is the current item of a session
+func (h *Handler) SubmitMatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	sessionID := r.URL.Query().Get("session_id")
+	if sessionID == "" {
+		writeError(w, http.StatusBadRequest, "missing session_id")
 		return
 	}
 
-	// Verify session belongs to user
-	if sess.UserID != userID {
-		writeError(w, http.StatusForbidden, "session does not belong to user")
+	var req SubmitMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
 		return
 	}
 
-	sess.Advance()
+	output, err := h.reviewUseCase.SubmitMatch(ctx, usecase.SubmitMatchInput{
+		UserID:    userID,
+		SessionID: sessionID,
+		Pairs:     req.Pairs,
+	})
+	if err != nil {
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, "pairs refer to cards not on the board")
+			return
+		}
+		if err == usecase.ErrConflict {
+			writeError(w, http.StatusConflict, "current item is not a matching board, or it was already answered")
+			return
+		}
+		if writeSessionError(w, err) {
+			return
+		}
+		h.logger.Error("failed to submit match", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to process review")
+		return
+	}
 
-	writeJSON(w, http.StatusOK, map[string]bool{
-		"success": true,
-		"done":    sess.Done(),
+	results := make([]MatchPairResponse, len(output.Results))
+	for i, res := range output.Results {
+		results[i] = MatchPairResponse{
+			WordID:   res.WordID,
+			Text:     res.Text,
+			Correct:  res.Correct,
+			Expected: res.Expected,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, SubmitMatchResponse{
+		Success: output.Success,
+		Results: results,
+		Correct: output.Correct,
+		Total:   len(results),
+		Session: toSessionProgress(output.Session),
+	})
+}
+
+type WordOutcomeResponse struct {
+	WordID         string   `json:"word_id"`
+	Reviewed       bool     `json:"reviewed"`
+	Correct        bool     `json:"correct"`
+	Score          float64  `json:"score"`
+	MPSBefore      float64  `json:"mps_before"`
+	MPSAfter       float64  `json:"mps_after"`
+	AccuracyBefore *float64 `json:"accuracy_before"`
+	AccuracyAfter  *float64 `json:"accuracy_after"`
+	TimeSpentMs    *int64   `json:"time_spent_ms"`
+	Mastered       bool     `json:"mastered"`
+	Slipped        bool     `json:"slipped"`
+}
+
+type ItemOutcomeResponse struct {
+	Position   int                   `json:"position"`
+	ReviewType string                `json:"review_type"`
+	Words      []WordOutcomeResponse `json:"words"`
+}
+
+type SuggestionResponse struct {
+	DueNow    int        `json:"due_now"`
+	NextDueAt *time.Time `json:"next_due_at"`
+	Message   string     `json:"message"`
+}
+
+type SessionResultsResponse struct {
+	SessionID   string                `json:"session_id"`
+	Status      string                `json:"status"`
+	Items       []ItemOutcomeResponse `json:"items"`
+	Reviewed    int                   `json:"reviewed"`
+	Correct     int                   `json:"correct"`
+	TimeSpentMs *int64                `json:"time_spent_ms"`
+	Mastered    []string              `json:"mastered"`
+	Slipped     []string              `json:"slipped"`
+	Next        SuggestionResponse    `json:"next"`
+}
+
+// milliseconds converts an optional duration for a response
+func milliseconds(d *time.Duration) *int64 {
+	if d == nil {
+		return nil
+	}
+	ms := d.Milliseconds()
+	return &ms
+}
+
+// GetSessionSummary sums up how each item of a session went
+func (h *Handler) GetSessionSummary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	sessionID := r.URL.Query().Get("session_id")
+	if sessionID == "" {
+		writeError(w, http.StatusBadRequest, "missing session_id")
+		return
+	}
+
+	output, err := h.sessionUseCase.GetSessionSummary(ctx, userID, sessionID)
+	if err != nil {
+		if writeSessionError(w, err) {
+			return
+		}
+		h.logger.Error("failed to summarize session", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to summarize session")
+		return
+	}
+
+	items := make([]ItemOutcomeResponse, len(output.Items))
+	for i, item := range output.Items {
+		words := make([]WordOutcomeResponse, len(item.Words))
+		for j, o := range item.Words {
+			words[j] = WordOutcomeResponse{
+				WordID:         o.WordID,
+				Reviewed:       o.Reviewed(),
+				Correct:        o.Correct,
+				Score:          o.Score,
+				MPSBefore:      o.PriorityBefore,
+				MPSAfter:       o.PriorityAfter,
+				AccuracyBefore: o.AccuracyBefore,
+				TimeSpentMs:    milliseconds(o.TimeSpent),
+				Mastered:       o.Mastered(),
+				Slipped:        o.Slipped(),
+			}
+			if o.Reviewed() {
+				words[j].AccuracyAfter = &o.AccuracyAfter
+			}
+		}
+		items[i] = ItemOutcomeResponse{Position: item.Position, ReviewType: item.ReviewType, Words: words}
+	}
+
+	writeJSON(w, http.StatusOK, SessionResultsResponse{
+		SessionID:   output.SessionID,
+		Status:      string(output.Status),
+		Items:       items,
+		Reviewed:    output.Reviewed,
+		Correct:     output.Correct,
+		TimeSpentMs: milliseconds(output.TimeSpent),
+		Mastered:    append([]string{}, output.Mastered...),
+		Slipped:     append([]string{}, output.Slipped...),
+		Next: SuggestionResponse{
+			DueNow:    output.Next.DueNow,
+			NextDueAt: output.Next.NextDueAt,
+			Message:   output.Next.Message,
+		},
 	})
 }
+
+type ReviewHistoryResponse struct {
+	ID         string    `json:"id"`
+	WordID     string    `json:"word_id"`
+	ReviewType string    `json:"review_type"`
+	Correct    bool      `json:"correct"`
+	Score      float64   `json:"score"`
+	Quality    *string   `json:"quality"`
+	Hesitant   bool      `json:"hesitant"`
+	Answer     *string   `json:"answer"`
+	LatencyMs  *int64    `json:"latency_ms"`
+	SessionID  *string   `json:"session_id"`
+	ReviewedAt time.Time `json:"reviewed_at"`
+}
+
+type ListReviewsResponse struct {
+	Reviews    []ReviewHistoryResponse `json:"reviews"`
+	NextCursor *string                 `json:"next_cursor"`
+}
+
+// ListWordReviews lists the reviews of a word, newest first
+func (h *Handler) ListWordReviews(w http.ResponseWriter, r *http.Request) {
+	wordID := r.PathValue("id")
+	if wordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word ID")
+		return
+	}
+	h.listReviews(w, r, wordID)
+}
+
+// ListReviewHistory lists the user's reviews of all words, newest first
+func (h *Handler) ListReviewHistory(w http.ResponseWriter, r *http.Request) {
+	h.listReviews(w, r, "")
+}
+
+func (h *Handler) listReviews(w http.ResponseWriter, r *http.Request, wordID string) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	q := newQueryParams(r)
+	limit := 0
+	if l := q.intPtr("limit"); l != nil {
+		if *l < 1 || *l > usecase.MaxPageSize {
+			q.fail("limit")
+		}
+		limit = *l
+	}
+	from := q.timePtr("from", false)
+	to := q.timePtr("to", true)
+	if q.err != nil {
+		writeError(w, http.StatusBadRequest, q.err.Error())
+		return
+	}
+
+	output, err := h.reviewUseCase.ListReviews(ctx, usecase.ListReviewsInput{
+		UserID: userID,
+		WordID: wordID,
+		From:   from,
+		To:     to,
+		Limit:  limit,
+		Cursor: q.string("cursor"),
+	})
+	if err != nil {
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, "invalid date range or cursor")
+			return
+		}
+		if err == usecase.ErrNotFound {
+			writeError(w, http.StatusNotFound, "word not found")
+			return
+		}
+		h.logger.Error("failed to list reviews", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to list reviews")
+		return
+	}
+
+	reviews := make([]ReviewHistoryResponse, len(output.Reviews))
+	for i, rev := range output.Reviews {
+		reviews[i] = ReviewHistoryResponse{
+			ID:         rev.ID,
+			WordID:     rev.WordID,
+			ReviewType: rev.ReviewType,
+			Correct:    rev.Result,
+			Score:      rev.Score,
+			Hesitant:   rev.Hesitant,
+			Answer:     rev.Answer,
+			LatencyMs:  milliseconds(rev.Latency),
+			SessionID:  rev.SessionID,
+			ReviewedAt: rev.ReviewedAt,
+		}
+		if rev.Quality != nil {
+			quality := string(*rev.Quality)
+			reviews[i].Quality = &quality
+		}
+	}
+
+	resp := ListReviewsResponse{Reviews: reviews}
+	if output.NextCursor != "" {
+		resp.NextCursor = &output.NextCursor
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+	"github.com/sonsonha/eng-noting/internal/domain/settings"
+	"github.com/sonsonha/eng-noting/internal/usecase"
+)
+
+type UpdateSettingsRequest struct {
+	Scheduler *string             `json:"scheduler"`
+	Session   *SessionPlanRequest `json:"session"`
+}
+
+// SessionPlanRequest is a default session plan; omitted fields take the
+// built-in defaults
+type SessionPlanRequest struct {
+	Size          *int     `json:"size"`
+	Minutes       int      `json:"minutes"`
+	NewRatio      *float64 `json:"new_ratio"`
+	ReviewTypes   []string `json:"review_types"`
+	TagIDs        []string `json:"tag_ids"`
+	CEFRLevels    []string `json:"cefr_levels"`
+	PartsOfSpeech []string `json:"parts_of_speech"`
+}
+
+func (req *SessionPlanRequest) plan() *session.Plan {
+	plan := session.DefaultPlan()
+	if req.Size != nil {
+		plan.Size = *req.Size
+	}
+	plan.Minutes = req.Minutes
+	plan.NewRatio = req.NewRatio
+	plan.ReviewTypes = req.ReviewTypes
+	plan.Filter = session.QueueFilter{
+		TagIDs:        req.TagIDs,
+		CEFRLevels:    req.CEFRLevels,
+		PartsOfSpeech: req.PartsOfSpeech,
+	}
+	return &plan
+}
+
+type SettingsResponse struct {
+	Scheduler string              `json:"scheduler"`
+	Session   SessionPlanResponse `json:"session"`
+	UpdatedAt *string             `json:"updated_at,omitempty"` // nil until first changed
+}
+
+type SessionPlanResponse struct {
+	Size          int      `json:"size"`
+	Minutes       int      `json:"minutes"` // 0 for no time budget
+	NewRatio      *float64 `json:"new_ratio"`
+	ReviewTypes   []string `json:"review_types"`
+	TagIDs        []string `json:"tag_ids"`
+	CEFRLevels    []string `json:"cefr_levels"`
+	PartsOfSpeech []string `json:"parts_of_speech"`
+}
+
+func toSessionPlanResponse(p session.Plan) SessionPlanResponse {
+	return SessionPlanResponse{
+		Size:          p.Size,
+		Minutes:       p.Minutes,
+		NewRatio:      p.NewRatio,
+		ReviewTypes:   append([]string{}, p.ReviewTypes...),
+		TagIDs:        append([]string{}, p.Filter.TagIDs...),
+		CEFRLevels:    append([]string{}, p.Filter.CEFRLevels...),
+		PartsOfSpeech: append([]string{}, p.Filter.PartsOfSpeech...),
+	}
+}
+
+func toSettingsResponse(s *settings.Settings) SettingsResponse {
+	resp := SettingsResponse{
+		Scheduler: string(s.Scheduler),
+		Session:   toSessionPlanResponse(s.Session),
+	}
+	if !s.UpdatedAt.IsZero() {
+		updatedAt := s.UpdatedAt.Format(time.RFC3339)
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
+
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	output, err := h.settingsUseCase.GetSettings(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to get settings", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to get settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toSettingsResponse(output.Settings))
+}
+
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	var req UpdateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	input := usecase.UpdateSettingsInput{
+		UserID:    userID,
+		Scheduler: req.Scheduler,
+	}
+	if req.Session != nil {
+		input.Session = req.Session.plan()
+	}
+
+	output, err := h.settingsUseCase.UpdateSettings(ctx, input)
+	if err != nil {
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, "scheduler must be mps, sm2 or fsrs, the session plan in range and its tag_ids UUIDs")
+			return
+		}
+		h.logger.Error("failed to update settings", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to update settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toSettingsResponse(output.Settings))
+}
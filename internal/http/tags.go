@@ -0,0 +1,169 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/tag"
+	"github.com/sonsonha/eng-noting/internal/usecase"
+)
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+type TagResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	WordCount int    `json:"word_count"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ListTagsResponse struct {
+	Tags []TagResponse `json:"tags"`
+}
+
+func toTagResponse(t *tag.Tag) TagResponse {
+	return TagResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		WordCount: t.WordCount,
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (h *Handler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	output, err := h.tagUseCase.CreateTag(ctx, usecase.CreateTagInput{
+		UserID: userID,
+		Name:   req.Name,
+	})
+	if err != nil {
+		h.writeTagError(w, "create", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toTagResponse(output.Tag))
+}
+
+func (h *Handler) ListTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	output, err := h.tagUseCase.ListTags(ctx, userID)
+	if err != nil {
+		h.writeTagError(w, "list", err)
+		return
+	}
+
+	tags := make([]TagResponse, len(output.Tags))
+	for i, t := range output.Tags {
+		tags[i] = toTagResponse(t)
+	}
+
+	writeJSON(w, http.StatusOK, ListTagsResponse{Tags: tags})
+}
+
+func (h *Handler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	tagID := r.PathValue("id")
+	if tagID == "" {
+		writeError(w, http.StatusBadRequest, "missing tag ID")
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	output, err := h.tagUseCase.RenameTag(ctx, usecase.RenameTagInput{
+		UserID: userID,
+		TagID:  tagID,
+		Name:   req.Name,
+	})
+	if err != nil {
+		h.writeTagError(w, "rename", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTagResponse(output.Tag))
+}
+
+func (h *Handler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	tagID := r.PathValue("id")
+	if tagID == "" {
+		writeError(w, http.StatusBadRequest, "missing tag ID")
+		return
+	}
+
+	if err := h.tagUseCase.DeleteTag(ctx, usecase.TagRefInput{UserID: userID, TagID: tagID}); err != nil {
+		h.writeTagError(w, "delete", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (h *Handler) TagWord(w http.ResponseWriter, r *http.Request) {
+	h.wordTagAction(w, r, "tag", h.tagUseCase.TagWord)
+}
+
+func (h *Handler) UntagWord(w http.ResponseWriter, r *http.Request) {
+	h.wordTagAction(w, r, "untag", h.tagUseCase.UntagWord)
+}
+
+func (h *Handler) wordTagAction(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	fn func(context.Context, usecase.WordTagInput) error,
+) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	tagID := r.PathValue("tagID")
+	if wordID == "" || tagID == "" {
+		writeError(w, http.StatusBadRequest, "missing word or tag ID")
+		return
+	}
+
+	input := usecase.WordTagInput{UserID: userID, WordID: wordID, TagID: tagID}
+	if err := fn(ctx, input); err != nil {
+		h.writeTagError(w, action+" word", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (h *Handler) writeTagError(w http.ResponseWriter, action string, err error) {
+	switch err {
+	case usecase.ErrNotFound:
+		writeError(w, http.StatusNotFound, "tag or word not found")
+	case usecase.ErrBadRequest:
+		writeError(w, http.StatusBadRequest, "tag name must be 1-64 characters")
+	case usecase.ErrConflict:
+		writeError(w, http.StatusConflict, "a tag with this name already exists")
+	default:
+		h.logger.Error("failed to "+action, "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to "+action)
+	}
+}
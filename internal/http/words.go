@@ -1,21 +1,25 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-	"strconv"
 	"time"
 
+	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
 	"github.com/sonsonha/eng-noting/internal/usecase"
 )
 
 type CreateWordRequest struct {
-	Text    string `json:"text"`
-	Context string `json:"context"`
+	Text        string  `json:"text"`
+	Context     string  `json:"context"`
+	SourceURL   *string `json:"source_url"`
+	SourceTitle *string `json:"source_title"`
 }
 
 type CreateWordResponse struct {
-	WordID string `json:"word_id"`
+	WordID   string `json:"word_id"`
+	Existing bool   `json:"existing"`
 }
 
 func (h *Handler) CreateWord(w http.ResponseWriter, r *http.Request) {
@@ -29,34 +33,124 @@ func (h *Handler) CreateWord(w http.ResponseWriter, r *http.Request) {
 	}
 
 	input := usecase.CreateWordInput{
-		UserID:  userID,
-		Text:    req.Text,
-		Context: req.Context,
+		UserID:      userID,
+		Text:        req.Text,
+		Context:     req.Context,
+		SourceURL:   req.SourceURL,
+		SourceTitle: req.SourceTitle,
 	}
 
 	output, err := h.wordUseCase.CreateWord(ctx, input)
 	if err != nil {
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, "text is required")
+			return
+		}
 		h.logger.Error("failed to create word", "err", err)
 		writeError(w, http.StatusInternalServerError, "failed to save word")
 		return
 	}
 
-	resp := CreateWordResponse{WordID: output.WordID}
-	writeJSON(w, http.StatusCreated, resp)
+	resp := CreateWordResponse{WordID: output.WordID, Existing: output.Existing}
+	status := http.StatusCreated
+	if output.Existing {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, resp)
+}
+
+type WordContextResponse struct {
+	ID          string  `json:"id"`
+	Sentence    string  `json:"sentence"`
+	SourceURL   *string `json:"source_url,omitempty"`
+	SourceTitle *string `json:"source_title,omitempty"`
+	CapturedAt  string  `json:"captured_at"`
+}
+
+func toWordContextResponse(c *wordDomain.WordContext) WordContextResponse {
+	return WordContextResponse{
+		ID:          c.ID,
+		Sentence:    c.Sentence,
+		SourceURL:   c.SourceURL,
+		SourceTitle: c.SourceTitle,
+		CapturedAt:  c.CapturedAt.Format(time.RFC3339),
+	}
+}
+
+type WordTagResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 type WordResponse struct {
-	ID           string  `json:"id"`
-	Text         string  `json:"text"`
-	Context      *string `json:"context,omitempty"`
-	Source       *string `json:"source,omitempty"`
-	Confidence   *int    `json:"confidence,omitempty"`
-	CreatedAt    string  `json:"created_at"`
-	Definition   *string `json:"definition,omitempty"`
-	ExampleGood  *string `json:"example_good,omitempty"`
-	ExampleBad   *string `json:"example_bad,omitempty"`
-	PartOfSpeech *string `json:"part_of_speech,omitempty"`
-	CEFRLevel    *string `json:"cefr_level,omitempty"`
+	ID           string                `json:"id"`
+	Text         string                `json:"text"`
+	Context      *string               `json:"context,omitempty"`
+	Contexts     []WordContextResponse `json:"contexts"`
+	Tags         []WordTagResponse     `json:"tags"`
+	Source       *string               `json:"source,omitempty"`
+	Confidence   *int                  `json:"confidence,omitempty"`
+	CreatedAt    string                `json:"created_at"`
+	Definition   *string               `json:"definition,omitempty"`
+	ExampleGood  *string               `json:"example_good,omitempty"`
+	ExampleBad   *string               `json:"example_bad,omitempty"`
+	PartOfSpeech *string               `json:"part_of_speech,omitempty"`
+	CEFRLevel    *string               `json:"cefr_level,omitempty"`
+	Translation  *string               `json:"translation,omitempty"`
+	AIVersion    *int                  `json:"explanation_version,omitempty"`
+	AISource     *string               `json:"explanation_source,omitempty"`
+	AIStatus     *string               `json:"ai_status"`
+	AIError      *string               `json:"ai_error,omitempty"`
+	ArchivedAt   *string               `json:"archived_at,omitempty"`
+}
+
+func toWordResponse(word *wordDomain.Word) WordResponse {
+	resp := WordResponse{
+		ID:         word.ID,
+		Text:       word.Text,
+		Contexts:   make([]WordContextResponse, len(word.Contexts)),
+		Tags:       make([]WordTagResponse, len(word.Tags)),
+		Source:     word.Source,
+		Confidence: word.Confidence,
+		CreatedAt:  word.CreatedAt.Format(time.RFC3339),
+	}
+
+	for i := range word.Contexts {
+		resp.Contexts[i] = toWordContextResponse(&word.Contexts[i])
+	}
+	for i, t := range word.Tags {
+		resp.Tags[i] = WordTagResponse{ID: t.ID, Name: t.Name}
+	}
+	// "context" is the most recent sentence, kept for older clients
+	if latest := word.LatestContext(); latest != nil {
+		resp.Context = &latest.Sentence
+	}
+
+	if word.ArchivedAt != nil {
+		archivedAt := word.ArchivedAt.Format(time.RFC3339)
+		resp.ArchivedAt = &archivedAt
+	}
+
+	if word.AIData != nil {
+		resp.Definition = &word.AIData.Definition
+		resp.ExampleGood = &word.AIData.ExampleGood
+		resp.ExampleBad = word.AIData.ExampleBad
+		resp.PartOfSpeech = word.AIData.PartOfSpeech
+		resp.CEFRLevel = word.AIData.CEFRLevel
+		resp.Translation = word.AIData.Translation
+
+		aiSource := string(word.AIData.Source)
+		resp.AIVersion = &word.AIData.Version
+		resp.AISource = &aiSource
+	}
+
+	if word.AIStatus != "" {
+		aiStatus := string(word.AIStatus)
+		resp.AIStatus = &aiStatus
+		resp.AIError = word.AIError
+	}
+
+	return resp
 }
 
 func (h *Handler) GetWord(w http.ResponseWriter, r *http.Request) {
@@ -85,58 +179,88 @@ func (h *Handler) GetWord(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	word := output.Word
-	resp := WordResponse{
-		ID:         word.ID,
-		Text:       word.Text,
-		Context:    word.Context,
-		Source:     word.Source,
-		Confidence: word.Confidence,
-		CreatedAt:  word.CreatedAt.Format(time.RFC3339),
-	}
-
-	if word.AIData != nil {
-		resp.Definition = &word.AIData.Definition
-		resp.ExampleGood = &word.AIData.ExampleGood
-		resp.ExampleBad = word.AIData.ExampleBad
-		resp.PartOfSpeech = word.AIData.PartOfSpeech
-		resp.CEFRLevel = word.AIData.CEFRLevel
-	}
-
-	writeJSON(w, http.StatusOK, resp)
+	writeJSON(w, http.StatusOK, toWordResponse(output.Word))
 }
 
 type ListWordsResponse struct {
-	Words []WordResponse `json:"words"`
-	Total int            `json:"total"`
+	Words      []WordResponse `json:"words"`
+	Total      int            `json:"total"`
+	NextCursor *string        `json:"next_cursor"`
 }
 
 func (h *Handler) ListWords(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	userID := mustUserIDFromContext(ctx)
 
-	// Parse pagination parameters
-	limit := 50
-	offset := 0
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil {
-			limit = l
-		}
+	q := newQueryParams(r)
+
+	// Offset paging was replaced by cursors; offset=0 still asks for the
+	// first page, so clients that always send it keep working
+	if offset := q.intPtr("offset"); offset != nil && *offset != 0 {
+		writeError(w, http.StatusBadRequest, "offset is no longer supported: pass next_cursor from the previous page as cursor")
+		return
 	}
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil {
-			offset = o
+
+	// Parse pagination parameters; the use case applies the default page size
+	limit := 0
+	if l := q.intPtr("limit"); l != nil {
+		if *l < 1 || *l > usecase.MaxPageSize {
+			q.fail("limit")
 		}
+		limit = *l
+	}
+
+	filter := wordDomain.ListFilter{
+		TagID:         q.string("tag"),
+		Query:         q.string("q"),
+		Match:         wordDomain.MatchMode(q.string("match")),
+		CEFRLevels:    q.list("cefr"),
+		PartOfSpeech:  q.string("pos"),
+		MinConfidence: q.intPtr("min_confidence"),
+		MaxConfidence: q.intPtr("max_confidence"),
+		HasAIData:     q.boolPtr("has_ai"),
+		CreatedFrom:   q.timePtr("created_from", false),
+		CreatedTo:     q.timePtr("created_to", true),
+		MinAccuracy:   q.floatPtr("min_accuracy"),
+		MaxAccuracy:   q.floatPtr("max_accuracy"),
+		Sort:          wordDomain.SortField(q.string("sort")),
+		Limit:         limit,
+	}
+	if archived := q.boolPtr("archived"); archived != nil {
+		filter.Archived = *archived
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = wordDomain.SortCreatedAt
+	}
+	switch q.string("order") {
+	case "":
+		filter.Descending = filter.Sort.DefaultDescending()
+	case "asc":
+		filter.Descending = false
+	case "desc":
+		filter.Descending = true
+	default:
+		q.fail("order")
+	}
+
+	if q.err != nil {
+		writeError(w, http.StatusBadRequest, q.err.Error())
+		return
 	}
 
 	input := usecase.ListWordsInput{
 		UserID: userID,
-		Limit:  limit,
-		Offset: offset,
+		Filter: filter,
+		Cursor: q.string("cursor"),
 	}
 
 	output, err := h.wordUseCase.ListWords(ctx, input)
 	if err != nil {
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, "invalid match, sort, range or cursor parameters")
+			return
+		}
 		h.logger.Error("failed to list words", "err", err)
 		writeError(w, http.StatusInternalServerError, "failed to list words")
 		return
@@ -144,26 +268,271 @@ func (h *Handler) ListWords(w http.ResponseWriter, r *http.Request) {
 
 	words := make([]WordResponse, len(output.Words))
 	for i, word := range output.Words {
-		words[i] = WordResponse{
-			ID:         word.ID,
-			Text:       word.Text,
-			Context:    word.Context,
-			Source:     word.Source,
-			Confidence: word.Confidence,
-			CreatedAt:  word.CreatedAt.Format(time.RFC3339),
+		words[i] = toWordResponse(word)
+	}
+
+	resp := ListWordsResponse{
+		Words: words,
+		Total: output.Total,
+	}
+	if output.NextCursor != "" {
+		resp.NextCursor = &output.NextCursor
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type UpdateWordRequest struct {
+	Text       *string `json:"text"`
+	Source     *string `json:"source"`
+	Confidence *int    `json:"confidence"`
+	// Context is no longer editable here; it is only read to reject it
+	Context json.RawMessage `json:"context"`
+}
+
+func (h *Handler) UpdateWord(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	if wordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word ID")
+		return
+	}
+
+	var req UpdateWordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if req.Context != nil {
+		writeError(w, http.StatusBadRequest, "context cannot be changed here: use /api/words/{id}/contexts")
+		return
+	}
+
+	input := usecase.UpdateWordInput{
+		WordID:     wordID,
+		UserID:     userID,
+		Text:       req.Text,
+		Source:     req.Source,
+		Confidence: req.Confidence,
+	}
+
+	output, err := h.wordUseCase.UpdateWord(ctx, input)
+	if err != nil {
+		if err == usecase.ErrNotFound {
+			writeError(w, http.StatusNotFound, "word not found")
+			return
+		}
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, "text must not be empty and confidence must be between 1 and 5")
+			return
+		}
+		if err == usecase.ErrConflict {
+			writeError(w, http.StatusConflict, "another of your words has the same lemma: combine them with POST /api/words/{id}/merge")
+			return
+		}
+		h.logger.Error("failed to update word", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to update word")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toWordResponse(output.Word))
+}
+
+func (h *Handler) ArchiveWord(w http.ResponseWriter, r *http.Request) {
+	h.wordLifecycleAction(w, r, "archive", h.wordUseCase.ArchiveWord)
+}
+
+func (h *Handler) UnarchiveWord(w http.ResponseWriter, r *http.Request) {
+	h.wordLifecycleAction(w, r, "unarchive", h.wordUseCase.UnarchiveWord)
+}
+
+func (h *Handler) DeleteWord(w http.ResponseWriter, r *http.Request) {
+	h.wordLifecycleAction(w, r, "delete", h.wordUseCase.DeleteWord)
+}
+
+// wordLifecycleAction runs a use case that only needs the word reference
+func (h *Handler) wordLifecycleAction(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	fn func(context.Context, usecase.WordRefInput) error,
+) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	if wordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word ID")
+		return
+	}
+
+	err := fn(ctx, usecase.WordRefInput{WordID: wordID, UserID: userID})
+	if err != nil {
+		if err == usecase.ErrNotFound {
+			writeError(w, http.StatusNotFound, "word not found")
+			return
 		}
+		h.logger.Error("failed to "+action+" word", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to "+action+" word")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+type MergeWordsRequest struct {
+	SourceWordID string `json:"source_word_id"`
+}
+
+func (h *Handler) MergeWords(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	if wordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word ID")
+		return
+	}
+
+	var req MergeWordsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	input := usecase.MergeWordsInput{
+		UserID:       userID,
+		TargetWordID: wordID,
+		SourceWordID: req.SourceWordID,
+	}
 
-		if word.AIData != nil {
-			words[i].Definition = &word.AIData.Definition
-			words[i].ExampleGood = &word.AIData.ExampleGood
-			words[i].ExampleBad = word.AIData.ExampleBad
-			words[i].PartOfSpeech = word.AIData.PartOfSpeech
-			words[i].CEFRLevel = word.AIData.CEFRLevel
+	output, err := h.wordUseCase.MergeWords(ctx, input)
+	if err != nil {
+		if err == usecase.ErrNotFound {
+			writeError(w, http.StatusNotFound, "word not found")
+			return
 		}
+		if err == usecase.ErrBadRequest {
+			writeError(w, http.StatusBadRequest, "source_word_id must be a different word")
+			return
+		}
+		h.logger.Error("failed to merge words", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to merge words")
+		return
 	}
 
-	writeJSON(w, http.StatusOK, ListWordsResponse{
-		Words: words,
-		Total: output.Total,
-	})
+	writeJSON(w, http.StatusOK, toWordResponse(output.Word))
+}
+
+type WordContextRequest struct {
+	Sentence    string  `json:"sentence"`
+	SourceURL   *string `json:"source_url"`
+	SourceTitle *string `json:"source_title"`
+}
+
+func (h *Handler) AddWordContext(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	if wordID == "" {
+		writeError(w, http.StatusBadRequest, "missing word ID")
+		return
+	}
+
+	var req WordContextRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	input := usecase.AddContextInput{
+		UserID:      userID,
+		WordID:      wordID,
+		Sentence:    req.Sentence,
+		SourceURL:   req.SourceURL,
+		SourceTitle: req.SourceTitle,
+	}
+
+	output, err := h.wordUseCase.AddContext(ctx, input)
+	if err != nil {
+		h.writeContextError(w, "add", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toWordContextResponse(output.Context))
+}
+
+func (h *Handler) UpdateWordContext(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	contextID := r.PathValue("contextID")
+	if wordID == "" || contextID == "" {
+		writeError(w, http.StatusBadRequest, "missing word or context ID")
+		return
+	}
+
+	var req WordContextRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	input := usecase.UpdateContextInput{
+		UserID:      userID,
+		WordID:      wordID,
+		ContextID:   contextID,
+		Sentence:    req.Sentence,
+		SourceURL:   req.SourceURL,
+		SourceTitle: req.SourceTitle,
+	}
+
+	output, err := h.wordUseCase.UpdateContext(ctx, input)
+	if err != nil {
+		h.writeContextError(w, "update", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toWordContextResponse(output.Context))
+}
+
+func (h *Handler) DeleteWordContext(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := mustUserIDFromContext(ctx)
+
+	wordID := r.PathValue("id")
+	contextID := r.PathValue("contextID")
+	if wordID == "" || contextID == "" {
+		writeError(w, http.StatusBadRequest, "missing word or context ID")
+		return
+	}
+
+	input := usecase.DeleteContextInput{
+		UserID:    userID,
+		WordID:    wordID,
+		ContextID: contextID,
+	}
+
+	if err := h.wordUseCase.DeleteContext(ctx, input); err != nil {
+		h.writeContextError(w, "delete", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func (h *Handler) writeContextError(w http.ResponseWriter, action string, err error) {
+	switch err {
+	case usecase.ErrNotFound:
+		writeError(w, http.StatusNotFound, "word or context not found")
+	case usecase.ErrBadRequest:
+		writeError(w, http.StatusBadRequest, "sentence is required")
+	default:
+		h.logger.Error("failed to "+action+" context", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to "+action+" context")
+	}
 }
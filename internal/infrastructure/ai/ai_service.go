@@ -1,6 +1,8 @@
 package ai
 
 import (
+	"context"
+
 	"github.com/sonsonha/eng-noting/internal/domain/ai"
 )
 
@@ -15,8 +17,8 @@ func NewAIService(client ai.Client) *AIService {
 }
 
 // ExplainWord generates an AI explanation for a word
-func (s *AIService) ExplainWord(word, context string) (*ai.AIExplanation, error) {
-	exp, err := ai.ExplainWordSafe(s.client, word, context)
+func (s *AIService) ExplainWord(ctx context.Context, req ai.ExplainRequest) (*ai.AIExplanation, error) {
+	exp, err := ai.ExplainWordSafe(ctx, s.client, req)
 	if err != nil {
 		return nil, err
 	}
@@ -27,5 +29,18 @@ func (s *AIService) ExplainWord(word, context string) (*ai.AIExplanation, error)
 		ExampleBad:   exp.ExampleBad,
 		PartOfSpeech: exp.PartOfSpeech,
 		CEFRLevel:    exp.CEFRLevel,
+
+		Model:         s.client.Model(),
+		PromptVersion: ai.PromptVersion,
 	}, nil
 }
+
+// SuggestDistractors generates wrong options for a multiple-choice question
+func (s *AIService) SuggestDistractors(ctx context.Context, word, definition string, n int) ([]string, error) {
+	return ai.SuggestDistractorsSafe(ctx, s.client, word, definition, n)
+}
+
+// WriteClozeSentence generates an example sentence for a fill-in-the-blank exercise
+func (s *AIService) WriteClozeSentence(ctx context.Context, word, definition string) (string, error) {
+	return ai.WriteClozeSentenceSafe(ctx, s.client, word, definition)
+}
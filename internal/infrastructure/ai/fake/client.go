@@ -0,0 +1,54 @@
+package fake
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sonsonha/eng-noting/internal/domain/ai"
+)
+
+// explanation is a fixed answer that passes ai.ValidateExplanation for
+// ordinary words
+const explanation = `{
+	"definition": "a placeholder meaning used when no language model is available",
+	"example_good": "This sentence is a placeholder example.",
+	"example_bad": "Placeholder sentence wrong order this is.",
+	"part_of_speech": "noun",
+	"cefr_level": "B1"
+}`
+
+// distractors is the fixed answer to distractor prompts
+const distractors = `{"distractors": ["careful", "ordinary", "sudden", "gentle", "narrow"]}`
+
+// clozeSentence is the fixed answer to cloze prompts. It does not contain
+// the requested word, so no AI cloze is ever built from it.
+const clozeSentence = `{"sentence": "This sentence is a placeholder example for a fill-in-the-blank exercise."}`
+
+// Client is a deterministic ai.Client for offline development and tests.
+// It never calls out and always returns the same answer to the same kind of prompt.
+type Client struct{}
+
+func NewClient() *Client {
+	return &Client{}
+}
+
+func (c *Client) Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if strings.Contains(userPrompt, `"distractors"`) {
+		return distractors, nil
+	}
+	if strings.Contains(userPrompt, `"sentence"`) {
+		return clozeSentence, nil
+	}
+	return explanation, nil
+}
+
+// Model returns the name recorded with fake explanations
+func (c *Client) Model() string {
+	return "fake"
+}
+
+// Ensure Client implements ai.Client interface
+var _ ai.Client = (*Client)(nil)
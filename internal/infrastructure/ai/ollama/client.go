@@ -0,0 +1,136 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/ai"
+)
+
+const (
+	// DefaultBaseURL is where a local Ollama server listens
+	DefaultBaseURL = "http://localhost:11434"
+	// DefaultModel is used when no model is configured
+	DefaultModel = "llama3.1"
+)
+
+// Config configures a Client
+type Config struct {
+	BaseURL     string
+	Model       string
+	Temperature float32
+	Timeout     time.Duration // per request; zero means no timeout
+}
+
+// Client talks to the Ollama chat API (POST /api/chat)
+type Client struct {
+	http        *http.Client
+	baseURL     string
+	model       string
+	temperature float32
+}
+
+func NewClient(cfg Config) *Client {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = DefaultModel
+	}
+
+	return &Client{
+		http:        &http.Client{Timeout: cfg.Timeout},
+		baseURL:     baseURL,
+		model:       model,
+		temperature: cfg.Temperature,
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Format   string        `json:"format,omitempty"`
+	Options  chatOptions   `json:"options"`
+}
+
+type chatOptions struct {
+	Temperature float32 `json:"temperature"`
+}
+
+type chatResponse struct {
+	Message chatMessage `json:"message"`
+	Error   string      `json:"error"`
+}
+
+func (c *Client) Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Stream:  false,
+		Format:  "json",
+		Options: chatOptions{Temperature: c.temperature},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Ollama API error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("Ollama API error: %w", err)
+	}
+
+	var out chatResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return "", fmt.Errorf("Ollama API error: status %d: unreadable response", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if out.Error != "" {
+			return "", fmt.Errorf("Ollama API error: status %d: %s", resp.StatusCode, out.Error)
+		}
+		return "", fmt.Errorf("Ollama API error: status %d", resp.StatusCode)
+	}
+
+	content := strings.TrimSpace(out.Message.Content)
+	if content == "" {
+		return "", fmt.Errorf("empty response from Ollama")
+	}
+	return content, nil
+}
+
+// Model returns the configured model name
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Ensure Client implements ai.Client interface
+var _ ai.Client = (*Client)(nil)
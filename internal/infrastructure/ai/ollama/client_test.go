@@ -0,0 +1,52 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompleteSendsChatRequest(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Write([]byte(`{"message":{"role":"assistant","content":" {\"definition\":\"x\"} "},"done":true}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(Config{BaseURL: srv.URL + "/", Model: "qwen2.5", Temperature: 0.2})
+	content, err := client.Complete(context.Background(), "system", "user")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if content != `{"definition":"x"}` {
+		t.Fatalf("unexpected content %q", content)
+	}
+	if got.Model != "qwen2.5" || got.Stream || got.Format != "json" || got.Options.Temperature != 0.2 {
+		t.Fatalf("unexpected request %+v", got)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[1].Content != "user" {
+		t.Fatalf("unexpected messages %+v", got.Messages)
+	}
+}
+
+func TestCompleteReportsServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model \"missing\" not found"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(Config{BaseURL: srv.URL, Model: "missing"})
+	if _, err := client.Complete(context.Background(), "system", "user"); err == nil {
+		t.Fatal("expected an error for a missing model")
+	}
+}
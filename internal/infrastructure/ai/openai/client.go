@@ -4,33 +4,65 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	openai "github.com/sashabaranov/go-openai"
 	"github.com/sonsonha/eng-noting/internal/domain/ai"
 )
 
+// DefaultModel is used when no model is configured
+const DefaultModel = "gpt-4o-mini"
+
+// Config configures a Client
+type Config struct {
+	APIKey      string
+	BaseURL     string // empty for api.openai.com; set for OpenAI-compatible servers
+	Model       string
+	Temperature float32
+	Timeout     time.Duration // per request; zero means no timeout
+}
+
 type Client struct {
-	client *openai.Client
+	client      *openai.Client
+	model       string
+	temperature float32
+	timeout     time.Duration
 }
 
-func NewClient(apiKey string) *Client {
-	if apiKey == "" {
-		return nil
+func NewClient(cfg Config) *Client {
+	clientConfig := openai.DefaultConfig(cfg.APIKey)
+	if cfg.BaseURL != "" {
+		clientConfig.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = DefaultModel
 	}
+
 	return &Client{
-		client: openai.NewClient(apiKey),
+		client:      openai.NewClientWithConfig(clientConfig),
+		model:       model,
+		temperature: cfg.Temperature,
+		timeout:     cfg.Timeout,
 	}
 }
 
-func (c *Client) ExplainWord(systemPrompt, userPrompt string) (string, error) {
+func (c *Client) Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
 	if c == nil || c.client == nil {
 		return "", fmt.Errorf("OpenAI client not initialized")
 	}
 
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
 	resp, err := c.client.CreateChatCompletion(
-		context.Background(),
+		ctx,
 		openai.ChatCompletionRequest{
-			Model: "gpt-4o-mini",
+			Model: c.model,
 			Messages: []openai.ChatCompletionMessage{
 				{
 					Role:    openai.ChatMessageRoleSystem,
@@ -44,7 +76,7 @@ func (c *Client) ExplainWord(systemPrompt, userPrompt string) (string, error) {
 			ResponseFormat: &openai.ChatCompletionResponseFormat{
 				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
 			},
-			Temperature: 0.3,
+			Temperature: c.temperature,
 		},
 	)
 
@@ -60,5 +92,10 @@ func (c *Client) ExplainWord(systemPrompt, userPrompt string) (string, error) {
 	return content, nil
 }
 
+// Model returns the configured model name
+func (c *Client) Model() string {
+	return c.model
+}
+
 // Ensure Client implements ai.Client interface
 var _ ai.Client = (*Client)(nil)
@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/ai"
+	"github.com/sonsonha/eng-noting/internal/infrastructure/ai/fake"
+	"github.com/sonsonha/eng-noting/internal/infrastructure/ai/ollama"
+	"github.com/sonsonha/eng-noting/internal/infrastructure/ai/openai"
+)
+
+// Provider names accepted by NewClient
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai-compatible"
+	ProviderOllama           = "ollama"
+	ProviderFake             = "fake"
+)
+
+// ProviderConfig selects and configures an AI provider.
+// Empty Model and BaseURL fall back to the provider's defaults.
+type ProviderConfig struct {
+	Provider    string
+	APIKey      string
+	Model       string
+	BaseURL     string
+	Temperature float32
+	Timeout     time.Duration
+}
+
+// ProviderFactory builds a client for one provider
+type ProviderFactory func(cfg ProviderConfig) (ai.Client, error)
+
+var providers = map[string]ProviderFactory{
+	ProviderOpenAI: func(cfg ProviderConfig) (ai.Client, error) {
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("AI_API_KEY is required for provider %q", cfg.Provider)
+		}
+		return openai.NewClient(openai.Config{
+			APIKey:      cfg.APIKey,
+			BaseURL:     cfg.BaseURL,
+			Model:       cfg.Model,
+			Temperature: cfg.Temperature,
+			Timeout:     cfg.Timeout,
+		}), nil
+	},
+	ProviderOpenAICompatible: func(cfg ProviderConfig) (ai.Client, error) {
+		if cfg.BaseURL == "" || cfg.Model == "" {
+			return nil, fmt.Errorf("AI_BASE_URL and AI_MODEL are required for provider %q", cfg.Provider)
+		}
+		// Local servers usually ignore the key but the header must be non-empty
+		apiKey := cfg.APIKey
+		if apiKey == "" {
+			apiKey = "unused"
+		}
+		return openai.NewClient(openai.Config{
+			APIKey:      apiKey,
+			BaseURL:     cfg.BaseURL,
+			Model:       cfg.Model,
+			Temperature: cfg.Temperature,
+			Timeout:     cfg.Timeout,
+		}), nil
+	},
+	ProviderOllama: func(cfg ProviderConfig) (ai.Client, error) {
+		return ollama.NewClient(ollama.Config{
+			BaseURL:     cfg.BaseURL,
+			Model:       cfg.Model,
+			Temperature: cfg.Temperature,
+			Timeout:     cfg.Timeout,
+		}), nil
+	},
+	ProviderFake: func(cfg ProviderConfig) (ai.Client, error) {
+		return fake.NewClient(), nil
+	},
+}
+
+// RegisterProvider adds or replaces a provider
+func RegisterProvider(name string, factory ProviderFactory) {
+	providers[strings.ToLower(name)] = factory
+}
+
+// NewClient builds the client of the configured provider
+func NewClient(cfg ProviderConfig) (ai.Client, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.Provider))
+	if name == "" {
+		name = ProviderOpenAI
+	}
+
+	factory, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown AI provider %q (known: %s)", cfg.Provider, strings.Join(providerNames(), ", "))
+	}
+	return factory(cfg)
+}
+
+func providerNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
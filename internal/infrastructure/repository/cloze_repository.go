@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+)
+
+// ClozeRepository implements exercise.ClozeRepository using PostgreSQL
+type ClozeRepository struct {
+	db *sql.DB
+}
+
+// NewClozeRepository creates a new ClozeRepository
+func NewClozeRepository(db *sql.DB) *ClozeRepository {
+	return &ClozeRepository{db: db}
+}
+
+// Get returns the cached cloze of a word, or nil if there is none
+func (r *ClozeRepository) Get(ctx context.Context, wordID string) (*exercise.Cloze, error) {
+	var c exercise.Cloze
+	err := r.db.QueryRowContext(ctx, `
+		SELECT text, sentence, answer, hint, source
+		FROM word_cloze
+		WHERE word_id = $1
+	`, wordID).Scan(&c.Text, &c.Sentence, &c.Answer, &c.Hint, &c.Source)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Store caches the cloze of a word, replacing the previous one
+func (r *ClozeRepository) Store(ctx context.Context, wordID string, c *exercise.Cloze) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO word_cloze (word_id, text, sentence, answer, hint, source)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (word_id) DO UPDATE SET
+			text = EXCLUDED.text,
+			sentence = EXCLUDED.sentence,
+			answer = EXCLUDED.answer,
+			hint = EXCLUDED.hint,
+			source = EXCLUDED.source,
+			created_at = now()
+	`, wordID, c.Text, c.Sentence, c.Answer, c.Hint, c.Source)
+	return err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// DistractorRepository implements exercise.DistractorRepository using PostgreSQL
+type DistractorRepository struct {
+	db *sql.DB
+}
+
+// NewDistractorRepository creates a new DistractorRepository
+func NewDistractorRepository(db *sql.DB) *DistractorRepository {
+	return &DistractorRepository{db: db}
+}
+
+// Candidates returns the user's other active words ranked as distractors for w.
+// Ties are broken randomly so repeated questions do not always show the same options.
+func (r *DistractorRepository) Candidates(ctx context.Context, w *wordDomain.Word, limit int) ([]string, error) {
+	var pos, cefr *string
+	if w.AIData != nil {
+		pos = w.AIData.PartOfSpeech
+		cefr = w.AIData.CEFRLevel
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT w.text
+		FROM words w
+		LEFT JOIN word_ai_data ai ON ai.word_id = w.id
+		WHERE w.user_id = $1
+			AND w.id <> $2
+			AND w.lemma <> $3
+			AND w.archived_at IS NULL
+			AND ($4::text IS NULL OR lower(ai.pos) = lower($4))
+		ORDER BY
+			abs(
+				array_position(ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2'], ai.cefr_level) -
+				array_position(ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2'], $5::text)
+			) NULLS LAST,
+			random()
+		LIMIT $6
+	`, w.UserID, w.ID, w.Lemma, pos, cefr, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var texts []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+
+	return texts, rows.Err()
+}
+
+// Cached returns the stored AI distractors of a word, in random order
+func (r *DistractorRepository) Cached(ctx context.Context, wordID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT text FROM word_distractors WHERE word_id = $1 ORDER BY random()
+	`, wordID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var texts []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+
+	return texts, rows.Err()
+}
+
+// Store caches AI distractors for a word, ignoring ones already stored
+func (r *DistractorRepository) Store(ctx context.Context, wordID string, distractors []string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO word_distractors (word_id, text)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING
+	`, wordID, pq.Array(distractors))
+	return err
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/job"
+)
+
+// JobRepository implements job.JobRepository using PostgreSQL
+type JobRepository struct {
+	db *sql.DB
+}
+
+// NewJobRepository creates a new JobRepository
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// Enqueue schedules the word's job, replacing any earlier one. The job gets
+// a new ID so that a worker still busy with the old job cannot mark the new
+// one as done.
+func (r *JobRepository) Enqueue(ctx context.Context, j *job.Job) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO ai_jobs (id, word_id, user_id, status, max_attempts, hint, base_version)
+		VALUES ($1, $2, $3, 'pending', $4, $5, $6)
+		ON CONFLICT (word_id) DO UPDATE SET
+			id = EXCLUDED.id,
+			status = 'pending',
+			attempts = 0,
+			max_attempts = EXCLUDED.max_attempts,
+			hint = EXCLUDED.hint,
+			base_version = EXCLUDED.base_version,
+			run_at = now(),
+			locked_at = NULL,
+			last_error = NULL,
+			updated_at = now()
+	`, j.ID, j.WordID, j.UserID, j.MaxAttempts, j.Hint, j.BaseVersion)
+	return err
+}
+
+// Claim marks the next due job as running. SKIP LOCKED lets several workers
+// poll at once without picking the same job.
+func (r *JobRepository) Claim(ctx context.Context, staleAfter time.Duration) (*job.Job, error) {
+	var j job.Job
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE ai_jobs SET
+			status = 'running',
+			attempts = attempts + 1,
+			locked_at = now(),
+			updated_at = now()
+		WHERE id = (
+			SELECT id FROM ai_jobs
+			WHERE (status = 'pending' AND run_at <= now())
+				OR (status = 'running' AND locked_at < now() - make_interval(secs => $1))
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, word_id, user_id, status, attempts, max_attempts, last_error, hint, base_version
+	`, staleAfter.Seconds()).Scan(
+		&j.ID,
+		&j.WordID,
+		&j.UserID,
+		&j.Status,
+		&j.Attempts,
+		&j.MaxAttempts,
+		&j.LastError,
+		&j.Hint,
+		&j.BaseVersion,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &j, nil
+}
+
+// Complete marks a running job as done. It is a no-op if the job was
+// replaced by Enqueue in the meantime.
+func (r *JobRepository) Complete(ctx context.Context, jobID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE ai_jobs SET
+			status = 'done',
+			locked_at = NULL,
+			last_error = NULL,
+			updated_at = now()
+		WHERE id = $1 AND status = 'running'
+	`, jobID)
+	return err
+}
+
+// Fail records a failed attempt and either reschedules the job or gives up
+func (r *JobRepository) Fail(ctx context.Context, jobID, lastError string, retryIn time.Duration) error {
+	status := job.StatusFailed
+	if retryIn > 0 {
+		status = job.StatusPending
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE ai_jobs SET
+			status = $2,
+			run_at = now() + make_interval(secs => $3),
+			locked_at = NULL,
+			last_error = $4,
+			updated_at = now()
+		WHERE id = $1 AND status = 'running'
+	`, jobID, status, retryIn.Seconds(), lastError)
+	return err
+}
+
+// RetryFailed re-enqueues failed jobs with a fresh set of attempts
+func (r *JobRepository) RetryFailed(ctx context.Context, userID, wordID string) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE ai_jobs SET
+			status = 'pending',
+			attempts = 0,
+			run_at = now(),
+			updated_at = now()
+		WHERE user_id = $1 AND status = 'failed' AND ($2 = '' OR word_id::text = $2)
+	`, userID, wordID)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sonsonha/eng-noting/internal/domain/job"
+)
+
+// jobColumns are the columns Claim returns
+var jobColumns = []string{"id", "word_id", "user_id", "status", "attempts", "max_attempts", "last_error", "hint", "base_version"}
+
+func TestClaimTakesDueOrStaleJobs(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewJobRepository(db)
+	lastError := "timeout"
+
+	// A pending job that is due, or a running one whose worker has held
+	// it for longer than staleAfter
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE (status = 'pending' AND run_at <= now())
+				OR (status = 'running' AND locked_at < now() - make_interval(secs => $1))`)).
+		WithArgs(float64(600)).
+		WillReturnRows(sqlmock.NewRows(jobColumns).AddRow("j1", "w1", "u1", "running", 2, 5, lastError, nil, 3))
+
+	j, err := repo.Claim(context.Background(), 10*time.Minute)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	want := job.Job{ID: "j1", WordID: "w1", UserID: "u1", Status: job.StatusRunning, Attempts: 2, MaxAttempts: 5, BaseVersion: 3}
+	if j == nil || j.LastError == nil || *j.LastError != lastError {
+		t.Fatalf("unexpected job %+v", j)
+	}
+	j.LastError = nil
+	if *j != want {
+		t.Errorf("expected %+v, got %+v", want, *j)
+	}
+}
+
+func TestClaimWithoutJobs(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewJobRepository(db)
+
+	mock.ExpectQuery(sqlPrefix("UPDATE ai_jobs SET")).WillReturnError(sql.ErrNoRows)
+
+	j, err := repo.Claim(context.Background(), time.Minute)
+	if err != nil || j != nil {
+		t.Fatalf("expected no job, got %+v, %v", j, err)
+	}
+}
+
+func TestFailSchedulesRetryOrGivesUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		retryIn time.Duration
+		status  job.Status
+	}{
+		{"retry", 2 * time.Minute, job.StatusPending},
+		{"give up", 0, job.StatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newTestDB(t)
+			repo := NewJobRepository(db)
+
+			// Only the running job is updated, not one that replaced it
+			mock.ExpectExec(regexp.QuoteMeta("WHERE id = $1 AND status = 'running'")).
+				WithArgs("j1", tt.status, tt.retryIn.Seconds(), "timeout").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			if err := repo.Fail(context.Background(), "j1", "timeout", tt.retryIn); err != nil {
+				t.Fatalf("fail: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryFailedResetsAttempts(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewJobRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta("attempts = 0")).
+		WithArgs("u1", "").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	n, err := repo.RetryFailed(context.Background(), "u1", "")
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 jobs retried, got %d", n)
+	}
+}
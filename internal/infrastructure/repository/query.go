@@ -0,0 +1,12 @@
+package repository
+
+import "strconv"
+
+// queryArgs collects positional arguments for a dynamically built query
+type queryArgs []any
+
+// add appends a value and returns its placeholder ("$1", "$2", ...)
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return "$" + strconv.Itoa(len(*a))
+}
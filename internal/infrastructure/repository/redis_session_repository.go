@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+)
+
+const (
+	// sessionKeyPrefix namespaces session keys in a shared Redis
+	sessionKeyPrefix = "eng-noting:session:"
+	// userSessionsKeyPrefix namespaces the per-user session indexes
+	userSessionsKeyPrefix = "eng-noting:user-sessions:"
+)
+
+// RedisSessionRepository implements session.SessionRepository on any server
+// speaking the Redis protocol. Each session is one hash whose TTL is the
+// session's expiry, so Redis drops expired sessions by itself. A sorted
+// set per user indexes their sessions by creation time; entries whose
+// session has expired are pruned when the user lists their sessions.
+type RedisSessionRepository struct {
+	client redis.UniversalClient
+}
+
+// NewRedisSessionRepository creates a new RedisSessionRepository
+func NewRedisSessionRepository(client redis.UniversalClient) *RedisSessionRepository {
+	return &RedisSessionRepository{client: client}
+}
+
+// redisSessionItem is the JSON stored for one item in the "items" field
+type redisSessionItem struct {
+	WordID        string  `json:"word_id,omitempty"`
+	ReviewType    string  `json:"review_type"`
+	PriorityScore float64 `json:"priority_score"`
+	Reason        string  `json:"reason"`
+	itemExercise
+}
+
+func sessionKey(sessionID string) string {
+	return sessionKeyPrefix + sessionID
+}
+
+func userSessionsKey(userID string) string {
+	return userSessionsKeyPrefix + userID
+}
+
+// Create stores a session with its items
+func (r *RedisSessionRepository) Create(ctx context.Context, s *session.Session, ttl time.Duration) error {
+	items := make([]redisSessionItem, len(s.Items))
+	for i, item := range s.Items {
+		items[i] = redisSessionItem{
+			WordID:        item.WordID,
+			ReviewType:    item.ReviewType,
+			PriorityScore: item.PriorityScore,
+			Reason:        item.Reason,
+			itemExercise:  itemExercise{MCQ: item.MCQ, Cloze: item.Cloze, Match: item.Match},
+		}
+	}
+	content, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	key := sessionKey(s.ID)
+	_, err = r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, key,
+			"user_id", s.UserID,
+			"tag_id", s.TagID,
+			"position", s.Index,
+			"item_count", len(s.Items),
+			"created_at", now.UnixMilli(),
+			"items", content,
+		)
+		p.PExpire(ctx, key, ttl)
+		p.ZAdd(ctx, userSessionsKey(s.UserID), redis.Z{Score: float64(now.UnixMilli()), Member: s.ID})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.CreatedAt = now
+	s.ExpiresAt = now.Add(ttl)
+	return nil
+}
+
+// Get returns a session that has not expired, with its items in order
+func (r *RedisSessionRepository) Get(ctx context.Context, sessionID string) (*session.Session, error) {
+	key := sessionKey(sessionID)
+	var fields *redis.MapStringStringCmd
+	var ttl *redis.DurationCmd
+	_, err := r.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		fields = p.HGetAll(ctx, key)
+		ttl = p.PTTL(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := fields.Val()
+	if len(values) == 0 {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	summary, err := parseSessionSummary(sessionID, values, ttl.Val())
+	if err != nil {
+		return nil, err
+	}
+	s := session.Session{
+		ID:          sessionID,
+		UserID:      values["user_id"],
+		TagID:       summary.TagID,
+		Index:       summary.Index,
+		CreatedAt:   summary.CreatedAt,
+		ExpiresAt:   summary.ExpiresAt,
+		AbandonedAt: summary.AbandonedAt,
+	}
+
+	var items []redisSessionItem
+	if err := json.Unmarshal([]byte(values["items"]), &items); err != nil {
+		return nil, err
+	}
+	s.Items = make([]session.SessionItem, len(items))
+	for i, item := range items {
+		s.Items[i] = session.SessionItem{
+			WordID:        item.WordID,
+			ReviewType:    item.ReviewType,
+			PriorityScore: item.PriorityScore,
+			Reason:        item.Reason,
+			MCQ:           item.MCQ,
+			Cloze:         item.Cloze,
+			Match:         item.Match,
+		}
+	}
+
+	return &s, nil
+}
+
+// parseSessionSummary reads the fields of a session hash other than its items
+func parseSessionSummary(sessionID string, values map[string]string, ttl time.Duration) (session.Summary, error) {
+	s := session.Summary{ID: sessionID, TagID: values["tag_id"], ExpiresAt: time.Now().Add(ttl)}
+	var err error
+	if s.Index, err = strconv.Atoi(values["position"]); err != nil {
+		return s, err
+	}
+	if s.Total, err = strconv.Atoi(values["item_count"]); err != nil {
+		return s, err
+	}
+	createdAt, err := strconv.ParseInt(values["created_at"], 10, 64)
+	if err != nil {
+		return s, err
+	}
+	s.CreatedAt = time.UnixMilli(createdAt)
+	if v, ok := values["abandoned_at"]; ok {
+		abandonedAt, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return s, err
+		}
+		t := time.UnixMilli(abandonedAt)
+		s.AbandonedAt = &t
+	}
+	return s, nil
+}
+
+// advanceScript checks the position and moves it on in one step, so of
+// concurrent advances from the same item only one succeeds. It returns -1
+// if the session does not exist and -2 if it is not at the given item or
+// has no next one.
+var advanceScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local position = tonumber(redis.call('HGET', KEYS[1], 'position'))
+if position ~= tonumber(ARGV[2]) or position >= tonumber(redis.call('HGET', KEYS[1], 'item_count')) then
+	return -2
+end
+position = redis.call('HINCRBY', KEYS[1], 'position', 1)
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return position
+`)
+
+// Advance moves a session from item from to the next
+func (r *RedisSessionRepository) Advance(ctx context.Context, sessionID string, from int, ttl time.Duration) (int, error) {
+	return r.move(ctx, advanceScript, sessionID, from, ttl)
+}
+
+// rewindScript is advanceScript backwards: -2 if the session is not at
+// the given item or has none before it
+var rewindScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local position = tonumber(redis.call('HGET', KEYS[1], 'position'))
+if position ~= tonumber(ARGV[2]) or position <= 0 then
+	return -2
+end
+position = redis.call('HINCRBY', KEYS[1], 'position', -1)
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return position
+`)
+
+// Rewind moves a session from item from back to the one before
+func (r *RedisSessionRepository) Rewind(ctx context.Context, sessionID string, from int, ttl time.Duration) (int, error) {
+	return r.move(ctx, rewindScript, sessionID, from, ttl)
+}
+
+// move runs a script that moves a session from an item
+func (r *RedisSessionRepository) move(ctx context.Context, script *redis.Script, sessionID string, from int, ttl time.Duration) (int, error) {
+	index, err := script.Run(ctx, r.client, []string{sessionKey(sessionID)}, ttl.Milliseconds(), from).Int()
+	if err != nil {
+		return 0, err
+	}
+	switch index {
+	case -1:
+		return 0, domain.ErrSessionNotFound
+	case -2:
+		return 0, domain.ErrSessionMoved
+	}
+	return index, nil
+}
+
+// abandonScript stamps a session abandoned and sets its expiry unless it
+// already is abandoned. It returns 0 if the session does not exist.
+var abandonScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+if redis.call('HSETNX', KEYS[1], 'abandoned_at', ARGV[1]) == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// Abandon marks a session abandoned, keeping the time it first was and
+// the expiry it was given then
+func (r *RedisSessionRepository) Abandon(ctx context.Context, sessionID string, ttl time.Duration) error {
+	found, err := abandonScript.Run(ctx, r.client, []string{sessionKey(sessionID)}, time.Now().UnixMilli(), ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if found == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+// List returns a page of a user's sessions that have not expired, newest
+// first. A user has few live sessions, so the index is read whole and
+// filtered here.
+func (r *RedisSessionRepository) List(ctx context.Context, filter session.ListFilter) (*session.Page, error) {
+	indexKey := userSessionsKey(filter.UserID)
+	ids, err := r.client.ZRevRange(ctx, indexKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]*redis.SliceCmd, len(ids))
+	ttls := make([]*redis.DurationCmd, len(ids))
+	_, err = r.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, id := range ids {
+			fields[i] = p.HMGet(ctx, sessionKey(id), "tag_id", "position", "item_count", "created_at", "abandoned_at")
+			ttls[i] = p.PTTL(ctx, sessionKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []session.Summary
+	var expired []any
+	for i, id := range ids {
+		raw := fields[i].Val()
+		if raw[1] == nil {
+			expired = append(expired, id)
+			continue
+		}
+		values := make(map[string]string)
+		for j, name := range []string{"tag_id", "position", "item_count", "created_at", "abandoned_at"} {
+			if v, ok := raw[j].(string); ok {
+				values[name] = v
+			}
+		}
+		s, err := parseSessionSummary(id, values, ttls[i].Val())
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	if len(expired) > 0 {
+		if err := r.client.ZRem(ctx, indexKey, expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// The index is ordered by millisecond; break ties by ID as Postgres does
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if !sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+		}
+		return sessions[i].ID > sessions[j].ID
+	})
+
+	page := &session.Page{}
+	for _, s := range sessions {
+		if filter.Status != "" && s.Status() != filter.Status {
+			continue
+		}
+		if a := filter.After; a != nil && !(s.CreatedAt.Before(a.CreatedAt) || s.CreatedAt.Equal(a.CreatedAt) && s.ID < a.ID) {
+			continue
+		}
+		if len(page.Sessions) == filter.Limit {
+			last := page.Sessions[len(page.Sessions)-1]
+			page.Next = &session.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+			break
+		}
+		page.Sessions = append(page.Sessions, s)
+	}
+	return page, nil
+}
+
+// DeleteExpired is a no-op: Redis expires sessions itself
+func (r *RedisSessionRepository) DeleteExpired(ctx context.Context) (int, error) {
+	return 0, nil
+}
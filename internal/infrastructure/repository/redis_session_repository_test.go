@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+)
+
+func newTestRedisSessions(t *testing.T) (*RedisSessionRepository, *miniredis.Miniredis) {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisSessionRepository(client), srv
+}
+
+func testSession(items int) *session.Session {
+	s := &session.Session{ID: "s1", UserID: "u1"}
+	for i := 0; i < items; i++ {
+		s.Items = append(s.Items, session.SessionItem{WordID: "w", ReviewType: "recognition", PriorityScore: 50, Reason: "due"})
+	}
+	return s
+}
+
+func TestRedisSessionRoundTrip(t *testing.T) {
+	repo, _ := newTestRedisSessions(t)
+	ctx := context.Background()
+
+	s := testSession(1)
+	s.Items = append(s.Items,
+		session.SessionItem{
+			WordID:     "w2",
+			ReviewType: "mcq",
+			MCQ:        &exercise.MCQ{Prompt: "p", PromptKind: exercise.PromptDefinition, Options: []string{"a", "b"}},
+		},
+		session.SessionItem{
+			ReviewType: "match",
+			Match: &exercise.MatchBoard{
+				Words:       []exercise.MatchCard{{ID: "w3", Text: "t"}},
+				Definitions: []exercise.MatchCard{{ID: "d1", Text: "d"}},
+				Key:         map[string]string{"w3": "d1"},
+			},
+		},
+	)
+	if err := repo.Create(ctx, s, time.Hour); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got, err := repo.Get(ctx, "s1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.UserID != "u1" || got.Index != 0 || len(got.Items) != 3 {
+		t.Fatalf("unexpected session %+v", got)
+	}
+	if got.Items[1].MCQ == nil || got.Items[1].MCQ.Options[1] != "b" {
+		t.Fatalf("mcq not restored: %+v", got.Items[1])
+	}
+	if got.Items[2].WordID != "" || got.Items[2].Match == nil || got.Items[2].Match.Key["w3"] != "d1" {
+		t.Fatalf("board not restored: %+v", got.Items[2])
+	}
+	if !got.ExpiresAt.After(time.Now()) {
+		t.Fatalf("expected a future expiry, got %v", got.ExpiresAt)
+	}
+}
+
+func TestRedisSessionMissing(t *testing.T) {
+	repo, _ := newTestRedisSessions(t)
+	ctx := context.Background()
+
+	if _, err := repo.Get(ctx, "nope"); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Fatalf("get: expected ErrSessionNotFound, got %v", err)
+	}
+	if _, err := repo.Advance(ctx, "nope", 0, time.Hour); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Fatalf("advance: expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestRedisSessionExpires(t *testing.T) {
+	repo, srv := newTestRedisSessions(t)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, testSession(3), time.Minute); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Advancing renews the TTL
+	srv.FastForward(50 * time.Second)
+	if _, err := repo.Advance(ctx, "s1", 0, time.Minute); err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	srv.FastForward(50 * time.Second)
+	if _, err := repo.Get(ctx, "s1"); err != nil {
+		t.Fatalf("expected the session to be kept alive, got %v", err)
+	}
+
+	srv.FastForward(time.Minute)
+	if _, err := repo.Get(ctx, "s1"); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound after expiry, got %v", err)
+	}
+}
+
+func TestRedisSessionAdvanceStopsAtEnd(t *testing.T) {
+	repo, _ := newTestRedisSessions(t)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, testSession(2), time.Hour); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for from, want := range []int{1, 2} {
+		got, err := repo.Advance(ctx, "s1", from, time.Hour)
+		if err != nil {
+			t.Fatalf("advance %d: %v", from, err)
+		}
+		if got != want {
+			t.Fatalf("advance %d: expected position %d, got %d", from, want, got)
+		}
+	}
+	if _, err := repo.Advance(ctx, "s1", 2, time.Hour); !errors.Is(err, domain.ErrSessionMoved) {
+		t.Fatalf("expected ErrSessionMoved past the end, got %v", err)
+	}
+}
+
+func TestRedisSessionAdvanceFromStaleItem(t *testing.T) {
+	repo, _ := newTestRedisSessions(t)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, testSession(3), time.Hour); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := repo.Advance(ctx, "s1", 0, time.Hour); err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if _, err := repo.Advance(ctx, "s1", 0, time.Hour); !errors.Is(err, domain.ErrSessionMoved) {
+		t.Fatalf("expected ErrSessionMoved, got %v", err)
+	}
+
+	got, err := repo.Get(ctx, "s1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Index != 1 {
+		t.Fatalf("expected position 1, got %d", got.Index)
+	}
+}
+
+func TestRedisSessionRewind(t *testing.T) {
+	repo, _ := newTestRedisSessions(t)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, testSession(2), time.Hour); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := repo.Rewind(ctx, "s1", 0, time.Hour); !errors.Is(err, domain.ErrSessionMoved) {
+		t.Fatalf("expected ErrSessionMoved before the first item, got %v", err)
+	}
+	if _, err := repo.Advance(ctx, "s1", 0, time.Hour); err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if _, err := repo.Rewind(ctx, "s1", 2, time.Hour); !errors.Is(err, domain.ErrSessionMoved) {
+		t.Fatalf("expected ErrSessionMoved from a stale item, got %v", err)
+	}
+
+	got, err := repo.Rewind(ctx, "s1", 1, time.Hour)
+	if err != nil {
+		t.Fatalf("rewind: %v", err)
+	}
+	if got != 0 {
+		t.Fatalf("expected position 0, got %d", got)
+	}
+	if _, err := repo.Rewind(ctx, "nope", 1, time.Hour); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestRedisSessionConcurrentAdvance(t *testing.T) {
+	repo, _ := newTestRedisSessions(t)
+	ctx := context.Background()
+
+	const tabs = 8
+	if err := repo.Create(ctx, testSession(20), time.Hour); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Every tab submits the first item; only one may move the session on
+	var wg sync.WaitGroup
+	var moved atomic.Int32
+	for i := 0; i < tabs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Advance(ctx, "s1", 0, time.Hour)
+			switch {
+			case err == nil:
+				moved.Add(1)
+			case !errors.Is(err, domain.ErrSessionMoved):
+				t.Errorf("advance: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := moved.Load(); n != 1 {
+		t.Fatalf("expected one advance to succeed, got %d", n)
+	}
+	got, err := repo.Get(ctx, "s1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Index != 1 {
+		t.Fatalf("expected position 1, got %d", got.Index)
+	}
+}
+
+func TestRedisSessionListAndAbandon(t *testing.T) {
+	repo, srv := newTestRedisSessions(t)
+	ctx := context.Background()
+
+	ids := []string{"a", "b", "c"}
+	for _, id := range ids {
+		s := testSession(1)
+		s.ID = id
+		if err := repo.Create(ctx, s, time.Minute); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+		time.Sleep(2 * time.Millisecond) // distinct creation times
+	}
+	if _, err := repo.Advance(ctx, "b", 0, time.Minute); err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if err := repo.Abandon(ctx, "c", time.Minute); err != nil {
+		t.Fatalf("abandon: %v", err)
+	}
+
+	page, err := repo.List(ctx, session.ListFilter{UserID: "u1", Limit: 2})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Sessions) != 2 || page.Sessions[0].ID != "c" || page.Sessions[1].ID != "b" || page.Next == nil {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+	if page.Sessions[0].Status() != session.StatusAbandoned || page.Sessions[1].Status() != session.StatusCompleted {
+		t.Fatalf("unexpected statuses %+v", page.Sessions)
+	}
+
+	page, err = repo.List(ctx, session.ListFilter{UserID: "u1", Limit: 2, After: page.Next})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Sessions) != 1 || page.Sessions[0].ID != "a" || page.Next != nil {
+		t.Fatalf("unexpected second page %+v", page)
+	}
+
+	page, err = repo.List(ctx, session.ListFilter{UserID: "u1", Status: session.StatusActive, Limit: 10})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Sessions) != 1 || page.Sessions[0].ID != "a" {
+		t.Fatalf("expected only the active session, got %+v", page.Sessions)
+	}
+
+	// Expired sessions drop out of the listing and the index
+	srv.FastForward(2 * time.Minute)
+	page, err = repo.List(ctx, session.ListFilter{UserID: "u1", Limit: 10})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Sessions) != 0 {
+		t.Fatalf("expected no sessions after expiry, got %+v", page.Sessions)
+	}
+	if srv.Exists(userSessionsKey("u1")) {
+		t.Fatal("expected the index to be pruned")
+	}
+	if err := repo.Abandon(ctx, "a", time.Minute); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Fatalf("abandon: expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestRedisSessionAbandonKeepsSession(t *testing.T) {
+	repo, srv := newTestRedisSessions(t)
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, testSession(2), time.Minute); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Abandon(ctx, "s1", time.Hour); err != nil {
+		t.Fatalf("abandon: %v", err)
+	}
+	if ttl := srv.TTL(sessionKey("s1")); ttl != time.Hour {
+		t.Fatalf("expected the session kept for an hour, got %v", ttl)
+	}
+
+	// Abandoning it again does not extend it
+	srv.FastForward(time.Minute)
+	if err := repo.Abandon(ctx, "s1", time.Hour); err != nil {
+		t.Fatalf("abandon again: %v", err)
+	}
+	if ttl := srv.TTL(sessionKey("s1")); ttl != 59*time.Minute {
+		t.Fatalf("expected the first expiry kept, got %v", ttl)
+	}
+}
@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 
+	"github.com/lib/pq"
 	"github.com/sonsonha/eng-noting/internal/domain/session"
 )
 
@@ -46,14 +48,46 @@ func (r *ReviewQueueRepository) Rebuild(ctx context.Context, userID string, item
 	return tx.Commit()
 }
 
-// GetQueueItems retrieves queue items for a user
-func (r *ReviewQueueRepository) GetQueueItems(ctx context.Context, userID string) ([]session.ReviewQueueItem, error) {
+// lowered returns the strings in lower case
+func lowered(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToLower(v)
+	}
+	return out
+}
+
+// GetQueueItems retrieves queue items for a user, highest priority first
+func (r *ReviewQueueRepository) GetQueueItems(ctx context.Context, userID string, filter session.QueueFilter) ([]session.ReviewQueueItem, error) {
+	var args queryArgs
+	conds := []string{"rq.user_id = " + args.add(userID)}
+
+	if len(filter.TagIDs) > 0 {
+		conds = append(conds, `EXISTS (
+			SELECT 1 FROM word_tags wt
+			WHERE wt.word_id = rq.word_id AND wt.tag_id = ANY(`+args.add(pq.Array(filter.TagIDs))+`)
+		)`)
+	}
+	if len(filter.CEFRLevels) > 0 || len(filter.PartsOfSpeech) > 0 {
+		aiConds := []string{"ai.word_id = rq.word_id"}
+		if len(filter.CEFRLevels) > 0 {
+			aiConds = append(aiConds, "lower(ai.cefr_level) = ANY("+args.add(pq.Array(lowered(filter.CEFRLevels)))+")")
+		}
+		if len(filter.PartsOfSpeech) > 0 {
+			aiConds = append(aiConds, "lower(ai.pos) = ANY("+args.add(pq.Array(lowered(filter.PartsOfSpeech)))+")")
+		}
+		conds = append(conds, `EXISTS (
+			SELECT 1 FROM word_ai_data ai
+			WHERE `+strings.Join(aiConds, " AND ")+`
+		)`)
+	}
+
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT user_id, word_id, priority_score, reason
-		FROM review_queue
-		WHERE user_id = $1
-		ORDER BY priority_score DESC
-	`, userID)
+		SELECT rq.user_id, rq.word_id, rq.priority_score, rq.reason
+		FROM review_queue rq
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY rq.priority_score DESC
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+)
+
+func TestGetQueueItemsMatchesCEFRAnyCase(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewReviewQueueRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("lower(ai.cefr_level) = ANY($2)")).
+		WithArgs("u1", `{"b1","c1"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "word_id", "priority_score", "reason"}).
+			AddRow("u1", "w1", 70.0, "Due"))
+
+	items, err := repo.GetQueueItems(context.Background(), "u1", session.QueueFilter{CEFRLevels: []string{"B1", "c1"}})
+	if err != nil {
+		t.Fatalf("queue items: %v", err)
+	}
+	if len(items) != 1 || items[0].WordID != "w1" {
+		t.Errorf("items = %+v", items)
+	}
+}
+
+func TestGetQueueItemsRestrictsToDecks(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewReviewQueueRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("wt.word_id = rq.word_id AND wt.tag_id = ANY($2)")).
+		WithArgs("u1", `{"t1","t2"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "word_id", "priority_score", "reason"}))
+
+	if _, err := repo.GetQueueItems(context.Background(), "u1", session.QueueFilter{TagIDs: []string{"t1", "t2"}}); err != nil {
+		t.Fatalf("queue items: %v", err)
+	}
+}
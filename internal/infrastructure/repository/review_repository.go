@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
-	"github.com/sonsonha/eng-noting/internal/domain/review"
+	"github.com/lib/pq"
+	"github.com/sonsonha/eng-noting/internal/domain"
+	domainReview "github.com/sonsonha/eng-noting/internal/domain/review"
 )
 
 // ReviewRepository implements domain.ReviewRepository using PostgreSQL
@@ -18,128 +21,125 @@ func NewReviewRepository(db *sql.DB) *ReviewRepository {
 	return &ReviewRepository{db: db}
 }
 
-// Create creates a new review within a transaction
-func (r *ReviewRepository) Create(ctx context.Context, review *review.Review) error {
-	tx, ok := ctx.Value("tx").(*sql.Tx)
-	if !ok {
-		// If no transaction, start one
-		var err error
-		tx, err = r.db.BeginTx(ctx, nil)
-		if err != nil {
-			return err
-		}
-		defer tx.Rollback()
-
-		_, err = tx.ExecContext(ctx, `
-			INSERT INTO reviews (id, word_id, user_id, result, review_type, reviewed_at)
-			VALUES ($1, $2, $3, $4, $5, $6)
-		`, review.ID, review.WordID, review.UserID, review.Result, review.ReviewType, time.Now())
+// Create stores a new review. It leaves the word's statistics alone:
+// callers update them in the same unit of work.
+func (r *ReviewRepository) Create(ctx context.Context, review *domainReview.Review) error {
+	if review.ReviewedAt.IsZero() {
+		review.ReviewedAt = time.Now()
+	}
 
-		if err != nil {
-			return err
-		}
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO reviews (
+			id, word_id, user_id, result, score, answer,
+			quality, latency_ms, hesitant, review_type, session_id, reviewed_at, idempotency_key
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, review.ID, review.WordID, review.UserID, review.Result, review.Score, review.Answer,
+		review.Quality, millis(review.Latency), review.Hesitant, review.ReviewType, review.SessionID,
+		review.ReviewedAt, review.IdempotencyKey)
+	return mapReviewError(err)
+}
 
-		// Update stats
-		if err := r.UpdateStats(ctx, review.WordID, review.Result); err != nil {
-			return err
+// mapReviewError turns a unique violation on (user_id, idempotency_key)
+// into ErrReviewKeyUsed, and one on (session_id, word_id) into
+// ErrReviewExists
+func mapReviewError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		if pqErr.Constraint == "idx_reviews_idempotency" {
+			return domain.ErrReviewKeyUsed
 		}
-
-		return tx.Commit()
+		return domain.ErrReviewExists
 	}
-
-	// Use existing transaction
-	_, err := tx.ExecContext(ctx, `
-		INSERT INTO reviews (id, word_id, user_id, result, review_type, reviewed_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, review.ID, review.WordID, review.UserID, review.Result, review.ReviewType, time.Now())
 	return err
 }
 
-// GetStats retrieves review statistics for a word
-func (r *ReviewRepository) GetStats(ctx context.Context, wordID string) (*review.ReviewStats, error) {
-	var stats review.ReviewStats
-	var lastReviewedAt sql.NullTime
+// GetByKey returns the user's review with the idempotency key, or nil
+func (r *ReviewRepository) GetByKey(ctx context.Context, userID, key string) (*domainReview.Review, error) {
+	rev, err := scanReview(conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews
+		WHERE user_id = $1 AND idempotency_key = $2
+	`, userID, key))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rev, err
+}
+
+// hesitationWindow is how many of the latest right answers make up the
+// hesitation rate
+const hesitationWindow = 5
 
-	err := r.db.QueryRowContext(ctx, `
+// ListStats retrieves the review statistics of several words; words
+// never reviewed get zero statistics
+func (r *ReviewRepository) ListStats(ctx context.Context, wordIDs []string) (map[string]*domainReview.ReviewStats, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
 		SELECT
 			word_id,
 			total_reviews,
 			correct_reviews,
 			last_reviewed_at,
 			accuracy_rate,
-			memory_score
+			memory_score,
+			(
+				SELECT COALESCE(AVG(h.hesitant::int), 0)
+				FROM (
+					SELECT r.hesitant
+					FROM reviews r
+					WHERE r.word_id = review_stats.word_id AND r.result = true
+					ORDER BY r.reviewed_at DESC
+					LIMIT $2
+				) h
+			) AS hesitation_rate
 		FROM review_stats
-		WHERE word_id = $1
-	`, wordID).Scan(
-		&stats.WordID,
-		&stats.TotalReviews,
-		&stats.CorrectReviews,
-		&lastReviewedAt,
-		&stats.AccuracyRate,
-		&stats.MemoryScore,
-	)
-
-	if err == sql.ErrNoRows {
-		// Return default stats if not found
-		return &review.ReviewStats{
-			WordID:         wordID,
-			TotalReviews:   0,
-			CorrectReviews: 0,
-			AccuracyRate:   0.0,
-			MemoryScore:    0.0,
-		}, nil
-	}
+		WHERE word_id = ANY($1)
+	`, pq.Array(wordIDs), hesitationWindow)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	if lastReviewedAt.Valid {
-		stats.LastReviewedAt = &lastReviewedAt.Time
+	stats := make(map[string]*domainReview.ReviewStats, len(wordIDs))
+	for rows.Next() {
+		var s domainReview.ReviewStats
+		var lastReviewedAt sql.NullTime
+		if err := rows.Scan(
+			&s.WordID,
+			&s.TotalReviews,
+			&s.CorrectReviews,
+			&lastReviewedAt,
+			&s.AccuracyRate,
+			&s.MemoryScore,
+			&s.HesitationRate,
+		); err != nil {
+			return nil, err
+		}
+		if lastReviewedAt.Valid {
+			s.LastReviewedAt = &lastReviewedAt.Time
+		}
+		stats[s.WordID] = &s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return &stats, nil
+	for _, id := range wordIDs {
+		if _, ok := stats[id]; !ok {
+			stats[id] = &domainReview.ReviewStats{WordID: id}
+		}
+	}
+	return stats, nil
 }
 
 // UpdateStats updates review statistics for a word
-func (r *ReviewRepository) UpdateStats(ctx context.Context, wordID string, result bool) error {
-	tx, ok := ctx.Value("tx").(*sql.Tx)
-
-	var err error
-	if ok {
-		_, err = tx.ExecContext(ctx, `
-		INSERT INTO review_stats (
-			word_id,
-			total_reviews,
-			correct_reviews,
-			last_reviewed_at,
-			accuracy_rate
-		)
-		VALUES (
-			$1,
-			1,
-			CASE WHEN $2 = true THEN 1 ELSE 0 END,
-			now(),
-			CASE WHEN $2 = true THEN 1.0 ELSE 0.0 END
-		)
-		ON CONFLICT (word_id)
-		DO UPDATE SET
-			total_reviews = review_stats.total_reviews + 1,
-			correct_reviews =
-				review_stats.correct_reviews
-				+ CASE WHEN $2 = true THEN 1 ELSE 0 END,
-			last_reviewed_at = now(),
-			accuracy_rate =
-				(review_stats.correct_reviews
-				 + CASE WHEN $2 = true THEN 1 ELSE 0 END)::float
-				/ (review_stats.total_reviews + 1)
-	`, wordID, result)
-	} else {
-		// If no transaction, use db directly
-		_, err = r.db.ExecContext(ctx, `
+func (r *ReviewRepository) UpdateStats(ctx context.Context, wordID string, outcome domainReview.Outcome) error {
+	query := `
 		INSERT INTO review_stats (
 			word_id,
 			total_reviews,
 			correct_reviews,
+			score_sum,
 			last_reviewed_at,
 			accuracy_rate
 		)
@@ -147,8 +147,9 @@ func (r *ReviewRepository) UpdateStats(ctx context.Context, wordID string, resul
 			$1,
 			1,
 			CASE WHEN $2 = true THEN 1 ELSE 0 END,
+			$3,
 			now(),
-			CASE WHEN $2 = true THEN 1.0 ELSE 0.0 END
+			$3
 		)
 		ON CONFLICT (word_id)
 		DO UPDATE SET
@@ -156,39 +157,269 @@ func (r *ReviewRepository) UpdateStats(ctx context.Context, wordID string, resul
 			correct_reviews =
 				review_stats.correct_reviews
 				+ CASE WHEN $2 = true THEN 1 ELSE 0 END,
+			score_sum = review_stats.score_sum + $3,
 			last_reviewed_at = now(),
 			accuracy_rate =
-				(review_stats.correct_reviews
-				 + CASE WHEN $2 = true THEN 1 ELSE 0 END)::float
+				(review_stats.score_sum + $3)
 				/ (review_stats.total_reviews + 1)
-	`, wordID, result)
-	}
+	`
 
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, wordID, outcome.Correct, outcome.Score)
 	return err
 }
 
-// GetLastReviewType retrieves the last review type for a word
-func (r *ReviewRepository) GetLastReviewType(ctx context.Context, wordID string) (string, error) {
-	var reviewType sql.NullString
+// RecomputeStats rebuilds a word's statistics from its reviews
+func (r *ReviewRepository) RecomputeStats(ctx context.Context, wordID string) error {
+	return withinTx(ctx, r.db, func(ctx context.Context) error {
+		return recomputeReviewStats(ctx, conn(ctx, r.db), wordID)
+	})
+}
 
-	err := r.db.QueryRowContext(ctx, `
-		SELECT review_type 
-		FROM reviews 
-		WHERE word_id = $1 
-		ORDER BY reviewed_at DESC 
-		LIMIT 1
-	`, wordID).Scan(&reviewType)
+// LastReviewTypes retrieves the last review type of several words;
+// words never reviewed are left out
+func (r *ReviewRepository) LastReviewTypes(ctx context.Context, wordIDs []string) (map[string]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT DISTINCT ON (word_id) word_id, review_type
+		FROM reviews
+		WHERE word_id = ANY($1) AND review_type IS NOT NULL
+		ORDER BY word_id, reviewed_at DESC
+	`, pq.Array(wordIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := make(map[string]string, len(wordIDs))
+	for rows.Next() {
+		var wordID, reviewType string
+		if err := rows.Scan(&wordID, &reviewType); err != nil {
+			return nil, err
+		}
+		types[wordID] = reviewType
+	}
+	return types, rows.Err()
+}
+
+// reviewColumns are the columns scanReview reads, in order
+const reviewColumns = `
+	id, word_id, user_id, result, score, answer,
+	quality, latency_ms, hesitant, review_type, session_id, reviewed_at, idempotency_key
+`
+
+// scanReview reads a row of reviewColumns
+func scanReview(row interface{ Scan(...any) error }) (*domainReview.Review, error) {
+	var rev domainReview.Review
+	var answer, quality, sessionID, key sql.NullString
+	var latencyMs sql.NullInt64
+	err := row.Scan(
+		&rev.ID, &rev.WordID, &rev.UserID, &rev.Result, &rev.Score, &answer,
+		&quality, &latencyMs, &rev.Hesitant, &rev.ReviewType, &sessionID, &rev.ReviewedAt, &key,
+	)
+	if err != nil {
+		return nil, err
+	}
 
+	if answer.Valid {
+		rev.Answer = &answer.String
+	}
+	if quality.Valid {
+		q := domainReview.Quality(quality.String)
+		rev.Quality = &q
+	}
+	if latencyMs.Valid {
+		latency := time.Duration(latencyMs.Int64) * time.Millisecond
+		rev.Latency = &latency
+	}
+	if sessionID.Valid {
+		rev.SessionID = &sessionID.String
+	}
+	if key.Valid {
+		rev.IdempotencyKey = &key.String
+	}
+	return &rev, nil
+}
+
+// List returns a page of a user's reviews, newest first
+func (r *ReviewRepository) List(ctx context.Context, filter domainReview.ListFilter) (*domainReview.Page, error) {
+	var args queryArgs
+	where := "user_id = " + args.add(filter.UserID)
+	if filter.WordID != "" {
+		where += " AND word_id = " + args.add(filter.WordID)
+	}
+	if filter.From != nil {
+		where += " AND reviewed_at >= " + args.add(*filter.From)
+	}
+	if filter.To != nil {
+		where += " AND reviewed_at < " + args.add(*filter.To)
+	}
+	if filter.After != nil {
+		where += " AND (reviewed_at, id) < (" + args.add(filter.After.ReviewedAt) + ", " + args.add(filter.After.ID) + ")"
+	}
+
+	// One extra row is fetched to learn whether another page follows
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews
+		WHERE `+where+`
+		ORDER BY reviewed_at DESC, id DESC
+		LIMIT `+args.add(filter.Limit+1), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*domainReview.Review
+	for rows.Next() {
+		rev, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &domainReview.Page{Reviews: reviews}
+	if len(reviews) > filter.Limit {
+		page.Reviews = reviews[:filter.Limit]
+		last := page.Reviews[len(page.Reviews)-1]
+		page.Next = &domainReview.Cursor{ReviewedAt: last.ReviewedAt, ID: last.ID}
+	}
+	return page, nil
+}
+
+// ListBySession returns the reviews made in a session, oldest first
+func (r *ReviewRepository) ListBySession(ctx context.Context, sessionID string) ([]*domainReview.Review, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews
+		WHERE session_id = $1
+		ORDER BY reviewed_at, id
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*domainReview.Review
+	for rows.Next() {
+		rev, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, rev)
+	}
+	return reviews, rows.Err()
+}
+
+// Latest returns the most recent review of a word, or nil
+func (r *ReviewRepository) Latest(ctx context.Context, wordID string) (*domainReview.Review, error) {
+	rev, err := scanReview(conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews
+		WHERE word_id = $1
+		ORDER BY reviewed_at DESC, id DESC
+		LIMIT 1
+	`, wordID))
 	if err == sql.ErrNoRows {
-		return "", nil
+		return nil, nil
 	}
+	return rev, err
+}
+
+// ListByWord returns every review of a word, oldest first
+func (r *ReviewRepository) ListByWord(ctx context.Context, wordID string) ([]*domainReview.Review, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM reviews
+		WHERE word_id = $1
+		ORDER BY reviewed_at, id
+	`, wordID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	defer rows.Close()
 
-	if reviewType.Valid {
-		return reviewType.String, nil
+	var reviews []*domainReview.Review
+	for rows.Next() {
+		rev, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, rev)
+	}
+	return reviews, rows.Err()
+}
+
+// UpdateOutcome changes the result and score of a review
+func (r *ReviewRepository) UpdateOutcome(ctx context.Context, reviewID string, outcome domainReview.Outcome) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE reviews SET
+			result = $2,
+			score = $3,
+			hesitant = hesitant AND $2
+		WHERE id = $1
+	`, reviewID, outcome.Correct, outcome.Score)
+	if err != nil {
+		return err
 	}
+	return expectRows(res, domain.ErrReviewNotFound)
+}
 
-	return "", nil
+// Delete removes a review
+func (r *ReviewRepository) Delete(ctx context.Context, reviewID string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM reviews WHERE id = $1`, reviewID)
+	if err != nil {
+		return err
+	}
+	return expectRows(res, domain.ErrReviewNotFound)
+}
+
+// SessionAccuracy returns, for each word reviewed in a session, its mean
+// score before its first review in the session and after its last one
+func (r *ReviewRepository) SessionAccuracy(ctx context.Context, sessionID string) (map[string]domainReview.AccuracyChange, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		WITH in_session AS (
+			SELECT word_id, MIN(reviewed_at) AS first_at, MAX(reviewed_at) AS last_at
+			FROM reviews
+			WHERE session_id = $1
+			GROUP BY word_id
+		)
+		SELECT
+			s.word_id,
+			AVG(r.score) FILTER (WHERE r.reviewed_at < s.first_at),
+			AVG(r.score) FILTER (WHERE r.reviewed_at <= s.last_at)
+		FROM in_session s
+		JOIN reviews r ON r.word_id = s.word_id
+		GROUP BY s.word_id
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make(map[string]domainReview.AccuracyChange)
+	for rows.Next() {
+		var wordID string
+		var before sql.NullFloat64
+		var change domainReview.AccuracyChange
+		if err := rows.Scan(&wordID, &before, &change.After); err != nil {
+			return nil, err
+		}
+		if before.Valid {
+			change.Before = &before.Float64
+		}
+		changes[wordID] = change
+	}
+	return changes, rows.Err()
+}
+
+// millis converts an optional duration to whole milliseconds
+func millis(d *time.Duration) *int64 {
+	if d == nil {
+		return nil
+	}
+	ms := d.Milliseconds()
+	return &ms
 }
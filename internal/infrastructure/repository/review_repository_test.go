@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestListStatsFillsUnreviewedWords(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewReviewRepository(db)
+
+	mock.ExpectQuery(sqlPrefix("SELECT")).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"word_id", "total_reviews", "correct_reviews", "last_reviewed_at",
+			"accuracy_rate", "memory_score", "hesitation_rate",
+		}).AddRow("w1", 4, 3, nil, 0.75, 60.0, 0.5))
+
+	stats, err := repo.ListStats(context.Background(), []string{"w1", "w2"})
+	if err != nil {
+		t.Fatalf("list stats: %v", err)
+	}
+	if got := stats["w1"]; got == nil || got.TotalReviews != 4 || got.HesitationRate != 0.5 {
+		t.Errorf("w1 stats = %+v", got)
+	}
+	if got := stats["w2"]; got == nil || got.WordID != "w2" || got.TotalReviews != 0 {
+		t.Errorf("w2 stats = %+v, want zero statistics", got)
+	}
+}
+
+func TestRecomputeStatsRebuildsAsMergeDoes(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewReviewRepository(db)
+	done := sqlmock.NewResult(0, 1)
+
+	// The same statements as a merge, in a transaction of their own
+	mock.ExpectBegin()
+	mock.ExpectExec(sqlPrefix("DELETE FROM review_stats")).WithArgs("w1").WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("INSERT INTO review_stats")).WithArgs("w1").WillReturnResult(done)
+	mock.ExpectCommit()
+
+	if err := repo.RecomputeStats(context.Background(), "w1"); err != nil {
+		t.Fatalf("recompute: %v", err)
+	}
+}
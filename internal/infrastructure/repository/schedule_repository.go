@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sonsonha/eng-noting/internal/domain/schedule"
+)
+
+// ScheduleRepository implements schedule.Repository using PostgreSQL
+type ScheduleRepository struct {
+	db *sql.DB
+}
+
+// NewScheduleRepository creates a new ScheduleRepository
+func NewScheduleRepository(db *sql.DB) *ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+const scheduleColumns = `
+	s.scheduler,
+	s.stability,
+	s.difficulty,
+	s.repetitions,
+	s.lapses,
+	s.due_at,
+	s.last_reviewed_at
+`
+
+// Get returns the state of a word, the zero State if it was never scheduled
+func (r *ScheduleRepository) Get(ctx context.Context, wordID string) (schedule.State, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM word_schedules s
+		WHERE s.word_id = $1
+	`, wordID)
+
+	state, err := scanSchedule(row)
+	if err == sql.ErrNoRows {
+		return schedule.State{}, nil
+	}
+	return state, err
+}
+
+// List returns the states of all scheduled words of a user
+func (r *ScheduleRepository) List(ctx context.Context, userID string) (map[string]schedule.State, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT s.word_id, `+scheduleColumns+`
+		FROM word_schedules s
+		JOIN words w ON w.id = s.word_id
+		WHERE w.user_id = $1 AND w.archived_at IS NULL
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[string]schedule.State)
+	for rows.Next() {
+		var wordID string
+		state, err := scanSchedule(rows, &wordID)
+		if err != nil {
+			return nil, err
+		}
+		states[wordID] = state
+	}
+
+	return states, rows.Err()
+}
+
+// Save stores the state of a word and mirrors its stability into
+// review_stats.memory_score
+func (r *ScheduleRepository) Save(ctx context.Context, wordID string, s schedule.State) error {
+	return withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO word_schedules (
+				word_id, scheduler, stability, difficulty, repetitions, lapses,
+				due_at, last_reviewed_at, updated_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now())
+			ON CONFLICT (word_id) DO UPDATE SET
+				scheduler = EXCLUDED.scheduler,
+				stability = EXCLUDED.stability,
+				difficulty = EXCLUDED.difficulty,
+				repetitions = EXCLUDED.repetitions,
+				lapses = EXCLUDED.lapses,
+				due_at = EXCLUDED.due_at,
+				last_reviewed_at = EXCLUDED.last_reviewed_at,
+				updated_at = now()
+		`, wordID, s.Algorithm, s.Stability, s.Difficulty, s.Repetitions, s.Lapses, s.DueAt, s.LastReviewedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE review_stats SET memory_score = $2 WHERE word_id = $1
+		`, wordID, s.Stability)
+		return err
+	})
+}
+
+// Delete forgets the state of a word and clears its
+// review_stats.memory_score
+func (r *ScheduleRepository) Delete(ctx context.Context, wordID string) error {
+	return withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
+		_, err := tx.ExecContext(ctx, `DELETE FROM word_schedules WHERE word_id = $1`, wordID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE review_stats SET memory_score = 0 WHERE word_id = $1
+		`, wordID)
+		return err
+	})
+}
+
+// scanSchedule scans scheduleColumns, after any leading columns into lead
+func scanSchedule(row rowScanner, lead ...any) (schedule.State, error) {
+	var state schedule.State
+	err := row.Scan(append(lead,
+		&state.Algorithm,
+		&state.Stability,
+		&state.Difficulty,
+		&state.Repetitions,
+		&state.Lapses,
+		&state.DueAt,
+		&state.LastReviewedAt,
+	)...)
+	return state, err
+}
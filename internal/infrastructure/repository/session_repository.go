@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+)
+
+// SessionRepository implements session.SessionRepository using PostgreSQL
+type SessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// itemExercise is the JSON stored in session_items.exercise
+type itemExercise struct {
+	MCQ   *exercise.MCQ        `json:"mcq,omitempty"`
+	Cloze *exercise.Cloze      `json:"cloze,omitempty"`
+	Match *exercise.MatchBoard `json:"match,omitempty"`
+}
+
+// Create stores a session with its items
+func (r *SessionRepository) Create(ctx context.Context, s *session.Session, ttl time.Duration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var tagID *string
+	if s.TagID != "" {
+		tagID = &s.TagID
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO sessions (id, user_id, tag_id, position, item_count, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, now(), now() + make_interval(secs => $6))
+		RETURNING created_at, expires_at
+	`, s.ID, s.UserID, tagID, s.Index, len(s.Items), ttl.Seconds()).Scan(&s.CreatedAt, &s.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range s.Items {
+		// A nil []byte would be sent as an empty string rather than NULL
+		var content any
+		if item.MCQ != nil || item.Cloze != nil || item.Match != nil {
+			encoded, err := json.Marshal(itemExercise{MCQ: item.MCQ, Cloze: item.Cloze, Match: item.Match})
+			if err != nil {
+				return err
+			}
+			content = encoded
+		}
+
+		var wordID *string
+		if item.WordID != "" {
+			wordID = &item.WordID
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO session_items (
+				session_id, position, word_id, review_type, priority_score, reason, exercise
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, s.ID, i, wordID, item.ReviewType, item.PriorityScore, item.Reason, content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get returns a session that has not expired, with its items in order
+func (r *SessionRepository) Get(ctx context.Context, sessionID string) (*session.Session, error) {
+	s := session.Session{ID: sessionID}
+	var tagID sql.NullString
+	var abandonedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, tag_id, position, created_at, expires_at, abandoned_at
+		FROM sessions
+		WHERE id = $1 AND expires_at > now()
+	`, sessionID).Scan(&s.UserID, &tagID, &s.Index, &s.CreatedAt, &s.ExpiresAt, &abandonedAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.TagID = tagID.String
+	if abandonedAt.Valid {
+		s.AbandonedAt = &abandonedAt.Time
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT word_id, review_type, priority_score, reason, exercise
+		FROM session_items
+		WHERE session_id = $1
+		ORDER BY position
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item session.SessionItem
+		var wordID sql.NullString
+		var content []byte
+		if err := rows.Scan(&wordID, &item.ReviewType, &item.PriorityScore, &item.Reason, &content); err != nil {
+			return nil, err
+		}
+		item.WordID = wordID.String
+
+		if content != nil {
+			var ex itemExercise
+			if err := json.Unmarshal(content, &ex); err != nil {
+				return nil, err
+			}
+			item.MCQ, item.Cloze, item.Match = ex.MCQ, ex.Cloze, ex.Match
+		}
+		s.Items = append(s.Items, item)
+	}
+
+	return &s, rows.Err()
+}
+
+// Advance moves a session from item from to the next
+func (r *SessionRepository) Advance(ctx context.Context, sessionID string, from int, ttl time.Duration) (int, error) {
+	var index int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE sessions SET
+			position = position + 1,
+			expires_at = now() + make_interval(secs => $3)
+		WHERE id = $1 AND expires_at > now() AND position = $2 AND position < item_count
+		RETURNING position
+	`, sessionID, from, ttl.Seconds()).Scan(&index)
+	if err != sql.ErrNoRows {
+		return index, err
+	}
+	return 0, r.missedMove(ctx, sessionID)
+}
+
+// Rewind moves a session from item from back to the one before
+func (r *SessionRepository) Rewind(ctx context.Context, sessionID string, from int, ttl time.Duration) (int, error) {
+	var index int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE sessions SET
+			position = position - 1,
+			expires_at = now() + make_interval(secs => $3)
+		WHERE id = $1 AND expires_at > now() AND position = $2 AND position > 0
+		RETURNING position
+	`, sessionID, from, ttl.Seconds()).Scan(&index)
+	if err != sql.ErrNoRows {
+		return index, err
+	}
+	return 0, r.missedMove(ctx, sessionID)
+}
+
+// missedMove tells why a session could not be moved: it is gone, or it
+// is not where the caller thought
+func (r *SessionRepository) missedMove(ctx context.Context, sessionID string) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND expires_at > now())
+	`, sessionID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrSessionMoved
+	}
+	return domain.ErrSessionNotFound
+}
+
+// Abandon marks a session abandoned, keeping the time it first was and
+// the expiry it was given then
+func (r *SessionRepository) Abandon(ctx context.Context, sessionID string, ttl time.Duration) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE sessions SET
+			abandoned_at = COALESCE(abandoned_at, now()),
+			expires_at = CASE
+				WHEN abandoned_at IS NULL THEN now() + make_interval(secs => $2)
+				ELSE expires_at
+			END
+		WHERE id = $1 AND expires_at > now()
+	`, sessionID, ttl.Seconds())
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+// sessionStatusWhere is the condition selecting sessions with a status
+var sessionStatusWhere = map[session.Status]string{
+	session.StatusActive:    "abandoned_at IS NULL AND position < item_count",
+	session.StatusCompleted: "abandoned_at IS NULL AND position >= item_count",
+	session.StatusAbandoned: "abandoned_at IS NOT NULL",
+}
+
+// List returns a page of a user's sessions that have not expired, newest first
+func (r *SessionRepository) List(ctx context.Context, filter session.ListFilter) (*session.Page, error) {
+	var args queryArgs
+	where := "user_id = " + args.add(filter.UserID) + " AND expires_at > now()"
+	if filter.Status != "" {
+		where += " AND " + sessionStatusWhere[filter.Status]
+	}
+	if filter.After != nil {
+		where += " AND (created_at, id) < (" + args.add(filter.After.CreatedAt) + ", " + args.add(filter.After.ID) + ")"
+	}
+
+	// One extra row is fetched to learn whether another page follows
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, tag_id, position, item_count, created_at, expires_at, abandoned_at
+		FROM sessions
+		WHERE `+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT `+args.add(filter.Limit+1), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []session.Summary
+	for rows.Next() {
+		var s session.Summary
+		var tagID sql.NullString
+		var abandonedAt sql.NullTime
+		if err := rows.Scan(&s.ID, &tagID, &s.Index, &s.Total, &s.CreatedAt, &s.ExpiresAt, &abandonedAt); err != nil {
+			return nil, err
+		}
+		s.TagID = tagID.String
+		if abandonedAt.Valid {
+			s.AbandonedAt = &abandonedAt.Time
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &session.Page{Sessions: sessions}
+	if len(sessions) > filter.Limit {
+		page.Sessions = sessions[:filter.Limit]
+		last := page.Sessions[len(page.Sessions)-1]
+		page.Next = &session.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	return page, nil
+}
+
+// DeleteExpired removes expired sessions; their items go with them
+func (r *SessionRepository) DeleteExpired(ctx context.Context) (int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+)
+
+// sessionColumns are the columns Get reads from sessions
+var sessionColumns = []string{"user_id", "tag_id", "position", "created_at", "expires_at", "abandoned_at"}
+
+func TestSessionCreateStoresItems(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewSessionRepository(db)
+	now := time.Now()
+	done := sqlmock.NewResult(0, 1)
+
+	s := testSession(1)
+	s.TagID = "t1"
+	s.Items = append(s.Items, session.SessionItem{
+		WordID:     "w2",
+		ReviewType: "mcq",
+		MCQ:        &exercise.MCQ{Prompt: "p", PromptKind: exercise.PromptDefinition, Options: []string{"a", "b"}},
+	})
+
+	content, err := json.Marshal(itemExercise{MCQ: s.Items[1].MCQ})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(sqlPrefix("INSERT INTO sessions")).
+		WithArgs("s1", "u1", "t1", 0, 2, float64(3600)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "expires_at"}).AddRow(now, now.Add(time.Hour)))
+	// Items without an exercise store no JSON
+	mock.ExpectExec(sqlPrefix("INSERT INTO session_items")).
+		WithArgs("s1", 0, "w", "recognition", 50.0, "due", nil).
+		WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("INSERT INTO session_items")).
+		WithArgs("s1", 1, "w2", "mcq", 0.0, "", content).
+		WillReturnResult(done)
+	mock.ExpectCommit()
+
+	if err := repo.Create(context.Background(), s, time.Hour); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !s.CreatedAt.Equal(now) || !s.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected the stored times, got %v and %v", s.CreatedAt, s.ExpiresAt)
+	}
+}
+
+func TestSessionGetRestoresItems(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewSessionRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $1 AND expires_at > now()")).
+		WithArgs("s1").
+		WillReturnRows(sqlmock.NewRows(sessionColumns).AddRow("u1", nil, 1, now, now.Add(time.Hour), nil))
+	mock.ExpectQuery(sqlPrefix("SELECT word_id, review_type")).
+		WithArgs("s1").
+		WillReturnRows(sqlmock.NewRows([]string{"word_id", "review_type", "priority_score", "reason", "exercise"}).
+			AddRow("w1", "recognition", 50.0, "due", nil).
+			AddRow(nil, "match", 0.0, "", []byte(`{"match":{"words":[{"id":"w3","text":"t"}],"definitions":[{"id":"d1","text":"d"}],"key":{"w3":"d1"}}}`)))
+
+	got, err := repo.Get(context.Background(), "s1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.UserID != "u1" || got.TagID != "" || got.Index != 1 || got.AbandonedAt != nil || len(got.Items) != 2 {
+		t.Fatalf("unexpected session %+v", got)
+	}
+	if got.Items[0].WordID != "w1" || got.Items[0].Match != nil {
+		t.Errorf("unexpected first item %+v", got.Items[0])
+	}
+	if got.Items[1].WordID != "" || got.Items[1].Match == nil || got.Items[1].Match.Key["w3"] != "d1" {
+		t.Errorf("board not restored: %+v", got.Items[1])
+	}
+}
+
+func TestSessionGetExpired(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewSessionRepository(db)
+
+	// Expired sessions are filtered out by the query
+	mock.ExpectQuery(regexp.QuoteMeta("expires_at > now()")).
+		WithArgs("s1").
+		WillReturnError(sql.ErrNoRows)
+
+	if _, err := repo.Get(context.Background(), "s1"); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestSessionAdvance(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewSessionRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("position = $2 AND position < item_count")).
+		WithArgs("s1", 0, float64(60)).
+		WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(1))
+
+	got, err := repo.Advance(context.Background(), "s1", 0, time.Minute)
+	if err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if got != 1 {
+		t.Errorf("expected position 1, got %d", got)
+	}
+}
+
+func TestSessionAdvanceMissed(t *testing.T) {
+	tests := []struct {
+		name   string
+		exists bool
+		want   error
+	}{
+		// At the last item, or from an item the session already left
+		{"past the end", true, domain.ErrSessionMoved},
+		{"expired", false, domain.ErrSessionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newTestDB(t)
+			repo := NewSessionRepository(db)
+
+			mock.ExpectQuery(sqlPrefix("UPDATE sessions SET")).
+				WithArgs("s1", 2, float64(3600)).
+				WillReturnError(sql.ErrNoRows)
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND expires_at > now())")).
+				WithArgs("s1").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.exists))
+
+			if _, err := repo.Advance(context.Background(), "s1", 2, time.Hour); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSessionAbandonKeepsFirstExpiry(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewSessionRepository(db)
+
+	// Only the first abandon sets the expiry
+	mock.ExpectExec(regexp.QuoteMeta("WHEN abandoned_at IS NULL THEN now() + make_interval(secs => $2)")).
+		WithArgs("s1", float64(86400)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.Abandon(context.Background(), "s1", 24*time.Hour); err != nil {
+		t.Fatalf("abandon: %v", err)
+	}
+}
+
+func TestSessionDeleteExpired(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewSessionRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM sessions WHERE expires_at <= now()")).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := repo.DeleteExpired(context.Background())
+	if err != nil {
+		t.Fatalf("delete expired: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 sessions deleted, got %d", n)
+	}
+}
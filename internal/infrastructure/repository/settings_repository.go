@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+	"github.com/sonsonha/eng-noting/internal/domain/settings"
+)
+
+// SettingsRepository implements settings.SettingsRepository using PostgreSQL
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+// NewSettingsRepository creates a new SettingsRepository
+func NewSettingsRepository(db *sql.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// sessionPlan is the JSON stored in user_settings.session_plan
+type sessionPlan struct {
+	Size          int      `json:"size"`
+	Minutes       int      `json:"minutes,omitempty"`
+	NewRatio      *float64 `json:"new_ratio,omitempty"`
+	ReviewTypes   []string `json:"review_types,omitempty"`
+	TagIDs        []string `json:"tag_ids,omitempty"`
+	CEFRLevels    []string `json:"cefr_levels,omitempty"`
+	PartsOfSpeech []string `json:"parts_of_speech,omitempty"`
+}
+
+// Get returns the settings of a user, the defaults if none were saved
+func (r *SettingsRepository) Get(ctx context.Context, userID string) (*settings.Settings, error) {
+	s := settings.Default(userID)
+	var plan []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT scheduler, session_plan, updated_at
+		FROM user_settings
+		WHERE user_id = $1
+	`, userID).Scan(&s.Scheduler, &plan, &s.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if plan != nil {
+		var p sessionPlan
+		if err := json.Unmarshal(plan, &p); err != nil {
+			return nil, err
+		}
+		s.Session = session.Plan{
+			Size:        p.Size,
+			Minutes:     p.Minutes,
+			NewRatio:    p.NewRatio,
+			ReviewTypes: p.ReviewTypes,
+			Filter: session.QueueFilter{
+				TagIDs:        p.TagIDs,
+				CEFRLevels:    p.CEFRLevels,
+				PartsOfSpeech: p.PartsOfSpeech,
+			},
+		}
+	}
+	return s, nil
+}
+
+// Save stores the settings of a user
+func (r *SettingsRepository) Save(ctx context.Context, s *settings.Settings) error {
+	plan, err := json.Marshal(sessionPlan{
+		Size:          s.Session.Size,
+		Minutes:       s.Session.Minutes,
+		NewRatio:      s.Session.NewRatio,
+		ReviewTypes:   s.Session.ReviewTypes,
+		TagIDs:        s.Session.Filter.TagIDs,
+		CEFRLevels:    s.Session.Filter.CEFRLevels,
+		PartsOfSpeech: s.Session.Filter.PartsOfSpeech,
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO user_settings (user_id, scheduler, session_plan, updated_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (user_id) DO UPDATE SET
+			scheduler = EXCLUDED.scheduler,
+			session_plan = EXCLUDED.session_plan,
+			updated_at = now()
+		RETURNING updated_at
+	`, s.UserID, s.Scheduler, plan).Scan(&s.UpdatedAt)
+}
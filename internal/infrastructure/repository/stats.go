@@ -0,0 +1,38 @@
+package repository
+
+import "context"
+
+// recomputeReviewStats rebuilds review_stats for a single word from its
+// reviews, as RebuildStats does for every word: a word left without reviews
+// has no row, and memory_score is the stability of the word's schedule. Its
+// two statements belong in one transaction.
+func recomputeReviewStats(ctx context.Context, db querier, wordID string) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM review_stats WHERE word_id = $1`, wordID)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO review_stats (
+			word_id,
+			total_reviews,
+			correct_reviews,
+			score_sum,
+			last_reviewed_at,
+			accuracy_rate,
+			memory_score
+		)
+		SELECT
+			r.word_id,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE r.result = true),
+			SUM(r.score),
+			MAX(r.reviewed_at),
+			SUM(r.score) / COUNT(*),
+			COALESCE((SELECT s.stability FROM word_schedules s WHERE s.word_id = r.word_id), 0)
+		FROM reviews r
+		WHERE r.word_id = $1
+		GROUP BY r.word_id
+	`, wordID)
+	return err
+}
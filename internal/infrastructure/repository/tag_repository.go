@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/tag"
+)
+
+// TagRepository implements tag.TagRepository using PostgreSQL
+type TagRepository struct {
+	db *sql.DB
+}
+
+// NewTagRepository creates a new TagRepository
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// Create creates a new tag
+func (r *TagRepository) Create(ctx context.Context, t *tag.Tag) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO tags (id, user_id, name, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, t.ID, t.UserID, t.Name, t.CreatedAt)
+	return mapTagError(err)
+}
+
+// GetByID retrieves a tag by ID
+func (r *TagRepository) GetByID(ctx context.Context, tagID, userID string) (*tag.Tag, error) {
+	var t tag.Tag
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			t.id,
+			t.user_id,
+			t.name,
+			t.created_at,
+			COUNT(w.id) AS word_count
+		FROM tags t
+		LEFT JOIN word_tags wt ON wt.tag_id = t.id
+		LEFT JOIN words w ON w.id = wt.word_id AND w.archived_at IS NULL
+		WHERE t.id = $1 AND t.user_id = $2
+		GROUP BY t.id
+	`, tagID, userID).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.Name,
+		&t.CreatedAt,
+		&t.WordCount,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrTagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// List retrieves all tags of a user with their active word counts
+func (r *TagRepository) List(ctx context.Context, userID string) ([]*tag.Tag, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			t.id,
+			t.user_id,
+			t.name,
+			t.created_at,
+			COUNT(w.id) AS word_count
+		FROM tags t
+		LEFT JOIN word_tags wt ON wt.tag_id = t.id
+		LEFT JOIN words w ON w.id = wt.word_id AND w.archived_at IS NULL
+		WHERE t.user_id = $1
+		GROUP BY t.id
+		ORDER BY lower(t.name)
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*tag.Tag
+	for rows.Next() {
+		var t tag.Tag
+		if err := rows.Scan(
+			&t.ID,
+			&t.UserID,
+			&t.Name,
+			&t.CreatedAt,
+			&t.WordCount,
+		); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &t)
+	}
+
+	return tags, rows.Err()
+}
+
+// Rename changes the name of a tag
+func (r *TagRepository) Rename(ctx context.Context, tagID, userID, name string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE tags SET name = $3 WHERE id = $1 AND user_id = $2
+	`, tagID, userID, name)
+	if err != nil {
+		return mapTagError(err)
+	}
+	return expectRows(res, domain.ErrTagNotFound)
+}
+
+// Delete removes a tag; words keep existing, only the grouping goes away
+func (r *TagRepository) Delete(ctx context.Context, tagID, userID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM tags WHERE id = $1 AND user_id = $2
+	`, tagID, userID)
+	if err != nil {
+		return err
+	}
+	return expectRows(res, domain.ErrTagNotFound)
+}
+
+// AddWord tags a word. Tagging an already tagged word is a no-op.
+func (r *TagRepository) AddWord(ctx context.Context, tagID, wordID, userID string) error {
+	var matched int
+	err := r.db.QueryRowContext(ctx, `
+		WITH target AS (
+			SELECT w.id AS word_id, t.id AS tag_id
+			FROM words w
+			JOIN tags t ON t.user_id = w.user_id
+			WHERE w.id = $1 AND t.id = $2 AND w.user_id = $3
+		), inserted AS (
+			INSERT INTO word_tags (word_id, tag_id)
+			SELECT word_id, tag_id FROM target
+			ON CONFLICT DO NOTHING
+		)
+		SELECT COUNT(*) FROM target
+	`, wordID, tagID, userID).Scan(&matched)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		return domain.ErrTagNotFound
+	}
+	return nil
+}
+
+// RemoveWord untags a word
+func (r *TagRepository) RemoveWord(ctx context.Context, tagID, wordID, userID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM word_tags wt
+		USING tags t
+		WHERE wt.tag_id = t.id AND wt.word_id = $1 AND t.id = $2 AND t.user_id = $3
+	`, wordID, tagID, userID)
+	if err != nil {
+		return err
+	}
+	return expectRows(res, domain.ErrTagNotFound)
+}
+
+// mapTagError turns a unique violation on (user_id, lower(name)) into ErrTagExists
+func mapTagError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return domain.ErrTagExists
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// txKey is the context key of the transaction a unit of work runs in
+type txKey struct{}
+
+// querier is what *sql.DB and *sql.Tx have in common
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn returns the transaction ctx runs in, or db outside of one
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// withinTx runs fn in the transaction ctx runs in, or in a new one on db
+// that is committed if fn returns nil and rolled back otherwise
+func withinTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// TxManager runs units of work in PostgreSQL transactions
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager creates a new TxManager
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx runs fn in a transaction, committed if fn returns nil and
+// rolled back otherwise. Repositories given the context fn receives run
+// their queries in the transaction. Called within a transaction, it
+// joins it.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTx(ctx, m.db, fn)
+}
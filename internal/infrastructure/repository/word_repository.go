@@ -3,9 +3,14 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
 	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/job"
 	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
 )
 
@@ -19,38 +24,64 @@ func NewWordRepository(db *sql.DB) *WordRepository {
 	return &WordRepository{db: db}
 }
 
-// Create creates a new word
+// Create creates a new word together with its contexts
 func (r *WordRepository) Create(ctx context.Context, word *wordDomain.Word) error {
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO words (id, user_id, text, context, confidence, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`, word.ID, word.UserID, word.Text, word.Context, word.Confidence, word.CreatedAt, word.UpdatedAt)
-	return err
+	return withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO words (id, user_id, text, lemma, lemma_version, source, confidence, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $9, $5, $6, $7, $8)
+		`, word.ID, word.UserID, word.Text, word.Lemma, word.Source, word.Confidence, word.CreatedAt, word.UpdatedAt,
+			wordDomain.LemmaVersion)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range word.Contexts {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO word_contexts (id, word_id, sentence, source_url, source_title, captured_at)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, c.ID, word.ID, c.Sentence, c.SourceURL, c.SourceTitle, c.CapturedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // GetByID retrieves a word by ID
 func (r *WordRepository) GetByID(ctx context.Context, wordID, userID string) (*wordDomain.Word, error) {
 	var word wordDomain.Word
-	var createdAt, updatedAt sql.NullTime
+	var createdAt, updatedAt, archivedAt sql.NullTime
 
 	var aiDefinition, aiExampleGood sql.NullString
-	var aiExampleBad, aiPOS, aiCEFR sql.NullString
+	var aiExampleBad, aiPOS, aiCEFR, aiTranslation sql.NullString
+	var aiGeneratedAt sql.NullTime
+	var aiVersion sql.NullInt64
+	var aiSource sql.NullString
 
-	err := r.db.QueryRowContext(ctx, `
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
 		SELECT
 			w.id,
 			w.user_id,
 			w.text,
-			w.context,
+			w.lemma,
 			w.source,
 			w.confidence,
 			w.created_at,
 			w.updated_at,
+			w.archived_at,
 			ai.definition,
 			ai.example_good,
 			ai.example_bad,
 			ai.pos,
-			ai.cefr_level
+			ai.cefr_level,
+			ai.translation,
+			ai.generated_at,
+			ai.version,
+			ai.source
 		FROM words w
 		LEFT JOIN word_ai_data ai ON ai.word_id = w.id
 		WHERE w.id = $1 AND w.user_id = $2
@@ -58,16 +89,21 @@ func (r *WordRepository) GetByID(ctx context.Context, wordID, userID string) (*w
 		&word.ID,
 		&word.UserID,
 		&word.Text,
-		&word.Context,
+		&word.Lemma,
 		&word.Source,
 		&word.Confidence,
 		&createdAt,
 		&updatedAt,
+		&archivedAt,
 		&aiDefinition,
 		&aiExampleGood,
 		&aiExampleBad,
 		&aiPOS,
 		&aiCEFR,
+		&aiTranslation,
+		&aiGeneratedAt,
+		&aiVersion,
+		&aiSource,
 	)
 
 	if err == sql.ErrNoRows {
@@ -83,6 +119,9 @@ func (r *WordRepository) GetByID(ctx context.Context, wordID, userID string) (*w
 	if updatedAt.Valid {
 		word.UpdatedAt = updatedAt.Time
 	}
+	if archivedAt.Valid {
+		word.ArchivedAt = &archivedAt.Time
+	}
 
 	// Set AI data if available
 	if aiDefinition.Valid {
@@ -90,7 +129,9 @@ func (r *WordRepository) GetByID(ctx context.Context, wordID, userID string) (*w
 			WordID:      wordID,
 			Definition:  aiDefinition.String,
 			ExampleGood: aiExampleGood.String,
-			GeneratedAt: time.Now(), // Could fetch from DB if needed
+			GeneratedAt: aiGeneratedAt.Time,
+			Version:     int(aiVersion.Int64),
+			Source:      wordDomain.AISource(aiSource.String),
 		}
 		if aiExampleBad.Valid {
 			word.AIData.ExampleBad = &aiExampleBad.String
@@ -101,60 +142,97 @@ func (r *WordRepository) GetByID(ctx context.Context, wordID, userID string) (*w
 		if aiCEFR.Valid {
 			word.AIData.CEFRLevel = &aiCEFR.String
 		}
+		if aiTranslation.Valid {
+			word.AIData.Translation = &aiTranslation.String
+		}
+	}
+
+	if err := r.attachDetails(ctx, []*wordDomain.Word{&word}); err != nil {
+		return nil, err
 	}
 
 	return &word, nil
 }
 
-// List retrieves a list of words for a user
-func (r *WordRepository) List(ctx context.Context, userID string, limit, offset int) ([]*wordDomain.Word, error) {
-	rows, err := r.db.QueryContext(ctx, `
+// wordListFrom joins everything List and Count can filter or sort on
+const wordListFrom = `
+		FROM words w
+		LEFT JOIN word_ai_data ai ON ai.word_id = w.id
+		LEFT JOIN review_stats rs ON rs.word_id = w.id
+		LEFT JOIN review_queue rq ON rq.word_id = w.id AND rq.user_id = w.user_id
+`
+
+// List retrieves a page of words matching the filter
+func (r *WordRepository) List(ctx context.Context, filter wordDomain.ListFilter) (*wordDomain.Page, error) {
+	var args queryArgs
+	where := wordFilterWhere(filter, &args)
+	if filter.After != nil {
+		where += " AND " + wordAfterCond(filter, &args)
+	}
+	sortKey, _ := wordSortKey(filter.Sort)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
 		SELECT
 			w.id,
 			w.user_id,
 			w.text,
-			w.context,
+			w.lemma,
 			w.source,
 			w.confidence,
 			w.created_at,
 			w.updated_at,
+			w.archived_at,
 			ai.definition,
 			ai.example_good,
 			ai.example_bad,
 			ai.pos,
-			ai.cefr_level
-		FROM words w
-		LEFT JOIN word_ai_data ai ON ai.word_id = w.id
-		WHERE w.user_id = $1
-		ORDER BY w.created_at DESC
-		LIMIT $2 OFFSET $3
-	`, userID, limit, offset)
+			ai.cefr_level,
+			ai.translation,
+			ai.generated_at,
+			ai.version,
+			ai.source,
+			(`+sortKey+`)::text AS sort_key
+		`+wordListFrom+`
+		WHERE `+where+`
+		ORDER BY `+wordOrderBy(filter)+`
+		LIMIT `+args.add(filter.Limit+1), args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var words []*wordDomain.Word
+	var keys []string
 	for rows.Next() {
 		var word wordDomain.Word
-		var createdAt, updatedAt sql.NullTime
+		var sortKeyValue string
+		var createdAt, updatedAt, archivedAt sql.NullTime
 		var aiDefinition, aiExampleGood sql.NullString
-		var aiExampleBad, aiPOS, aiCEFR sql.NullString
+		var aiExampleBad, aiPOS, aiCEFR, aiTranslation sql.NullString
+		var aiGeneratedAt sql.NullTime
+		var aiVersion sql.NullInt64
+		var aiSource sql.NullString
 
 		err := rows.Scan(
 			&word.ID,
 			&word.UserID,
 			&word.Text,
-			&word.Context,
+			&word.Lemma,
 			&word.Source,
 			&word.Confidence,
 			&createdAt,
 			&updatedAt,
+			&archivedAt,
 			&aiDefinition,
 			&aiExampleGood,
 			&aiExampleBad,
 			&aiPOS,
 			&aiCEFR,
+			&aiTranslation,
+			&aiGeneratedAt,
+			&aiVersion,
+			&aiSource,
+			&sortKeyValue,
 		)
 		if err != nil {
 			continue
@@ -166,13 +244,18 @@ func (r *WordRepository) List(ctx context.Context, userID string, limit, offset
 		if updatedAt.Valid {
 			word.UpdatedAt = updatedAt.Time
 		}
+		if archivedAt.Valid {
+			word.ArchivedAt = &archivedAt.Time
+		}
 
 		if aiDefinition.Valid {
 			aiData := &wordDomain.WordAIData{
 				WordID:      word.ID,
 				Definition:  aiDefinition.String,
 				ExampleGood: aiExampleGood.String,
-				GeneratedAt: time.Now(),
+				GeneratedAt: aiGeneratedAt.Time,
+				Version:     int(aiVersion.Int64),
+				Source:      wordDomain.AISource(aiSource.String),
 			}
 			if aiExampleBad.Valid {
 				aiData.ExampleBad = &aiExampleBad.String
@@ -183,27 +266,279 @@ func (r *WordRepository) List(ctx context.Context, userID string, limit, offset
 			if aiCEFR.Valid {
 				aiData.CEFRLevel = &aiCEFR.String
 			}
+			if aiTranslation.Valid {
+				aiData.Translation = &aiTranslation.String
+			}
 			word.AIData = aiData
 		}
 
 		words = append(words, &word)
+		keys = append(keys, sortKeyValue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// One extra row was fetched to learn whether another page follows
+	page := &wordDomain.Page{Words: words}
+	if len(words) > filter.Limit {
+		page.Words = words[:filter.Limit]
+		last := len(page.Words) - 1
+		page.Next = &wordDomain.Cursor{
+			Sort:       filter.Sort,
+			Descending: filter.Descending,
+			ID:         page.Words[last].ID,
+		}
+		// A timestamp's text depends on the server's DateStyle, so it is
+		// carried as a time instead
+		if filter.Sort == wordDomain.SortCreatedAt {
+			page.Next.CreatedAt = page.Words[last].CreatedAt
+		} else {
+			page.Next.Key = keys[last]
+		}
 	}
 
-	return words, nil
+	if err := r.attachDetails(ctx, page.Words); err != nil {
+		return nil, err
+	}
+
+	return page, nil
 }
 
-// Count returns the total count of words for a user
-func (r *WordRepository) Count(ctx context.Context, userID string) (int, error) {
+// Count returns the total count of words matching the filter
+func (r *WordRepository) Count(ctx context.Context, filter wordDomain.ListFilter) (int, error) {
+	var args queryArgs
+	where := wordFilterWhere(filter, &args)
+
 	var total int
-	err := r.db.QueryRowContext(ctx, `
-		SELECT COUNT(*) FROM words WHERE user_id = $1
-	`, userID).Scan(&total)
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) `+wordListFrom+` WHERE `+where, args...).Scan(&total)
 	return total, err
 }
 
-// StoreAIData stores AI-generated data for a word
-func (r *WordRepository) StoreAIData(ctx context.Context, wordID string, aiData *wordDomain.WordAIData) error {
-	_, err := r.db.ExecContext(ctx, `
+// wordFilterWhere builds the WHERE clause shared by List and Count
+func wordFilterWhere(filter wordDomain.ListFilter, args *queryArgs) string {
+	conds := []string{"w.user_id = " + args.add(filter.UserID)}
+
+	if filter.Archived {
+		conds = append(conds, "w.archived_at IS NOT NULL")
+	} else {
+		conds = append(conds, "w.archived_at IS NULL")
+	}
+
+	if filter.TagID != "" {
+		conds = append(conds, `EXISTS (
+			SELECT 1 FROM word_tags wt
+			WHERE wt.word_id = w.id AND wt.tag_id = `+args.add(filter.TagID)+`
+		)`)
+	}
+
+	if filter.Query != "" {
+		conds = append(conds, wordSearchCond(filter, args))
+	}
+
+	if len(filter.CEFRLevels) > 0 {
+		conds = append(conds, "lower(ai.cefr_level) = ANY("+args.add(pq.Array(lowered(filter.CEFRLevels)))+")")
+	}
+	if filter.PartOfSpeech != "" {
+		conds = append(conds, "lower(ai.pos) = lower("+args.add(filter.PartOfSpeech)+")")
+	}
+	if filter.MinConfidence != nil {
+		conds = append(conds, "w.confidence >= "+args.add(*filter.MinConfidence))
+	}
+	if filter.MaxConfidence != nil {
+		conds = append(conds, "w.confidence <= "+args.add(*filter.MaxConfidence))
+	}
+	if filter.HasAIData != nil {
+		if *filter.HasAIData {
+			conds = append(conds, "ai.word_id IS NOT NULL")
+		} else {
+			conds = append(conds, "ai.word_id IS NULL")
+		}
+	}
+	if filter.CreatedFrom != nil {
+		conds = append(conds, "w.created_at >= "+args.add(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conds = append(conds, "w.created_at < "+args.add(*filter.CreatedTo))
+	}
+	if filter.MinAccuracy != nil {
+		conds = append(conds, "rs.accuracy_rate >= "+args.add(*filter.MinAccuracy))
+	}
+	if filter.MaxAccuracy != nil {
+		conds = append(conds, "rs.accuracy_rate <= "+args.add(*filter.MaxAccuracy))
+	}
+
+	return strings.Join(conds, " AND ")
+}
+
+// wordSearchCond matches filter.Query against text, contexts and definition
+func wordSearchCond(filter wordDomain.ListFilter, args *queryArgs) string {
+	switch filter.Match {
+	case wordDomain.MatchPrefix:
+		return "w.text ILIKE " + args.add(escapeLike(filter.Query)+"%")
+
+	case wordDomain.MatchFullText:
+		q := "plainto_tsquery('english', " + args.add(filter.Query) + ")"
+		return `(
+			to_tsvector('english', w.text) @@ ` + q + `
+			OR to_tsvector('english', COALESCE(ai.definition, '')) @@ ` + q + `
+			OR EXISTS (
+				SELECT 1 FROM word_contexts c
+				WHERE c.word_id = w.id AND to_tsvector('english', c.sentence) @@ ` + q + `
+			)
+		)`
+
+	default:
+		pattern := args.add("%" + escapeLike(filter.Query) + "%")
+		return `(
+			w.text ILIKE ` + pattern + `
+			OR ai.definition ILIKE ` + pattern + `
+			OR EXISTS (
+				SELECT 1 FROM word_contexts c
+				WHERE c.word_id = w.id AND c.sentence ILIKE ` + pattern + `
+			)
+		)`
+	}
+}
+
+// wordSortKey returns the SQL expression a sort orders by and the type its
+// text rendering is cast back to when it is compared against a cursor
+func wordSortKey(sort wordDomain.SortField) (expr, sqlType string) {
+	switch sort {
+	case wordDomain.SortPriority:
+		return "COALESCE(rq.priority_score, 0)", "float8"
+	case wordDomain.SortAccuracy:
+		return "COALESCE(rs.accuracy_rate, 0)", "float8"
+	case wordDomain.SortAlphabetical:
+		return "lower(w.text)", "text"
+	default:
+		return "w.created_at", "timestamp"
+	}
+}
+
+// wordOrderBy returns the ORDER BY clause for the filter's sort.
+// w.id breaks ties so pages are stable.
+func wordOrderBy(filter wordDomain.ListFilter) string {
+	dir := "ASC"
+	if filter.Descending {
+		dir = "DESC"
+	}
+
+	expr, _ := wordSortKey(filter.Sort)
+	return expr + " " + dir + ", w.id " + dir
+}
+
+// wordAfterCond keeps the rows that come after filter.After in the
+// filter's order. The row comparison matches wordOrderBy, so rows inserted
+// or deleted between requests never shift the following pages.
+func wordAfterCond(filter wordDomain.ListFilter, args *queryArgs) string {
+	op := ">"
+	if filter.Descending {
+		op = "<"
+	}
+
+	expr, sqlType := wordSortKey(filter.Sort)
+	var value any = filter.After.Key
+	if filter.Sort == wordDomain.SortCreatedAt {
+		value = filter.After.CreatedAt
+	}
+	key := args.add(value) + "::" + sqlType
+	id := args.add(filter.After.ID) + "::uuid"
+	return "(" + expr + ", w.id) " + op + " (" + key + ", " + id + ")"
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// StoreAIData records a new version of a word's explanation and makes it
+// the current one, unless the current one is no longer baseVersion.
+// aiData.Version is set to the new version number.
+func (r *WordRepository) StoreAIData(ctx context.Context, wordID string, baseVersion int, aiData *wordDomain.WordAIData) error {
+	return withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
+
+		// Lock the word so concurrent writers get consecutive version
+		// numbers and see each other's changes
+		var current int
+		err := tx.QueryRowContext(ctx, `
+			SELECT COALESCE(ai.version, 0)
+			FROM words w
+			LEFT JOIN word_ai_data ai ON ai.word_id = w.id
+			WHERE w.id = $1
+			FOR UPDATE OF w
+		`, wordID).Scan(&current)
+		if err == sql.ErrNoRows {
+			return domain.ErrWordNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if current != baseVersion {
+			return domain.ErrAIDataChanged
+		}
+
+		return storeAIVersion(ctx, tx, wordID, aiData)
+	})
+}
+
+// storeAIVersion appends aiData to the word's version history and makes it
+// current. The caller must hold a lock on the word in tx.
+func storeAIVersion(ctx context.Context, tx querier, wordID string, aiData *wordDomain.WordAIData) error {
+	err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(version), 0) + 1 FROM word_ai_data_versions WHERE word_id = $1
+	`, wordID).Scan(&aiData.Version)
+	if err != nil {
+		return err
+	}
+
+	aiData.WordID = wordID
+	if aiData.GeneratedAt.IsZero() {
+		aiData.GeneratedAt = time.Now()
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO word_ai_data_versions (
+			id,
+			word_id,
+			version,
+			definition,
+			example_good,
+			example_bad,
+			pos,
+			cefr_level,
+			translation,
+			source,
+			prompt_version,
+			model,
+			hint,
+			restored_from,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`,
+		uuid.NewString(),
+		wordID,
+		aiData.Version,
+		aiData.Definition,
+		aiData.ExampleGood,
+		aiData.ExampleBad,
+		aiData.PartOfSpeech,
+		aiData.CEFRLevel,
+		aiData.Translation,
+		aiData.Source,
+		aiData.PromptVersion,
+		aiData.Model,
+		aiData.Hint,
+		aiData.RestoredFrom,
+		aiData.GeneratedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
 		INSERT INTO word_ai_data (
 			word_id,
 			definition,
@@ -211,10 +546,22 @@ func (r *WordRepository) StoreAIData(ctx context.Context, wordID string, aiData
 			example_bad,
 			pos,
 			cefr_level,
-			generated_at
+			translation,
+			generated_at,
+			version,
+			source
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		ON CONFLICT (word_id) DO NOTHING
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (word_id) DO UPDATE SET
+			definition = EXCLUDED.definition,
+			example_good = EXCLUDED.example_good,
+			example_bad = EXCLUDED.example_bad,
+			pos = EXCLUDED.pos,
+			cefr_level = EXCLUDED.cefr_level,
+			translation = EXCLUDED.translation,
+			generated_at = EXCLUDED.generated_at,
+			version = EXCLUDED.version,
+			source = EXCLUDED.source
 	`,
 		wordID,
 		aiData.Definition,
@@ -222,7 +569,604 @@ func (r *WordRepository) StoreAIData(ctx context.Context, wordID string, aiData
 		aiData.ExampleBad,
 		aiData.PartOfSpeech,
 		aiData.CEFRLevel,
-		time.Now(),
+		aiData.Translation,
+		aiData.GeneratedAt,
+		aiData.Version,
+		aiData.Source,
 	)
 	return err
 }
+
+// ListAIVersions returns the explanation history of a user's word, newest first
+func (r *WordRepository) ListAIVersions(ctx context.Context, wordID, userID string) ([]*wordDomain.WordAIData, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT
+			v.word_id,
+			v.version,
+			v.definition,
+			v.example_good,
+			v.example_bad,
+			v.pos,
+			v.cefr_level,
+			v.translation,
+			v.source,
+			v.prompt_version,
+			v.model,
+			v.hint,
+			v.restored_from,
+			v.created_at
+		FROM word_ai_data_versions v
+		JOIN words w ON w.id = v.word_id
+		WHERE v.word_id = $1 AND w.user_id = $2
+		ORDER BY v.version DESC
+	`, wordID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*wordDomain.WordAIData
+	for rows.Next() {
+		v, err := scanAIVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// RestoreAIVersion makes an earlier explanation current again. The history
+// is append-only: the restored content is recorded as a new version.
+func (r *WordRepository) RestoreAIVersion(ctx context.Context, wordID, userID string, version int) (*wordDomain.WordAIData, error) {
+	var restored *wordDomain.WordAIData
+	err := withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
+
+		var id string
+		err := tx.QueryRowContext(ctx, `
+			SELECT id FROM words WHERE id = $1 AND user_id = $2 FOR UPDATE
+		`, wordID, userID).Scan(&id)
+		if err == sql.ErrNoRows {
+			return domain.ErrWordNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		old, err := scanAIVersion(tx.QueryRowContext(ctx, `
+			SELECT
+				word_id,
+				version,
+				definition,
+				example_good,
+				example_bad,
+				pos,
+				cefr_level,
+				translation,
+				source,
+				prompt_version,
+				model,
+				hint,
+				restored_from,
+				created_at
+			FROM word_ai_data_versions
+			WHERE word_id = $1 AND version = $2
+		`, wordID, version))
+		if err == sql.ErrNoRows {
+			return domain.ErrVersionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		restored = &wordDomain.WordAIData{
+			Definition:    old.Definition,
+			ExampleGood:   old.ExampleGood,
+			ExampleBad:    old.ExampleBad,
+			PartOfSpeech:  old.PartOfSpeech,
+			CEFRLevel:     old.CEFRLevel,
+			Translation:   old.Translation,
+			Source:        wordDomain.AISourceRestore,
+			PromptVersion: old.PromptVersion,
+			Model:         old.Model,
+			Hint:          old.Hint,
+			RestoredFrom:  &old.Version,
+		}
+		return storeAIVersion(ctx, tx, wordID, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAIVersion(row rowScanner) (*wordDomain.WordAIData, error) {
+	var v wordDomain.WordAIData
+	err := row.Scan(
+		&v.WordID,
+		&v.Version,
+		&v.Definition,
+		&v.ExampleGood,
+		&v.ExampleBad,
+		&v.PartOfSpeech,
+		&v.CEFRLevel,
+		&v.Translation,
+		&v.Source,
+		&v.PromptVersion,
+		&v.Model,
+		&v.Hint,
+		&v.RestoredFrom,
+		&v.GeneratedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// DeleteAIData removes AI-generated data for a word so it can be regenerated
+func (r *WordRepository) DeleteAIData(ctx context.Context, wordID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM word_ai_data WHERE word_id = $1`, wordID)
+	return err
+}
+
+// Update updates the user-editable fields of a word
+func (r *WordRepository) Update(ctx context.Context, word *wordDomain.Word) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE words
+		SET text = $3, lemma = $4, lemma_version = $8, source = $5, confidence = $6, updated_at = $7
+		WHERE id = $1 AND user_id = $2
+	`, word.ID, word.UserID, word.Text, word.Lemma, word.Source, word.Confidence, word.UpdatedAt,
+		wordDomain.LemmaVersion)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Archive soft-archives a word and removes it from the review queue.
+// Reviews and statistics are kept so the word can be restored later.
+func (r *WordRepository) Archive(ctx context.Context, wordID, userID string) error {
+	return withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
+
+		res, err := tx.ExecContext(ctx, `
+			UPDATE words
+			SET archived_at = COALESCE(archived_at, now()), updated_at = now()
+			WHERE id = $1 AND user_id = $2
+		`, wordID, userID)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(res); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM review_queue WHERE user_id = $1 AND word_id = $2
+		`, userID, wordID)
+		return err
+	})
+}
+
+// Unarchive restores an archived word
+func (r *WordRepository) Unarchive(ctx context.Context, wordID, userID string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE words
+		SET archived_at = NULL, updated_at = now()
+		WHERE id = $1 AND user_id = $2
+	`, wordID, userID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// Delete permanently removes a word. AI data, reviews, stats and queue
+// entries are removed by ON DELETE CASCADE.
+func (r *WordRepository) Delete(ctx context.Context, wordID, userID string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		DELETE FROM words WHERE id = $1 AND user_id = $2
+	`, wordID, userID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// RefreshLemmas recomputes the lemmas stored under an older
+// word.LemmaVersion, returning how many words it updated
+func (r *WordRepository) RefreshLemmas(ctx context.Context) (int, error) {
+	stale := map[string]string{}
+	err := withinTx(ctx, r.db, func(ctx context.Context) error {
+		rows, err := conn(ctx, r.db).QueryContext(ctx, `
+			SELECT id, text FROM words WHERE lemma_version < $1
+		`, wordDomain.LemmaVersion)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id, text string
+			if err := rows.Scan(&id, &text); err != nil {
+				return err
+			}
+			stale[id] = text
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for id, text := range stale {
+			_, err := conn(ctx, r.db).ExecContext(ctx, `
+				UPDATE words SET lemma = $2, lemma_version = $3 WHERE id = $1
+			`, id, wordDomain.Lemma(text), wordDomain.LemmaVersion)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(stale), nil
+}
+
+// LockLemma takes a transaction-level advisory lock on a user's lemma.
+// Without a unique index (words captured before lemmas existed may share
+// one), this is what keeps two concurrent captures of a word from both
+// missing it.
+func (r *WordRepository) LockLemma(ctx context.Context, userID, lemma string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		SELECT pg_advisory_xact_lock(hashtext($1 || ':' || $2))
+	`, userID, lemma)
+	return err
+}
+
+// FindByLemma returns the user's word with the given lemma, preferring
+// active words over archived ones and older captures over newer ones
+func (r *WordRepository) FindByLemma(ctx context.Context, userID, lemma string) (*wordDomain.Word, error) {
+	var wordID string
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT id
+		FROM words
+		WHERE user_id = $1 AND lemma = $2
+		ORDER BY archived_at NULLS FIRST, created_at
+		LIMIT 1
+	`, userID, lemma).Scan(&wordID)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrWordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, wordID, userID)
+}
+
+// AddContext records a new context for one of the user's words
+func (r *WordRepository) AddContext(ctx context.Context, userID string, c *wordDomain.WordContext) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO word_contexts (id, word_id, sentence, source_url, source_title, captured_at)
+		SELECT $1, w.id, $3, $4, $5, $6
+		FROM words w
+		WHERE w.id = $2 AND w.user_id = $7
+	`, c.ID, c.WordID, c.Sentence, c.SourceURL, c.SourceTitle, c.CapturedAt, userID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// UpdateContext edits the sentence and source of a context
+func (r *WordRepository) UpdateContext(ctx context.Context, userID string, c *wordDomain.WordContext) error {
+	err := conn(ctx, r.db).QueryRowContext(ctx, `
+		UPDATE word_contexts c
+		SET sentence = $3, source_url = $4, source_title = $5
+		FROM words w
+		WHERE c.id = $1 AND c.word_id = $2 AND w.id = c.word_id AND w.user_id = $6
+		RETURNING c.captured_at
+	`, c.ID, c.WordID, c.Sentence, c.SourceURL, c.SourceTitle, userID).Scan(&c.CapturedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrContextNotFound
+	}
+	return err
+}
+
+// DeleteContext removes a context from a word
+func (r *WordRepository) DeleteContext(ctx context.Context, userID, wordID, contextID string) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `
+		DELETE FROM word_contexts c
+		USING words w
+		WHERE c.id = $1 AND c.word_id = $2 AND w.id = c.word_id AND w.user_id = $3
+	`, contextID, wordID, userID)
+	if err != nil {
+		return err
+	}
+	return expectContextAffected(res)
+}
+
+// attachDetails loads the contexts, tags and AI job state of all given
+// words, one query each
+func (r *WordRepository) attachDetails(ctx context.Context, words []*wordDomain.Word) error {
+	if len(words) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(words))
+	for i, w := range words {
+		ids[i] = w.ID
+	}
+
+	contexts, err := r.loadContexts(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	tags, err := r.loadTags(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	jobs, err := r.loadAIJobs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range words {
+		w.Contexts = contexts[w.ID]
+		w.Tags = tags[w.ID]
+		if j, ok := jobs[w.ID]; ok {
+			w.AIStatus = j.Status
+			w.AIError = j.LastError
+		} else if w.AIData != nil {
+			w.AIStatus = job.StatusDone
+		}
+	}
+	return nil
+}
+
+// loadContexts returns contexts grouped by word ID, oldest first
+func (r *WordRepository) loadContexts(ctx context.Context, wordIDs []string) (map[string][]wordDomain.WordContext, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT id, word_id, sentence, source_url, source_title, captured_at
+		FROM word_contexts
+		WHERE word_id = ANY($1)
+		ORDER BY captured_at, id
+	`, pq.Array(wordIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]wordDomain.WordContext)
+	for rows.Next() {
+		var c wordDomain.WordContext
+		if err := rows.Scan(
+			&c.ID,
+			&c.WordID,
+			&c.Sentence,
+			&c.SourceURL,
+			&c.SourceTitle,
+			&c.CapturedAt,
+		); err != nil {
+			return nil, err
+		}
+		result[c.WordID] = append(result[c.WordID], c)
+	}
+
+	return result, rows.Err()
+}
+
+// loadTags returns tags grouped by word ID, ordered by name
+func (r *WordRepository) loadTags(ctx context.Context, wordIDs []string) (map[string][]wordDomain.WordTag, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT wt.word_id, t.id, t.name
+		FROM word_tags wt
+		JOIN tags t ON t.id = wt.tag_id
+		WHERE wt.word_id = ANY($1)
+		ORDER BY lower(t.name)
+	`, pq.Array(wordIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]wordDomain.WordTag)
+	for rows.Next() {
+		var wordID string
+		var t wordDomain.WordTag
+		if err := rows.Scan(&wordID, &t.ID, &t.Name); err != nil {
+			return nil, err
+		}
+		result[wordID] = append(result[wordID], t)
+	}
+
+	return result, rows.Err()
+}
+
+// loadAIJobs returns the explanation job of each word that has one
+func (r *WordRepository) loadAIJobs(ctx context.Context, wordIDs []string) (map[string]job.Job, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT word_id, status, last_error
+		FROM ai_jobs
+		WHERE word_id = ANY($1)
+	`, pq.Array(wordIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]job.Job)
+	for rows.Next() {
+		var j job.Job
+		if err := rows.Scan(&j.WordID, &j.Status, &j.LastError); err != nil {
+			return nil, err
+		}
+		result[j.WordID] = j
+	}
+
+	return result, rows.Err()
+}
+
+// Merge folds the source word into the target: contexts and tags are moved over,
+// AI data is kept if the target has none, reviews are moved over (out of the
+// sessions that reviewed both words) and the target's review_stats are
+// recomputed. The source word is then deleted.
+func (r *WordRepository) Merge(ctx context.Context, userID, targetID, sourceID string) error {
+	return withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id FROM words
+			WHERE user_id = $1 AND id IN ($2, $3)
+			FOR UPDATE
+		`, userID, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+		found := 0
+		for rows.Next() {
+			found++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if found != 2 {
+			return domain.ErrWordNotFound
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE word_contexts SET word_id = $1 WHERE word_id = $2`, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO word_tags (word_id, tag_id)
+			SELECT $1, tag_id FROM word_tags WHERE word_id = $2
+			ON CONFLICT DO NOTHING
+		`, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE words SET updated_at = now() WHERE id = $1`, targetID)
+		if err != nil {
+			return err
+		}
+
+		// The source's current explanation becomes the target's next version
+		// if the target has none
+		_, err = tx.ExecContext(ctx, `
+			WITH src AS (
+				SELECT ai.*, v.prompt_version, v.model, v.hint
+				FROM word_ai_data ai
+				LEFT JOIN word_ai_data_versions v ON v.word_id = ai.word_id AND v.version = ai.version
+				WHERE ai.word_id = $2
+			), next AS (
+				SELECT COALESCE(MAX(version), 0) + 1 AS version
+				FROM word_ai_data_versions
+				WHERE word_id = $1
+			), copied AS (
+				INSERT INTO word_ai_data (
+					word_id,
+					definition,
+					example_good,
+					example_bad,
+					pos,
+					translation,
+					cefr_level,
+					generated_at,
+					version,
+					source
+				)
+				SELECT $1, definition, example_good, example_bad, pos, translation, cefr_level, generated_at, next.version, source
+				FROM src, next
+				ON CONFLICT (word_id) DO NOTHING
+				RETURNING version
+			)
+			INSERT INTO word_ai_data_versions (
+				id,
+				word_id,
+				version,
+				definition,
+				example_good,
+				example_bad,
+				pos,
+				cefr_level,
+				translation,
+				source,
+				prompt_version,
+				model,
+				hint,
+				created_at
+			)
+			SELECT gen_random_uuid(), $1, copied.version, definition, example_good, example_bad, pos, cefr_level, translation, source, prompt_version, model, hint, generated_at
+			FROM src, copied
+		`, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+
+		// A session has one review per word, so the source's reviews from
+		// sessions that also reviewed the target are kept outside of them
+		_, err = tx.ExecContext(ctx, `
+			UPDATE reviews SET session_id = NULL
+			WHERE word_id = $2 AND session_id IN (
+				SELECT session_id FROM reviews WHERE word_id = $1 AND session_id IS NOT NULL
+			)
+		`, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE reviews SET word_id = $1 WHERE word_id = $2`, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+
+		// Cascades to the source's AI data, stats and queue entry
+		_, err = tx.ExecContext(ctx, `DELETE FROM words WHERE id = $1`, sourceID)
+		if err != nil {
+			return err
+		}
+
+		if err := recomputeReviewStats(ctx, tx, targetID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// expectAffected maps "no rows touched" to ErrWordNotFound
+func expectAffected(res sql.Result) error {
+	return expectRows(res, domain.ErrWordNotFound)
+}
+
+// expectContextAffected maps "no rows touched" to ErrContextNotFound
+func expectContextAffected(res sql.Result) error {
+	return expectRows(res, domain.ErrContextNotFound)
+}
+
+func expectRows(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sonsonha/eng-noting/internal/domain"
+	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// newTestDB returns a database whose statements must be expected, in
+// order, on the mock
+func newTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+	return db, mock
+}
+
+// sqlPrefix matches a statement starting with the given text
+func sqlPrefix(text string) string {
+	return `^\s*` + regexp.QuoteMeta(text)
+}
+
+func TestMergeUnlinksSharedSessionReviews(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWordRepository(db)
+	done := sqlmock.NewResult(0, 1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(sqlPrefix("SELECT id FROM words")).
+		WithArgs("u1", "target", "source").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("target").AddRow("source"))
+	mock.ExpectExec(sqlPrefix("UPDATE word_contexts")).WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("INSERT INTO word_tags")).WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("UPDATE words SET updated_at")).WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("WITH src AS")).WillReturnResult(done)
+	// Both words were reviewed in one session: the source's review leaves
+	// it before the reviews are moved, so the move cannot collide
+	mock.ExpectExec(sqlPrefix("UPDATE reviews SET session_id = NULL")).
+		WithArgs("target", "source").
+		WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("UPDATE reviews SET word_id")).
+		WithArgs("target", "source").
+		WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("DELETE FROM words")).WithArgs("source").WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("DELETE FROM review_stats")).WithArgs("target").WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("INSERT INTO review_stats")).WithArgs("target").WillReturnResult(done)
+	mock.ExpectCommit()
+
+	if err := repo.Merge(context.Background(), "u1", "target", "source"); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+}
+
+func TestRestoreAIVersionBringsBackTranslation(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWordRepository(db)
+	anyArg := sqlmock.AnyArg()
+	done := sqlmock.NewResult(0, 1)
+	translation := "tiền thuê"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(sqlPrefix("SELECT id FROM words")).
+		WithArgs("w1", "u1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("w1"))
+	mock.ExpectQuery(sqlPrefix("SELECT")).
+		WithArgs("w1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"word_id", "version", "definition", "example_good", "example_bad", "pos", "cefr_level",
+			"translation", "source", "prompt_version", "model", "hint", "restored_from", "created_at",
+		}).AddRow("w1", 1, "money paid for a home", "The rent is due.", nil, "noun", "B1",
+			translation, "ai", "explain-v2", "gpt-4o-mini", nil, nil, time.Now()))
+	mock.ExpectQuery(sqlPrefix("SELECT COALESCE(MAX(version), 0) + 1")).
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	mock.ExpectExec(sqlPrefix("INSERT INTO word_ai_data_versions")).
+		WithArgs(anyArg, "w1", 3, "money paid for a home", "The rent is due.", nil, anyArg, anyArg,
+			translation, "restore", anyArg, anyArg, nil, anyArg, anyArg).
+		WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("INSERT INTO word_ai_data")).
+		WithArgs("w1", "money paid for a home", "The rent is due.", nil, anyArg, anyArg,
+			translation, anyArg, 3, "restore").
+		WillReturnResult(done)
+	mock.ExpectCommit()
+
+	restored, err := repo.RestoreAIVersion(context.Background(), "w1", "u1", 1)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.Version != 3 || restored.Translation == nil || *restored.Translation != translation {
+		t.Errorf("restored = %+v, want version 3 with the translation", restored)
+	}
+}
+
+func TestStoreAIDataRejectsChangedExplanation(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWordRepository(db)
+
+	// The job was queued against version 1; a manual edit made version 2
+	mock.ExpectBegin()
+	mock.ExpectQuery(sqlPrefix("SELECT COALESCE(ai.version, 0)")).
+		WithArgs("w1").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectRollback()
+
+	err := repo.StoreAIData(context.Background(), "w1", 1, &wordDomain.WordAIData{Definition: "d", ExampleGood: "e"})
+	if !errors.Is(err, domain.ErrAIDataChanged) {
+		t.Fatalf("expected ErrAIDataChanged, got %v", err)
+	}
+}
+
+func TestWordWritesJoinOuterTransaction(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWordRepository(db)
+	done := sqlmock.NewResult(0, 1)
+
+	// One transaction around both writes, rolled back as a whole
+	mock.ExpectBegin()
+	mock.ExpectExec(sqlPrefix("INSERT INTO words")).WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("UPDATE words")).WithArgs("w1", "u1").WillReturnResult(done)
+	mock.ExpectExec(sqlPrefix("DELETE FROM review_queue")).WillReturnError(errors.New("boom"))
+	mock.ExpectRollback()
+
+	err := NewTxManager(db).WithinTx(context.Background(), func(ctx context.Context) error {
+		if err := repo.Create(ctx, &wordDomain.Word{ID: "w1", UserID: "u1", Text: "rent"}); err != nil {
+			return err
+		}
+		return repo.Archive(ctx, "w1", "u1")
+	})
+	if err == nil {
+		t.Fatal("expected the archive to fail")
+	}
+}
+
+func TestListCreatedAtCursorIsTyped(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWordRepository(db)
+	after := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	newest := after.Add(-time.Minute)
+	columns := []string{
+		"id", "user_id", "text", "lemma", "source", "confidence", "created_at", "updated_at", "archived_at",
+		"definition", "example_good", "example_bad", "pos", "cefr_level", "translation", "generated_at",
+		"version", "source", "sort_key",
+	}
+
+	// The key is sent as a time, never as the server's rendering of one
+	mock.ExpectQuery(regexp.QuoteMeta("(w.created_at, w.id) < ($2::timestamp, $3::uuid)")).
+		WithArgs("u1", after, "w0", 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("w1", "u1", "rent", "rent", nil, 3, newest, newest, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "15/01/2024 10:29:00").
+			AddRow("w2", "u1", "lease", "lease", nil, 3, newest.Add(-time.Minute), newest, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "15/01/2024 10:28:00"))
+	mock.ExpectQuery("word_contexts").WillReturnRows(sqlmock.NewRows([]string{"word_id"}))
+	mock.ExpectQuery("word_tags").WillReturnRows(sqlmock.NewRows([]string{"word_id"}))
+	mock.ExpectQuery("ai_jobs").WillReturnRows(sqlmock.NewRows([]string{"word_id"}))
+
+	page, err := repo.List(context.Background(), wordDomain.ListFilter{
+		UserID:     "u1",
+		Sort:       wordDomain.SortCreatedAt,
+		Descending: true,
+		Limit:      1,
+		After:      &wordDomain.Cursor{Sort: wordDomain.SortCreatedAt, Descending: true, CreatedAt: after, ID: "w0"},
+	})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page.Words) != 1 || page.Next == nil {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	if !page.Next.CreatedAt.Equal(newest) || page.Next.Key != "" || page.Next.ID != "w1" {
+		t.Errorf("expected the cursor at w1's creation time, got %+v", page.Next)
+	}
+}
+
+func TestLockLemmaHoldsUntilLookupCommits(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWordRepository(db)
+
+	// Lock and lookup run on the same transaction, so the lock covers both
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("pg_advisory_xact_lock")).WithArgs("u1", "rent").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(sqlPrefix("SELECT id")).WithArgs("u1", "rent").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	err := NewTxManager(db).WithinTx(context.Background(), func(ctx context.Context) error {
+		if err := repo.LockLemma(ctx, "u1", "rent"); err != nil {
+			return err
+		}
+		_, err := repo.FindByLemma(ctx, "u1", "rent")
+		if !errors.Is(err, domain.ErrWordNotFound) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestRefreshLemmasRunsInOneTransaction(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWordRepository(db)
+
+	// A failed update leaves every lemma as it was, to be retried whole
+	mock.ExpectBegin()
+	mock.ExpectQuery(sqlPrefix("SELECT id, text FROM words")).WithArgs(wordDomain.LemmaVersion).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "text"}).AddRow("w1", "Running"))
+	mock.ExpectExec(sqlPrefix("UPDATE words SET lemma")).WithArgs("w1", "run", wordDomain.LemmaVersion).
+		WillReturnError(errors.New("boom"))
+	mock.ExpectRollback()
+
+	if _, err := repo.RefreshLemmas(context.Background()); err == nil {
+		t.Fatal("expected the refresh to fail")
+	}
+}
+
+func TestCountFilterWhere(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	two, four := 2, 4
+	low, high := 0.25, 0.75
+	yes := true
+
+	tests := []struct {
+		name   string
+		filter wordDomain.ListFilter
+		where  string
+		args   []driver.Value
+	}{
+		{
+			name:  "active words",
+			where: "w.user_id = $1 AND w.archived_at IS NULL",
+			args:  []driver.Value{"u1"},
+		},
+		{
+			name:   "archived words",
+			filter: wordDomain.ListFilter{Archived: true},
+			where:  "w.user_id = $1 AND w.archived_at IS NOT NULL",
+			args:   []driver.Value{"u1"},
+		},
+		{
+			name:   "tag",
+			filter: wordDomain.ListFilter{TagID: "t1"},
+			where:  "wt.word_id = w.id AND wt.tag_id = $2",
+			args:   []driver.Value{"u1", "t1"},
+		},
+		{
+			name:   "substring search escapes wildcards",
+			filter: wordDomain.ListFilter{Query: `50%_off\`},
+			where:  "w.text ILIKE $2",
+			args:   []driver.Value{"u1", `%50\%\_off\\%`},
+		},
+		{
+			name:   "prefix search escapes wildcards",
+			filter: wordDomain.ListFilter{Query: "re_", Match: wordDomain.MatchPrefix},
+			where:  "w.text ILIKE $2",
+			args:   []driver.Value{"u1", `re\_%`},
+		},
+		{
+			name:   "full-text search is not escaped",
+			filter: wordDomain.ListFilter{Query: "re_", Match: wordDomain.MatchFullText},
+			where:  "plainto_tsquery('english', $2)",
+			args:   []driver.Value{"u1", "re_"},
+		},
+		{
+			name:   "CEFR levels ignore case",
+			filter: wordDomain.ListFilter{CEFRLevels: []string{"B2", "c1"}},
+			where:  "lower(ai.cefr_level) = ANY($2)",
+			args:   []driver.Value{"u1", "{\"b2\",\"c1\"}"},
+		},
+		{
+			name:   "part of speech ignores case",
+			filter: wordDomain.ListFilter{PartOfSpeech: "Noun"},
+			where:  "lower(ai.pos) = lower($2)",
+			args:   []driver.Value{"u1", "Noun"},
+		},
+		{
+			name:   "confidence range",
+			filter: wordDomain.ListFilter{MinConfidence: &two, MaxConfidence: &four},
+			where:  "w.confidence >= $2 AND w.confidence <= $3",
+			args:   []driver.Value{"u1", int64(2), int64(4)},
+		},
+		{
+			name:   "has AI data",
+			filter: wordDomain.ListFilter{HasAIData: &yes},
+			where:  "ai.word_id IS NOT NULL",
+			args:   []driver.Value{"u1"},
+		},
+		{
+			name:   "created date range excludes the end",
+			filter: wordDomain.ListFilter{CreatedFrom: &from, CreatedTo: &to},
+			where:  "w.created_at >= $2 AND w.created_at < $3",
+			args:   []driver.Value{"u1", from, to},
+		},
+		{
+			name:   "accuracy band",
+			filter: wordDomain.ListFilter{MinAccuracy: &low, MaxAccuracy: &high},
+			where:  "rs.accuracy_rate >= $2 AND rs.accuracy_rate <= $3",
+			args:   []driver.Value{"u1", low, high},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newTestDB(t)
+			repo := NewWordRepository(db)
+			tt.filter.UserID = "u1"
+
+			mock.ExpectQuery(regexp.QuoteMeta(tt.where)).WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+			if _, err := repo.Count(context.Background(), tt.filter); err != nil {
+				t.Fatalf("count: %v", err)
+			}
+		})
+	}
+}
+
+func TestListOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter wordDomain.ListFilter
+		sql    string
+		args   []driver.Value
+	}{
+		{
+			name:   "newest first",
+			filter: wordDomain.ListFilter{Descending: true},
+			sql:    "ORDER BY w.created_at DESC, w.id DESC",
+			args:   []driver.Value{"u1", int64(21)},
+		},
+		{
+			name:   "priority",
+			filter: wordDomain.ListFilter{Sort: wordDomain.SortPriority, Descending: true},
+			sql:    "ORDER BY COALESCE(rq.priority_score, 0) DESC, w.id DESC",
+			args:   []driver.Value{"u1", int64(21)},
+		},
+		{
+			name:   "accuracy",
+			filter: wordDomain.ListFilter{Sort: wordDomain.SortAccuracy},
+			sql:    "ORDER BY COALESCE(rs.accuracy_rate, 0) ASC, w.id ASC",
+			args:   []driver.Value{"u1", int64(21)},
+		},
+		{
+			name: "alphabetical after a cursor",
+			filter: wordDomain.ListFilter{
+				Sort:  wordDomain.SortAlphabetical,
+				After: &wordDomain.Cursor{Sort: wordDomain.SortAlphabetical, Key: "rent", ID: "w0"},
+			},
+			sql:  "(lower(w.text), w.id) > ($2::text, $3::uuid)",
+			args: []driver.Value{"u1", "rent", "w0", int64(21)},
+		},
+		{
+			name: "accuracy descending after a cursor",
+			filter: wordDomain.ListFilter{
+				Sort:       wordDomain.SortAccuracy,
+				Descending: true,
+				After:      &wordDomain.Cursor{Sort: wordDomain.SortAccuracy, Descending: true, Key: "0.5", ID: "w0"},
+			},
+			sql:  "(COALESCE(rs.accuracy_rate, 0), w.id) < ($2::float8, $3::uuid)",
+			args: []driver.Value{"u1", "0.5", "w0", int64(21)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newTestDB(t)
+			repo := NewWordRepository(db)
+			tt.filter.UserID = "u1"
+			tt.filter.Limit = 20
+
+			mock.ExpectQuery(regexp.QuoteMeta(tt.sql)).WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			if _, err := repo.List(context.Background(), tt.filter); err != nil {
+				t.Fatalf("list: %v", err)
+			}
+		})
+	}
+}
+
+func TestArchiveDropsQueueEntry(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWordRepository(db)
+
+	// The word keeps its reviews and stats; only its queue entry goes
+	mock.ExpectBegin()
+	mock.ExpectExec(sqlPrefix("UPDATE words")).WithArgs("w1", "u1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(sqlPrefix("DELETE FROM review_queue")).WithArgs("u1", "w1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.Archive(context.Background(), "w1", "u1"); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+}
+
+func TestArchiveAndDeleteOnlyOwnWords(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWordRepository(db)
+	none := sqlmock.NewResult(0, 0)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(sqlPrefix("UPDATE words")).WithArgs("w1", "u2").WillReturnResult(none)
+	mock.ExpectRollback()
+	mock.ExpectExec(sqlPrefix("DELETE FROM words")).WithArgs("w1", "u2").WillReturnResult(none)
+
+	if err := repo.Archive(context.Background(), "w1", "u2"); !errors.Is(err, domain.ErrWordNotFound) {
+		t.Errorf("archive: expected ErrWordNotFound, got %v", err)
+	}
+	if err := repo.Delete(context.Background(), "w1", "u2"); !errors.Is(err, domain.ErrWordNotFound) {
+		t.Errorf("delete: expected ErrWordNotFound, got %v", err)
+	}
+}
@@ -25,7 +25,8 @@ WITH recent_reviews AS (
     SELECT
         word_id,
         COUNT(*) AS recent_reviews,
-        COUNT(*) FILTER (WHERE result = false) AS recent_failures
+        COUNT(*) FILTER (WHERE result = false) AS recent_failures,
+        COUNT(*) FILTER (WHERE result = true AND hesitant) AS recent_hesitant
     FROM reviews
     WHERE user_id = $1
       AND reviewed_at >= now() - interval '7 days'
@@ -38,11 +39,13 @@ SELECT
     COALESCE(rs.total_reviews, 0) AS total_reviews,
     rs.last_reviewed_at,
     COALESCE(rr.recent_failures, 0) AS recent_failures,
-    COALESCE(rr.recent_reviews, 0) AS recent_reviews
+    COALESCE(rr.recent_reviews, 0) AS recent_reviews,
+    COALESCE(rr.recent_hesitant, 0) AS recent_hesitant
 FROM words w
 LEFT JOIN review_stats rs ON rs.word_id = w.id
 LEFT JOIN recent_reviews rr ON rr.word_id = w.id
-WHERE w.user_id = $1;
+WHERE w.user_id = $1
+  AND w.archived_at IS NULL;
 `
 
 	rows, err := r.db.QueryContext(ctx, q, userID)
@@ -65,6 +68,7 @@ WHERE w.user_id = $1;
 			&lastReviewedAt,
 			&r.RecentFailures,
 			&r.RecentReviews,
+			&r.RecentHesitant,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLoadStatsLeavesOutArchivedWords(t *testing.T) {
+	db, mock := newTestDB(t)
+	repo := NewWordStatsRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("AND w.archived_at IS NULL")).WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"word_id"}))
+
+	if _, err := repo.LoadStats(context.Background(), "u1"); err != nil {
+		t.Fatalf("load stats: %v", err)
+	}
+}
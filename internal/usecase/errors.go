@@ -1,9 +1,34 @@
 package usecase
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+)
 
 var (
 	ErrNotFound   = errors.New("resource not found")
 	ErrForbidden  = errors.New("forbidden")
 	ErrBadRequest = errors.New("bad request")
+	ErrConflict   = errors.New("conflict")
 )
+
+// mapDomainError translates repository-level domain errors into use case errors
+func mapDomainError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrWordNotFound),
+		errors.Is(err, domain.ErrContextNotFound),
+		errors.Is(err, domain.ErrTagNotFound),
+		errors.Is(err, domain.ErrVersionNotFound),
+		errors.Is(err, domain.ErrSessionNotFound),
+		errors.Is(err, domain.ErrReviewNotFound):
+		return ErrNotFound
+	case errors.Is(err, domain.ErrTagExists),
+		errors.Is(err, domain.ErrSessionMoved),
+		errors.Is(err, domain.ErrReviewExists),
+		errors.Is(err, domain.ErrReviewKeyUsed),
+		errors.Is(err, domain.ErrAIDataChanged):
+		return ErrConflict
+	}
+	return err
+}
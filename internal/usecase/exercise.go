@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/ai"
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// distractorCandidates is how many of the user's words are considered as
+// distractors; a few spare ones cover candidates that collapse to the same lemma
+const distractorCandidates = exercise.MCQOptions + 2
+
+// aiDistractors is how many distractors are requested from the AI at once
+const aiDistractors = 5
+
+// prepareTimeout bounds the AI call made in the background for a word's
+// exercise
+const prepareTimeout = time.Minute
+
+// ExerciseBuilder produces the content of session items
+type ExerciseBuilder struct {
+	wordRepo       wordDomain.WordRepository
+	distractorRepo exercise.DistractorRepository
+	clozeRepo      exercise.ClozeRepository
+	aiSvc          ai.AIService
+
+	// preparing holds the IDs of words whose AI content is being generated
+	// in the background, so that each is generated once at a time
+	preparing  sync.Map
+	background sync.WaitGroup
+}
+
+// NewExerciseBuilder creates a new ExerciseBuilder
+func NewExerciseBuilder(
+	wordRepo wordDomain.WordRepository,
+	distractorRepo exercise.DistractorRepository,
+	clozeRepo exercise.ClozeRepository,
+	aiSvc ai.AIService,
+) *ExerciseBuilder {
+	return &ExerciseBuilder{
+		wordRepo:       wordRepo,
+		distractorRepo: distractorRepo,
+		clozeRepo:      clozeRepo,
+		aiSvc:          aiSvc,
+	}
+}
+
+// BuildMCQ builds a multiple-choice question for a word. Distractors come
+// from the user's own similar words first, then from AI suggestions cached
+// for the word. It never waits for the AI: when the cache is short, more
+// suggestions are generated in the background for later sessions. It
+// returns nil if no question can be built, e.g. the word has neither a
+// definition nor a usable context yet.
+func (b *ExerciseBuilder) BuildMCQ(ctx context.Context, userID, wordID string) (*exercise.MCQ, error) {
+	word, err := b.wordRepo.GetByID(ctx, wordID, userID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	candidates, err := b.distractorRepo.Candidates(ctx, word, distractorCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	distractors := exercise.MergeDistractors(word.Text, exercise.MCQOptions-1, candidates)
+	if len(distractors) < exercise.MCQOptions-1 {
+		cached, err := b.distractorRepo.Cached(ctx, word.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(cached) < exercise.MCQOptions-1 && word.AIData != nil {
+			b.prepare(word, func(ctx context.Context) {
+				cacheDistractors(ctx, b.aiSvc, b.distractorRepo, word)
+			})
+		}
+		distractors = exercise.MergeDistractors(word.Text, exercise.MCQOptions-1, distractors, cached)
+	}
+
+	return exercise.NewMCQ(word, distractors), nil
+}
+
+// BuildCloze builds a fill-in-the-blank exercise for a word. The user's own
+// contexts are preferred; an AI-written sentence is used only when none of
+// them can make one. The result is cached so the answer can be graded
+// against the same sentence, and so the AI is called once per word. The
+// AI sentence is written in the background, so until it is ready BuildCloze
+// returns nil, as it does when no exercise can be built.
+func (b *ExerciseBuilder) BuildCloze(ctx context.Context, userID, wordID string) (*exercise.Cloze, error) {
+	word, err := b.wordRepo.GetByID(ctx, wordID, userID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	cached, err := b.clozeRepo.Get(ctx, word.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Contexts are rebuilt every time since they may have been edited
+	if c := exercise.ContextCloze(word); c != nil {
+		if cached == nil || *cached != *c {
+			if err := b.clozeRepo.Store(ctx, word.ID, c); err != nil {
+				return nil, err
+			}
+		}
+		return c, nil
+	}
+
+	if cached != nil && cached.Source == exercise.ClozeFromAI && cached.Text == word.Text {
+		return cached, nil
+	}
+	b.prepare(word, func(ctx context.Context) {
+		b.writeCloze(ctx, word)
+	})
+	return nil, nil
+}
+
+// writeCloze asks the AI for a sentence using the word and caches the
+// exercise made from it. Failures are not errors: the word is just asked
+// in another way until a later session tries again.
+func (b *ExerciseBuilder) writeCloze(ctx context.Context, word *wordDomain.Word) {
+	var definition string
+	if word.AIData != nil {
+		definition = word.AIData.Definition
+	}
+	sentence, err := b.aiSvc.WriteClozeSentence(ctx, word.Text, definition)
+	if err != nil {
+		return
+	}
+
+	if c := exercise.NewCloze(sentence, word.Text, exercise.ClozeFromAI); c != nil {
+		b.clozeRepo.Store(ctx, word.ID, c)
+	}
+}
+
+// prepare runs generate in the background, unless there is no AI service
+// or the word's content is already being generated. It does not outlive
+// prepareTimeout, nor depend on the request that asked for it.
+func (b *ExerciseBuilder) prepare(word *wordDomain.Word, generate func(ctx context.Context)) {
+	if b.aiSvc == nil {
+		return
+	}
+	if _, busy := b.preparing.LoadOrStore(word.ID, struct{}{}); busy {
+		return
+	}
+
+	b.background.Add(1)
+	go func() {
+		defer b.background.Done()
+		defer b.preparing.Delete(word.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), prepareTimeout)
+		defer cancel()
+		generate(ctx)
+	}()
+}
+
+// PlainType returns the review type to use for a word when its exercise
+// cannot be built: "typing", recalling the word from its definition, if the
+// word has one and typing is allowed, and the self-graded review.Recall
+// otherwise. Neither lets the word be answered by copying what is shown.
+func (b *ExerciseBuilder) PlainType(ctx context.Context, userID, wordID string, allowed []string) (string, error) {
+	word, err := b.wordRepo.GetByID(ctx, wordID, userID)
+	if err != nil {
+		return "", mapDomainError(err)
+	}
+	if word.AIData == nil || word.AIData.Definition == "" {
+		return review.Recall, nil
+	}
+	if len(allowed) > 0 && !slices.Contains(allowed, "typing") {
+		return review.Recall, nil
+	}
+	return "typing", nil
+}
+
+// BuildMatchBoards bundles words into matching boards of MinMatchPairs to
+// MaxMatchPairs words, keeping their order. Only words with a definition
+// can be matched; the words left off every board are returned with the boards.
+func (b *ExerciseBuilder) BuildMatchBoards(ctx context.Context, userID string, wordIDs []string) ([]*exercise.MatchBoard, []string, error) {
+	var matchable []*wordDomain.Word
+	var leftover []string
+	for _, id := range wordIDs {
+		word, err := b.wordRepo.GetByID(ctx, id, userID)
+		if err != nil && !errors.Is(err, domain.ErrWordNotFound) {
+			return nil, nil, err
+		}
+		if word == nil || word.AIData == nil || word.AIData.Definition == "" {
+			leftover = append(leftover, id)
+			continue
+		}
+		matchable = append(matchable, word)
+	}
+
+	var boards []*exercise.MatchBoard
+	for _, size := range exercise.BoardSizes(len(matchable)) {
+		boards = append(boards, exercise.NewMatchBoard(matchable[:size]))
+		matchable = matchable[size:]
+	}
+	for _, word := range matchable {
+		leftover = append(leftover, word.ID)
+	}
+
+	return boards, leftover, nil
+}
+
+// cacheDistractors asks the AI for distractors for a word that has an
+// explanation and caches them. It returns what was generated, or nil if
+// generation failed.
+func cacheDistractors(
+	ctx context.Context,
+	aiSvc ai.AIService,
+	distractorRepo exercise.DistractorRepository,
+	word *wordDomain.Word,
+) []string {
+	if aiSvc == nil || word.AIData == nil {
+		return nil
+	}
+
+	generated, err := aiSvc.SuggestDistractors(ctx, word.Text, word.AIData.Definition, aiDistractors)
+	if err != nil {
+		return nil
+	}
+	if err := distractorRepo.Store(ctx, word.ID, generated); err != nil {
+		return nil
+	}
+	return generated
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/ai"
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// exerciseWords knows one explained word, "w1", met in a short context
+type exerciseWords struct{ word.WordRepository }
+
+func (exerciseWords) GetByID(ctx context.Context, wordID, userID string) (*word.Word, error) {
+	if wordID != "w1" {
+		return nil, domain.ErrWordNotFound
+	}
+	return &word.Word{
+		ID:       wordID,
+		UserID:   userID,
+		Text:     "resilient",
+		AIData:   &word.WordAIData{Definition: "able to recover quickly"},
+		Contexts: []word.WordContext{{Sentence: "So resilient."}},
+	}, nil
+}
+
+// distractorCache has none of the user's words to offer
+type distractorCache struct {
+	exercise.DistractorRepository
+	mu     sync.Mutex
+	cached []string
+}
+
+func (c *distractorCache) Candidates(ctx context.Context, w *word.Word, limit int) ([]string, error) {
+	return nil, nil
+}
+
+func (c *distractorCache) Cached(ctx context.Context, wordID string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cached, nil
+}
+
+func (c *distractorCache) Store(ctx context.Context, wordID string, distractors []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cached = append(c.cached, distractors...)
+	return nil
+}
+
+type clozeCache struct {
+	exercise.ClozeRepository
+	mu     sync.Mutex
+	cached *exercise.Cloze
+}
+
+func (c *clozeCache) Get(ctx context.Context, wordID string) (*exercise.Cloze, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cached, nil
+}
+
+func (c *clozeCache) Store(ctx context.Context, wordID string, cloze *exercise.Cloze) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cached = cloze
+	return nil
+}
+
+// slowAI answers only once released, counting the calls it gets
+type slowAI struct {
+	ai.AIService
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (a *slowAI) SuggestDistractors(ctx context.Context, w, definition string, n int) ([]string, error) {
+	a.calls.Add(1)
+	<-a.release
+	return []string{"fragile", "brittle", "weak"}, nil
+}
+
+func (a *slowAI) WriteClozeSentence(ctx context.Context, w, definition string) (string, error) {
+	a.calls.Add(1)
+	<-a.release
+	return "She stayed resilient after the failure.", nil
+}
+
+func newExerciseFixture() (*ExerciseBuilder, *slowAI) {
+	slow := &slowAI{release: make(chan struct{})}
+	return NewExerciseBuilder(exerciseWords{}, &distractorCache{}, &clozeCache{}, slow), slow
+}
+
+func TestBuildMCQDoesNotWaitForAI(t *testing.T) {
+	b, slow := newExerciseFixture()
+
+	// Both requests return at once, and the AI is asked once
+	for i := 0; i < 2; i++ {
+		q, err := b.BuildMCQ(context.Background(), "u1", "w1")
+		if err != nil {
+			t.Fatalf("build: %v", err)
+		}
+		if q != nil {
+			t.Fatalf("expected no question before the AI answers, got %+v", q)
+		}
+	}
+
+	close(slow.release)
+	b.background.Wait()
+	if n := slow.calls.Load(); n != 1 {
+		t.Fatalf("expected one AI call, got %d", n)
+	}
+
+	q, err := b.BuildMCQ(context.Background(), "u1", "w1")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if q == nil || len(q.Options) != exercise.MCQOptions {
+		t.Fatalf("expected a question from the cached options, got %+v", q)
+	}
+	if n := slow.calls.Load(); n != 1 {
+		t.Errorf("expected the cache used, got %d AI calls", n)
+	}
+}
+
+func TestBuildClozeDoesNotWaitForAI(t *testing.T) {
+	b, slow := newExerciseFixture()
+
+	c, err := b.BuildCloze(context.Background(), "u1", "w1")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if c != nil {
+		t.Fatalf("expected no cloze before the AI answers, got %+v", c)
+	}
+
+	close(slow.release)
+	b.background.Wait()
+
+	c, err = b.BuildCloze(context.Background(), "u1", "w1")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if c == nil || c.Source != exercise.ClozeFromAI {
+		t.Fatalf("expected the AI cloze, got %+v", c)
+	}
+}
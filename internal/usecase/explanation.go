@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// maxHintLength caps the meaning hint passed to the AI prompt
+const maxHintLength = 200
+
+// RegenerateExplanationInput represents input for regenerating an explanation
+type RegenerateExplanationInput struct {
+	WordID string
+	UserID string
+	Hint   string // optional, e.g. "the finance meaning"
+}
+
+// RegenerateExplanation queues a new AI explanation for a word. The current
+// explanation stays in place until the new one is ready, and both are kept
+// in the version history.
+func (uc *WordUseCase) RegenerateExplanation(ctx context.Context, input RegenerateExplanationInput) error {
+	hint := wordDomain.CleanText(input.Hint)
+	if len(hint) > maxHintLength {
+		return ErrBadRequest
+	}
+
+	word, err := uc.wordRepo.GetByID(ctx, input.WordID, input.UserID)
+	if err != nil {
+		return mapDomainError(err)
+	}
+
+	var hintPtr *string
+	if hint != "" {
+		hintPtr = &hint
+	}
+	return uc.enqueueExplanation(ctx, word, hintPtr)
+}
+
+// EditExplanationInput represents input for hand-editing an explanation.
+// Nil fields keep their current value.
+type EditExplanationInput struct {
+	WordID       string
+	UserID       string
+	Definition   *string
+	ExampleGood  *string
+	ExampleBad   *string
+	PartOfSpeech *string
+	CEFRLevel    *string
+	Translation  *string
+}
+
+// EditExplanationOutput represents output from editing an explanation
+type EditExplanationOutput struct {
+	AIData *wordDomain.WordAIData
+}
+
+// EditExplanation stores a hand-edited explanation as a new version. A word
+// without an explanation needs at least a definition and a good example.
+func (uc *WordUseCase) EditExplanation(ctx context.Context, input EditExplanationInput) (*EditExplanationOutput, error) {
+	word, err := uc.wordRepo.GetByID(ctx, input.WordID, input.UserID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	edited := &wordDomain.WordAIData{Source: wordDomain.AISourceManual}
+	if current := word.AIData; current != nil {
+		edited.Definition = current.Definition
+		edited.ExampleGood = current.ExampleGood
+		edited.ExampleBad = current.ExampleBad
+		edited.PartOfSpeech = current.PartOfSpeech
+		edited.CEFRLevel = current.CEFRLevel
+		edited.Translation = current.Translation
+	}
+
+	if input.Definition != nil {
+		edited.Definition = strings.TrimSpace(*input.Definition)
+	}
+	if input.ExampleGood != nil {
+		edited.ExampleGood = strings.TrimSpace(*input.ExampleGood)
+	}
+	if input.ExampleBad != nil {
+		edited.ExampleBad = optionalText(*input.ExampleBad)
+	}
+	if input.PartOfSpeech != nil {
+		edited.PartOfSpeech = optionalText(*input.PartOfSpeech)
+	}
+	if input.CEFRLevel != nil {
+		edited.CEFRLevel = optionalText(strings.ToUpper(*input.CEFRLevel))
+	}
+	if input.Translation != nil {
+		edited.Translation = optionalText(*input.Translation)
+	}
+
+	if edited.Definition == "" || edited.ExampleGood == "" {
+		return nil, ErrBadRequest
+	}
+	if edited.CEFRLevel != nil && !validCEFRLevel(*edited.CEFRLevel) {
+		return nil, ErrBadRequest
+	}
+
+	edited.GeneratedAt = time.Now()
+	if err := uc.wordRepo.StoreAIData(ctx, word.ID, aiVersion(word), edited); err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	return &EditExplanationOutput{AIData: edited}, nil
+}
+
+// ListExplanationVersionsOutput represents output from listing explanation versions
+type ListExplanationVersionsOutput struct {
+	Versions []*wordDomain.WordAIData // newest first
+}
+
+// ListExplanationVersions returns the explanation history of a word
+func (uc *WordUseCase) ListExplanationVersions(ctx context.Context, input WordRefInput) (*ListExplanationVersionsOutput, error) {
+	if _, err := uc.wordRepo.GetByID(ctx, input.WordID, input.UserID); err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	versions, err := uc.wordRepo.ListAIVersions(ctx, input.WordID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListExplanationVersionsOutput{Versions: versions}, nil
+}
+
+// RestoreExplanationVersionInput represents input for rolling back an explanation
+type RestoreExplanationVersionInput struct {
+	WordID  string
+	UserID  string
+	Version int
+}
+
+// RestoreExplanationVersionOutput represents output from rolling back an explanation
+type RestoreExplanationVersionOutput struct {
+	AIData *wordDomain.WordAIData
+}
+
+// RestoreExplanationVersion makes an earlier explanation current again
+func (uc *WordUseCase) RestoreExplanationVersion(ctx context.Context, input RestoreExplanationVersionInput) (*RestoreExplanationVersionOutput, error) {
+	if input.Version < 1 {
+		return nil, ErrBadRequest
+	}
+
+	aiData, err := uc.wordRepo.RestoreAIVersion(ctx, input.WordID, input.UserID, input.Version)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	return &RestoreExplanationVersionOutput{AIData: aiData}, nil
+}
+
+// aiVersion returns the version of the word's current explanation, or 0
+func aiVersion(word *wordDomain.Word) int {
+	if word.AIData == nil {
+		return 0
+	}
+	return word.AIData.Version
+}
+
+// optionalText trims s and returns nil if nothing is left
+func optionalText(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func validCEFRLevel(level string) bool {
+	switch level {
+	case "A1", "A2", "B1", "B2", "C1", "C2":
+		return true
+	}
+	return false
+}
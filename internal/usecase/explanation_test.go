@@ -0,0 +1,354 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/ai"
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+	"github.com/sonsonha/eng-noting/internal/domain/job"
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// explanationRepo keeps the explanation history of a single word, "w1"
+type explanationRepo struct {
+	word.WordRepository
+	versions []*word.WordAIData // oldest first; the last one is current
+}
+
+func (r *explanationRepo) current() *word.WordAIData {
+	if len(r.versions) == 0 {
+		return nil
+	}
+	return r.versions[len(r.versions)-1]
+}
+
+func (r *explanationRepo) GetByID(ctx context.Context, wordID, userID string) (*word.Word, error) {
+	if wordID != "w1" {
+		return nil, domain.ErrWordNotFound
+	}
+	w := &word.Word{ID: wordID, UserID: userID, Text: "rent"}
+	if current := r.current(); current != nil {
+		copied := *current
+		w.AIData = &copied
+	}
+	return w, nil
+}
+
+func (r *explanationRepo) StoreAIData(ctx context.Context, wordID string, baseVersion int, aiData *word.WordAIData) error {
+	if wordID != "w1" {
+		return domain.ErrWordNotFound
+	}
+	if aiVersion(&word.Word{AIData: r.current()}) != baseVersion {
+		return domain.ErrAIDataChanged
+	}
+	aiData.Version = len(r.versions) + 1
+	stored := *aiData
+	r.versions = append(r.versions, &stored)
+	return nil
+}
+
+func (r *explanationRepo) ListAIVersions(ctx context.Context, wordID, userID string) ([]*word.WordAIData, error) {
+	var versions []*word.WordAIData
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		versions = append(versions, r.versions[i])
+	}
+	return versions, nil
+}
+
+func (r *explanationRepo) RestoreAIVersion(ctx context.Context, wordID, userID string, version int) (*word.WordAIData, error) {
+	if version > len(r.versions) {
+		return nil, domain.ErrVersionNotFound
+	}
+	restored := *r.versions[version-1]
+	restored.Source = word.AISourceRestore
+	restored.RestoredFrom = &version
+	restored.Version = len(r.versions) + 1
+	r.versions = append(r.versions, &restored)
+	return &restored, nil
+}
+
+// jobQueue holds at most one job per word, as ai_jobs does
+type jobQueue struct {
+	job.JobRepository
+	mu      sync.Mutex
+	jobs    map[string]*job.Job
+	retryIn map[string]time.Duration // the delay of each job's last failure
+}
+
+func (q *jobQueue) Enqueue(ctx context.Context, j *job.Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.jobs == nil {
+		q.jobs = map[string]*job.Job{}
+	}
+	j.Status = job.StatusPending
+	q.jobs[j.WordID] = j
+	return nil
+}
+
+// Claim takes any pending job, as if it were due
+func (q *jobQueue) Claim(ctx context.Context, staleAfter time.Duration) (*job.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs {
+		if j.Status == job.StatusPending {
+			j.Status = job.StatusRunning
+			j.Attempts++
+			claimed := *j
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (q *jobQueue) Complete(ctx context.Context, jobID string) error {
+	q.update(jobID, func(j *job.Job) { j.Status = job.StatusDone })
+	return nil
+}
+
+func (q *jobQueue) Fail(ctx context.Context, jobID, lastError string, retryIn time.Duration) error {
+	q.update(jobID, func(j *job.Job) {
+		j.Status = job.StatusFailed
+		if retryIn > 0 {
+			j.Status = job.StatusPending
+		}
+		j.LastError = &lastError
+		if q.retryIn == nil {
+			q.retryIn = map[string]time.Duration{}
+		}
+		q.retryIn[jobID] = retryIn
+	})
+	return nil
+}
+
+func (q *jobQueue) update(jobID string, fn func(j *job.Job)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs {
+		if j.ID == jobID {
+			fn(j)
+		}
+	}
+}
+
+// explainer answers with a definition naming the hint it was given, or
+// with err if set
+type explainer struct {
+	ai.AIService
+	hints []string
+	err   error
+}
+
+func (e *explainer) ExplainWord(ctx context.Context, req ai.ExplainRequest) (*ai.AIExplanation, error) {
+	e.hints = append(e.hints, req.Hint)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &ai.AIExplanation{
+		Definition:    strings.TrimSpace("money paid for a home " + req.Hint),
+		ExampleGood:   "The rent is due on Friday.",
+		PartOfSpeech:  "noun",
+		CEFRLevel:     "B1",
+		Model:         "test-model",
+		PromptVersion: "explain-test",
+	}, nil
+}
+
+// cachedDistractors reports distractors as already cached
+type cachedDistractors struct{ exercise.DistractorRepository }
+
+func (cachedDistractors) Cached(ctx context.Context, wordID string) ([]string, error) {
+	return []string{"lease"}, nil
+}
+
+type explanationFixture struct {
+	words  *explanationRepo
+	jobs   *jobQueue
+	ai     *explainer
+	uc     *WordUseCase
+	worker *ExplanationWorker
+}
+
+// newExplanationFixture starts with one AI explanation that has a translation
+func newExplanationFixture() *explanationFixture {
+	translation := "tiền thuê"
+	words := &explanationRepo{versions: []*word.WordAIData{{
+		WordID:      "w1",
+		Definition:  "money paid for a home",
+		ExampleGood: "The rent is due.",
+		Translation: &translation,
+		Version:     1,
+		Source:      word.AISourceAI,
+	}}}
+	jobs := &jobQueue{}
+	explain := &explainer{}
+	return &explanationFixture{
+		words:  words,
+		jobs:   jobs,
+		ai:     explain,
+		uc:     NewWordUseCase(words, jobs, nil, nil, nil),
+		worker: NewExplanationWorker(jobs, words, cachedDistractors{}, explain, ExplanationWorkerConfig{}),
+	}
+}
+
+// runJob claims the queued job and processes it as a worker would
+func (f *explanationFixture) runJob(t *testing.T) *job.Job {
+	t.Helper()
+	j, _ := f.jobs.Claim(context.Background(), time.Minute)
+	if j == nil {
+		t.Fatal("expected a pending job")
+	}
+	f.worker.process(context.Background(), j)
+	return f.jobs.jobs[j.WordID]
+}
+
+func TestEditExplanationAddsManualVersion(t *testing.T) {
+	f := newExplanationFixture()
+	definition := "  the money a tenant pays  "
+
+	out, err := f.uc.EditExplanation(context.Background(), EditExplanationInput{WordID: "w1", UserID: "u1", Definition: &definition})
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+
+	if out.AIData.Version != 2 || out.AIData.Source != word.AISourceManual {
+		t.Fatalf("expected manual version 2, got version %d from %q", out.AIData.Version, out.AIData.Source)
+	}
+	current := f.words.current()
+	if current.Definition != "the money a tenant pays" {
+		t.Errorf("definition = %q", current.Definition)
+	}
+	// Fields that were not edited carry over
+	if current.ExampleGood != "The rent is due." || current.Translation == nil || *current.Translation != "tiền thuê" {
+		t.Errorf("expected the example and translation kept, got %+v", current)
+	}
+
+	versions, err := f.uc.ListExplanationVersions(context.Background(), WordRefInput{WordID: "w1", UserID: "u1"})
+	if err != nil {
+		t.Fatalf("versions: %v", err)
+	}
+	if len(versions.Versions) != 2 || versions.Versions[0].Version != 2 || versions.Versions[1].Version != 1 {
+		t.Errorf("expected versions 2 and 1, newest first, got %+v", versions.Versions)
+	}
+}
+
+func TestEditExplanationRejectsInvalidFields(t *testing.T) {
+	f := newExplanationFixture()
+	empty, level := " ", "D1"
+
+	for name, input := range map[string]EditExplanationInput{
+		"empty definition": {WordID: "w1", UserID: "u1", Definition: &empty},
+		"unknown level":    {WordID: "w1", UserID: "u1", CEFRLevel: &level},
+	} {
+		if _, err := f.uc.EditExplanation(context.Background(), input); err != ErrBadRequest {
+			t.Errorf("%s: expected ErrBadRequest, got %v", name, err)
+		}
+	}
+	if len(f.words.versions) != 1 {
+		t.Errorf("expected no new version, got %d", len(f.words.versions))
+	}
+}
+
+func TestRestoreExplanationVersion(t *testing.T) {
+	f := newExplanationFixture()
+	definition, translation := "the money a tenant pays", ""
+	if _, err := f.uc.EditExplanation(context.Background(), EditExplanationInput{
+		WordID: "w1", UserID: "u1", Definition: &definition, Translation: &translation,
+	}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+
+	out, err := f.uc.RestoreExplanationVersion(context.Background(), RestoreExplanationVersionInput{WordID: "w1", UserID: "u1", Version: 1})
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	if out.AIData.Version != 3 || out.AIData.RestoredFrom == nil || *out.AIData.RestoredFrom != 1 {
+		t.Fatalf("expected version 3 restored from 1, got %+v", out.AIData)
+	}
+	if out.AIData.Definition != "money paid for a home" || out.AIData.Translation == nil || *out.AIData.Translation != "tiền thuê" {
+		t.Errorf("expected version 1's content back, got %+v", out.AIData)
+	}
+
+	for _, version := range []int{0, 9} {
+		_, err := f.uc.RestoreExplanationVersion(context.Background(), RestoreExplanationVersionInput{WordID: "w1", UserID: "u1", Version: version})
+		if err != ErrBadRequest && err != ErrNotFound {
+			t.Errorf("version %d: expected a rejection, got %v", version, err)
+		}
+	}
+}
+
+func TestRegenerateExplanationWithHint(t *testing.T) {
+	f := newExplanationFixture()
+
+	if err := f.uc.RegenerateExplanation(context.Background(), RegenerateExplanationInput{WordID: "w1", UserID: "u1", Hint: " the verb "}); err != nil {
+		t.Fatalf("regenerate: %v", err)
+	}
+	if j := f.jobs.jobs["w1"]; j.BaseVersion != 1 || j.Hint == nil || *j.Hint != "the verb" {
+		t.Fatalf("expected a job for version 1 with the cleaned hint, got %+v", j)
+	}
+
+	j := f.runJob(t)
+
+	if j.Status != job.StatusDone {
+		t.Errorf("job status = %q", j.Status)
+	}
+	if len(f.ai.hints) != 1 || f.ai.hints[0] != "the verb" {
+		t.Errorf("expected the hint passed to the AI, got %q", f.ai.hints)
+	}
+	current := f.words.current()
+	if current.Version != 2 || current.Source != word.AISourceAI || current.Hint == nil || *current.Hint != "the verb" {
+		t.Fatalf("expected AI version 2 recording the hint, got %+v", current)
+	}
+	// The AI does not translate: the translation carries over
+	if current.Translation == nil || *current.Translation != "tiền thuê" {
+		t.Errorf("expected the translation kept, got %v", current.Translation)
+	}
+
+	long := strings.Repeat("x", maxHintLength+1)
+	if err := f.uc.RegenerateExplanation(context.Background(), RegenerateExplanationInput{WordID: "w1", UserID: "u1", Hint: long}); err != ErrBadRequest {
+		t.Errorf("long hint: expected ErrBadRequest, got %v", err)
+	}
+}
+
+func TestRegenerateDoesNotOverwriteLaterChanges(t *testing.T) {
+	definition := "the money a tenant pays"
+	changes := map[string]func(f *explanationFixture) error{
+		"manual edit": func(f *explanationFixture) error {
+			_, err := f.uc.EditExplanation(context.Background(), EditExplanationInput{WordID: "w1", UserID: "u1", Definition: &definition})
+			return err
+		},
+		"restore": func(f *explanationFixture) error {
+			_, err := f.uc.RestoreExplanationVersion(context.Background(), RestoreExplanationVersionInput{WordID: "w1", UserID: "u1", Version: 1})
+			return err
+		},
+	}
+
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			f := newExplanationFixture()
+			if err := f.uc.RegenerateExplanation(context.Background(), RegenerateExplanationInput{WordID: "w1", UserID: "u1"}); err != nil {
+				t.Fatalf("regenerate: %v", err)
+			}
+			// The user changes the explanation while the job waits
+			if err := change(f); err != nil {
+				t.Fatalf("change: %v", err)
+			}
+			kept := *f.words.current()
+
+			j := f.runJob(t)
+
+			if j.Status != job.StatusDone {
+				t.Errorf("expected the stale job finished, got %q", j.Status)
+			}
+			if len(f.words.versions) != 2 || f.words.current().Definition != kept.Definition {
+				t.Errorf("expected the user's version kept, got %d versions, current %+v", len(f.words.versions), f.words.current())
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/ai"
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+	"github.com/sonsonha/eng-noting/internal/domain/job"
+	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// ExplanationWorkerConfig configures the explanation worker pool
+type ExplanationWorkerConfig struct {
+	// Concurrency is the number of jobs processed at the same time
+	Concurrency int
+	// PollInterval is how long an idle worker waits before looking for jobs again
+	PollInterval time.Duration
+	// StaleAfter is how long a job may stay running before it is assumed
+	// abandoned (e.g. the process died) and claimed again. It must be longer
+	// than the slowest AI call, retries included.
+	StaleAfter time.Duration
+}
+
+// ExplanationWorker generates queued AI explanations in the background
+type ExplanationWorker struct {
+	jobRepo        job.JobRepository
+	wordRepo       wordDomain.WordRepository
+	distractorRepo exercise.DistractorRepository
+	aiSvc          ai.AIService
+	cfg            ExplanationWorkerConfig
+}
+
+// NewExplanationWorker creates a new ExplanationWorker
+func NewExplanationWorker(
+	jobRepo job.JobRepository,
+	wordRepo wordDomain.WordRepository,
+	distractorRepo exercise.DistractorRepository,
+	aiSvc ai.AIService,
+	cfg ExplanationWorkerConfig,
+) *ExplanationWorker {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = 10 * time.Minute
+	}
+
+	return &ExplanationWorker{
+		jobRepo:        jobRepo,
+		wordRepo:       wordRepo,
+		distractorRepo: distractorRepo,
+		aiSvc:          aiSvc,
+		cfg:            cfg,
+	}
+}
+
+// Run processes jobs until ctx is cancelled. It returns once every job
+// that was in progress has been finished and recorded.
+func (w *ExplanationWorker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *ExplanationWorker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		j, err := w.jobRepo.Claim(ctx, w.cfg.StaleAfter)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("[ERROR] failed to claim AI job: %v", err)
+		}
+
+		if j == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+
+		// A claimed job is finished even during shutdown, so that it is
+		// not left running until it goes stale
+		w.process(context.WithoutCancel(ctx), j)
+	}
+}
+
+// process generates and stores one explanation, recording the outcome on the job
+func (w *ExplanationWorker) process(ctx context.Context, j *job.Job) {
+	word, err := w.wordRepo.GetByID(ctx, j.WordID, j.UserID)
+	if errors.Is(err, domain.ErrWordNotFound) {
+		// The word was deleted and its job with it
+		return
+	}
+	if err != nil {
+		w.fail(ctx, j, err)
+		return
+	}
+
+	req := ai.ExplainRequest{Word: word.Text, Contexts: word.ContextSentences()}
+	if j.Hint != nil {
+		req.Hint = *j.Hint
+	}
+
+	exp, err := w.aiSvc.ExplainWord(ctx, req)
+	if err != nil {
+		w.fail(ctx, j, err)
+		return
+	}
+
+	aiData := &wordDomain.WordAIData{
+		WordID:        word.ID,
+		Definition:    exp.Definition,
+		ExampleGood:   exp.ExampleGood,
+		ExampleBad:    &exp.ExampleBad,
+		PartOfSpeech:  &exp.PartOfSpeech,
+		CEFRLevel:     &exp.CEFRLevel,
+		GeneratedAt:   time.Now(),
+		Source:        wordDomain.AISourceAI,
+		PromptVersion: &exp.PromptVersion,
+		Model:         &exp.Model,
+		Hint:          j.Hint,
+	}
+	// The AI does not translate: the current translation carries over
+	if word.AIData != nil {
+		aiData.Translation = word.AIData.Translation
+	}
+	err = w.wordRepo.StoreAIData(ctx, word.ID, j.BaseVersion, aiData)
+	if errors.Is(err, domain.ErrWordNotFound) {
+		return
+	}
+	if errors.Is(err, domain.ErrAIDataChanged) {
+		// The explanation was edited or restored after the job was queued;
+		// the user's choice stands
+		log.Printf("[INFO] AI job %s for word %s dropped: explanation changed since it was queued", j.ID, j.WordID)
+		if err := w.jobRepo.Complete(ctx, j.ID); err != nil {
+			log.Printf("[ERROR] failed to complete AI job %s: %v", j.ID, err)
+		}
+		return
+	}
+	if err != nil {
+		w.fail(ctx, j, err)
+		return
+	}
+
+	if err := w.jobRepo.Complete(ctx, j.ID); err != nil {
+		log.Printf("[ERROR] failed to complete AI job %s: %v", j.ID, err)
+	}
+
+	// Prepare multiple-choice distractors while we are at it, so that
+	// sessions do not have to wait for the AI. Failures are not fatal:
+	// they are generated on demand instead.
+	if cached, err := w.distractorRepo.Cached(ctx, word.ID); err == nil && len(cached) == 0 {
+		word.AIData = aiData
+		cacheDistractors(ctx, w.aiSvc, w.distractorRepo, word)
+	}
+}
+
+// fail records a failed attempt and schedules a retry while attempts remain
+func (w *ExplanationWorker) fail(ctx context.Context, j *job.Job, cause error) {
+	var retryIn time.Duration
+	if j.CanRetry() {
+		retryIn = job.Backoff(j.Attempts)
+	}
+
+	log.Printf("[WARN] AI job %s for word %s failed (attempt %d/%d): %v", j.ID, j.WordID, j.Attempts, j.MaxAttempts, cause)
+
+	if err := w.jobRepo.Fail(ctx, j.ID, cause.Error(), retryIn); err != nil {
+		log.Printf("[ERROR] failed to record AI job failure %s: %v", j.ID, err)
+	}
+}
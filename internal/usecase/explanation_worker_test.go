@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/ai"
+	"github.com/sonsonha/eng-noting/internal/domain/job"
+)
+
+func TestWorkerRetriesFailedJobsWithBackoff(t *testing.T) {
+	f := newExplanationFixture()
+	f.ai.err = errors.New("provider unavailable")
+	if err := f.uc.RegenerateExplanation(context.Background(), RegenerateExplanationInput{WordID: "w1", UserID: "u1"}); err != nil {
+		t.Fatalf("regenerate: %v", err)
+	}
+
+	// Every failed attempt but the last is retried after a longer delay
+	for attempt := 1; attempt < maxExplanationAttempts; attempt++ {
+		j := f.runJob(t)
+		if j.Status != job.StatusPending || j.LastError == nil || *j.LastError != "provider unavailable" {
+			t.Fatalf("attempt %d: expected a retry recording the error, got %+v", attempt, j)
+		}
+		if got := f.jobs.retryIn[j.ID]; got != job.Backoff(attempt) {
+			t.Fatalf("attempt %d: expected a retry in %v, got %v", attempt, job.Backoff(attempt), got)
+		}
+	}
+
+	j := f.runJob(t)
+	if j.Status != job.StatusFailed || f.jobs.retryIn[j.ID] != 0 {
+		t.Fatalf("expected the job to fail for good after %d attempts, got %+v", maxExplanationAttempts, j)
+	}
+	if len(f.words.versions) != 1 {
+		t.Errorf("expected the explanation kept, got %d versions", len(f.words.versions))
+	}
+}
+
+func TestWorkerRecoversAfterFailedAttempt(t *testing.T) {
+	f := newExplanationFixture()
+	f.ai.err = errors.New("timeout")
+	if err := f.uc.RegenerateExplanation(context.Background(), RegenerateExplanationInput{WordID: "w1", UserID: "u1"}); err != nil {
+		t.Fatalf("regenerate: %v", err)
+	}
+	f.runJob(t)
+
+	f.ai.err = nil
+	j := f.runJob(t)
+
+	if j.Status != job.StatusDone || j.Attempts != 2 {
+		t.Fatalf("expected the second attempt to succeed, got %+v", j)
+	}
+	if f.words.current().Version != 2 {
+		t.Errorf("expected a new version, got %d", f.words.current().Version)
+	}
+}
+
+// blockingExplainer answers only once released, telling when it was asked
+type blockingExplainer struct {
+	explainer
+	asked   chan struct{}
+	release chan struct{}
+}
+
+func (e *blockingExplainer) ExplainWord(ctx context.Context, req ai.ExplainRequest) (*ai.AIExplanation, error) {
+	close(e.asked)
+	<-e.release
+	return e.explainer.ExplainWord(ctx, req)
+}
+
+func TestWorkerFinishesClaimedJobOnShutdown(t *testing.T) {
+	f := newExplanationFixture()
+	slow := &blockingExplainer{asked: make(chan struct{}), release: make(chan struct{})}
+	worker := NewExplanationWorker(f.jobs, f.words, cachedDistractors{}, slow, ExplanationWorkerConfig{PollInterval: time.Millisecond})
+	if err := f.uc.RegenerateExplanation(context.Background(), RegenerateExplanationInput{WordID: "w1", UserID: "u1"}); err != nil {
+		t.Fatalf("regenerate: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		worker.Run(ctx)
+		close(stopped)
+	}()
+
+	// Shut down while the AI is generating
+	<-slow.asked
+	cancel()
+	select {
+	case <-stopped:
+		t.Fatal("expected Run to wait for the claimed job")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(slow.release)
+	<-stopped
+
+	if j := f.jobs.jobs["w1"]; j.Status != job.StatusDone {
+		t.Fatalf("expected the job finished, got %+v", j)
+	}
+	if f.words.current().Version != 2 {
+		t.Errorf("expected the explanation stored, got version %d", f.words.current().Version)
+	}
+}
@@ -3,8 +3,8 @@ package usecase
 import (
 	"time"
 
-	"github.com/sonsonha/eng-noting/internal/domain/word"
 	"github.com/sonsonha/eng-noting/internal/domain/mps"
+	"github.com/sonsonha/eng-noting/internal/domain/word"
 )
 
 // MPSService handles Memory Priority Score calculations
@@ -27,21 +27,24 @@ type CalculateMPSOutput struct {
 
 // CalculateMPS calculates the Memory Priority Score for a word
 func (s *MPSService) CalculateMPS(input CalculateMPSInput) (CalculateMPSOutput, string) {
-	// Convert domain.WordStats to mps.WordStats
-	stats := mps.WordStats{
-		DaysSinceLastReview: s.daysSinceLastReview(input.WordStats.LastReviewedAt),
-		AccuracyRate:         input.WordStats.AccuracyRate,
-		TotalReviews:         input.WordStats.TotalReviews,
-		RecentFailures:       input.WordStats.RecentFailures,
-		RecentReviews:        input.WordStats.RecentReviews,
-		Confidence:           input.WordStats.Confidence,
-		FrequencyScore:       input.WordStats.FrequencyScore,
-	}
-
-	score, reason := mps.CalculateMPS(stats)
+	score, reason := mps.CalculateMPS(s.Stats(input.WordStats))
 	return CalculateMPSOutput{Score: score}, reason
 }
 
+// Stats converts domain.WordStats to mps.WordStats
+func (s *MPSService) Stats(stats word.WordStats) mps.WordStats {
+	return mps.WordStats{
+		DaysSinceLastReview: s.daysSinceLastReview(stats.LastReviewedAt),
+		AccuracyRate:        stats.AccuracyRate,
+		TotalReviews:        stats.TotalReviews,
+		RecentFailures:      stats.RecentFailures,
+		RecentReviews:       stats.RecentReviews,
+		RecentHesitant:      stats.RecentHesitant,
+		Confidence:          stats.Confidence,
+		FrequencyScore:      stats.FrequencyScore,
+	}
+}
+
 // daysSinceLastReview calculates days since last review
 func (s *MPSService) daysSinceLastReview(lastReviewedAt *string) int {
 	if lastReviewedAt == nil || *lastReviewedAt == "" {
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
+	"github.com/sonsonha/eng-noting/pkg/cursor"
+)
+
+const (
+	// DefaultPageSize is used when a listing does not ask for a page size
+	DefaultPageSize = 50
+	// MaxPageSize is the largest page a listing returns
+	MaxPageSize = 200
+)
+
+// pageSize validates a requested page size; zero means the default
+func pageSize(limit int) (int, error) {
+	if limit == 0 {
+		return DefaultPageSize, nil
+	}
+	if limit < 0 || limit > MaxPageSize {
+		return 0, ErrBadRequest
+	}
+	return limit, nil
+}
+
+// wordCursorToken is the serialized form of a word.Cursor
+type wordCursorToken struct {
+	Sort       string     `json:"s"`
+	Descending bool       `json:"d"`
+	Key        string     `json:"k,omitempty"`
+	CreatedAt  *time.Time `json:"t,omitempty"`
+	ID         string     `json:"id"`
+}
+
+func encodeWordCursor(c *wordDomain.Cursor) string {
+	if c == nil {
+		return ""
+	}
+	t := wordCursorToken{
+		Sort:       string(c.Sort),
+		Descending: c.Descending,
+		Key:        c.Key,
+		ID:         c.ID,
+	}
+	if c.Sort == wordDomain.SortCreatedAt {
+		t.CreatedAt = &c.CreatedAt
+	}
+	return cursor.Encode(t)
+}
+
+// decodeWordCursor reads a token issued for the same sort and direction as
+// filter. A token from another ordering would silently skip or repeat rows,
+// so it is rejected like a malformed one.
+func decodeWordCursor(token string, filter wordDomain.ListFilter) (*wordDomain.Cursor, error) {
+	var t wordCursorToken
+	if err := cursor.Decode(token, &t); err != nil {
+		return nil, ErrBadRequest
+	}
+
+	c := &wordDomain.Cursor{
+		Sort:       wordDomain.SortField(t.Sort),
+		Descending: t.Descending,
+		Key:        t.Key,
+		ID:         t.ID,
+	}
+	if c.Sort != filter.Sort || c.Descending != filter.Descending {
+		return nil, ErrBadRequest
+	}
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return nil, ErrBadRequest
+	}
+
+	// The key is cast back to the sort column's type by the database,
+	// so check it parses before it gets there
+	switch c.Sort {
+	case wordDomain.SortPriority, wordDomain.SortAccuracy:
+		if _, err := strconv.ParseFloat(c.Key, 64); err != nil {
+			return nil, ErrBadRequest
+		}
+	case wordDomain.SortCreatedAt:
+		if t.CreatedAt == nil {
+			return nil, ErrBadRequest
+		}
+		c.CreatedAt = *t.CreatedAt
+	}
+
+	return c, nil
+}
+
+// sessionCursorToken is the serialized form of a session.Cursor
+type sessionCursorToken struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+func encodeSessionCursor(c *session.Cursor) string {
+	if c == nil {
+		return ""
+	}
+	return cursor.Encode(sessionCursorToken{CreatedAt: c.CreatedAt, ID: c.ID})
+}
+
+func decodeSessionCursor(token string) (*session.Cursor, error) {
+	var t sessionCursorToken
+	if err := cursor.Decode(token, &t); err != nil {
+		return nil, ErrBadRequest
+	}
+	if _, err := uuid.Parse(t.ID); err != nil {
+		return nil, ErrBadRequest
+	}
+	return &session.Cursor{CreatedAt: t.CreatedAt, ID: t.ID}, nil
+}
+
+// reviewCursorToken is the serialized form of a review.Cursor
+type reviewCursorToken struct {
+	ReviewedAt time.Time `json:"t"`
+	ID         string    `json:"id"`
+}
+
+func encodeReviewCursor(c *review.Cursor) string {
+	if c == nil {
+		return ""
+	}
+	return cursor.Encode(reviewCursorToken{ReviewedAt: c.ReviewedAt, ID: c.ID})
+}
+
+func decodeReviewCursor(token string) (*review.Cursor, error) {
+	var t reviewCursorToken
+	if err := cursor.Decode(token, &t); err != nil {
+		return nil, ErrBadRequest
+	}
+	if _, err := uuid.Parse(t.ID); err != nil {
+		return nil, ErrBadRequest
+	}
+	return &review.Cursor{ReviewedAt: t.ReviewedAt, ID: t.ID}, nil
+}
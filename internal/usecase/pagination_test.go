@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
+	"github.com/sonsonha/eng-noting/pkg/cursor"
+)
+
+func TestWordCursorKeepsCreationTime(t *testing.T) {
+	filter := wordDomain.ListFilter{Sort: wordDomain.SortCreatedAt, Descending: true}
+	createdAt := time.Date(2024, 1, 15, 10, 30, 0, 123456000, time.UTC)
+	want := &wordDomain.Cursor{
+		Sort:       filter.Sort,
+		Descending: true,
+		CreatedAt:  createdAt,
+		ID:         "3f1c2a4e-8d6b-4c1e-9a7f-2b5d8e0c6a91",
+	}
+
+	got, err := decodeWordCursor(encodeWordCursor(want), filter)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if *got != *want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestWordCursorRejectsRenderedTime(t *testing.T) {
+	filter := wordDomain.ListFilter{Sort: wordDomain.SortCreatedAt}
+
+	// A key rendered by the database, as earlier tokens carried it
+	token := cursor.Encode(wordCursorToken{
+		Sort: string(filter.Sort),
+		Key:  "2024-01-15 10:30:00.123456",
+		ID:   "3f1c2a4e-8d6b-4c1e-9a7f-2b5d8e0c6a91",
+	})
+	if _, err := decodeWordCursor(token, filter); err != ErrBadRequest {
+		t.Fatalf("expected ErrBadRequest, got %v", err)
+	}
+}
@@ -37,16 +37,21 @@ func (uc *RebuildStatsUseCase) RebuildStats(ctx context.Context) error {
 			word_id,
 			total_reviews,
 			correct_reviews,
+			score_sum,
 			last_reviewed_at,
-			accuracy_rate
+			accuracy_rate,
+			memory_score
 		)
 		SELECT
 			r.word_id,
 			COUNT(*) AS total_reviews,
 			COUNT(*) FILTER (WHERE r.result = true),
+			SUM(r.score),
 			MAX(r.reviewed_at),
-			COUNT(*) FILTER (WHERE r.result = true)::float / COUNT(*)
+			SUM(r.score) / COUNT(*),
+			COALESCE(MAX(s.stability), 0)
 		FROM reviews r
+		LEFT JOIN word_schedules s ON s.word_id = r.word_id
 		GROUP BY r.word_id
 	`)
 	if err != nil {
@@ -2,67 +2,446 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
+	"github.com/sonsonha/eng-noting/internal/domain/grading"
 	"github.com/sonsonha/eng-noting/internal/domain/review"
+	"github.com/sonsonha/eng-noting/internal/domain/schedule"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
 	"github.com/sonsonha/eng-noting/internal/domain/word"
 )
 
 // ReviewUseCase handles review-related business logic
 type ReviewUseCase struct {
-	reviewRepo review.ReviewRepository
-	wordRepo   word.WordRepository
+	reviewRepo  review.ReviewRepository
+	wordRepo    word.WordRepository
+	clozeRepo   exercise.ClozeRepository
+	sessionRepo session.SessionRepository
+	expiry      session.Expiry
+	scheduler   *ReviewScheduler
+	txManager   TxManager
 }
 
-// NewReviewUseCase creates a new ReviewUseCase
-func NewReviewUseCase(reviewRepo review.ReviewRepository, wordRepo word.WordRepository) *ReviewUseCase {
+// NewReviewUseCase creates a new ReviewUseCase. Answering a session's
+// item keeps the session as long as expiry says.
+func NewReviewUseCase(
+	reviewRepo review.ReviewRepository,
+	wordRepo word.WordRepository,
+	clozeRepo exercise.ClozeRepository,
+	sessionRepo session.SessionRepository,
+	expiry session.Expiry,
+	scheduler *ReviewScheduler,
+	txManager TxManager,
+) *ReviewUseCase {
 	return &ReviewUseCase{
-		reviewRepo: reviewRepo,
-		wordRepo:   wordRepo,
+		reviewRepo:  reviewRepo,
+		wordRepo:    wordRepo,
+		clozeRepo:   clozeRepo,
+		sessionRepo: sessionRepo,
+		expiry:      expiry,
+		scheduler:   scheduler,
+		txManager:   txManager,
 	}
 }
 
 // SubmitReviewInput represents input for submitting a review
 type SubmitReviewInput struct {
-	UserID     string
-	WordID     string
-	Result     bool
-	ReviewType string
+	UserID         string
+	WordID         string
+	Result         bool    // self-reported outcome, used only when there is no answer to grade
+	Answer         *string // the option picked ("mcq", required) or the text typed ("typing", "fill_blank")
+	Quality        *string // the learner's rating: "again", "hard", "good" or "easy"
+	LatencyMs      *int    // time taken to answer
+	ReviewType     string
+	SessionID      string // the session whose current item is answered, if any
+	IdempotencyKey string // the client's key for the submission, if any; retries with it are recorded once
+}
+
+// SessionProgress is where a session stands after one of its items was
+// answered
+type SessionProgress struct {
+	Position int // the item to continue from
+	Done     bool
 }
 
 // SubmitReviewOutput represents output from submitting a review
 type SubmitReviewOutput struct {
-	Success bool
+	Success  bool
+	Correct  bool             // whether the answer was right; a right answer rated "again" is recorded as a miss
+	Score    float64          // 0.0 - 1.0, partial credit included
+	Match    string           // how a graded answer matched, see grading.Match
+	Expected string           // the right answer, for graded reviews
+	Session  *SessionProgress // for reviews made in a session
+	Replayed bool             // the idempotency key was used before, and nothing was recorded again
 }
 
-// SubmitReview submits a review for a word
+// SubmitReview submits a review for a word. A review made in a session
+// must answer the session's current item, with its review type; the
+// session then moves on to the next item. Each item is answered once.
+//
+// A submission with an idempotency key that was used before is a retry:
+// it is answered as the first one was and not recorded again. A retry
+// that differs from the first submission is a conflict.
 func (uc *ReviewUseCase) SubmitReview(ctx context.Context, input SubmitReviewInput) (*SubmitReviewOutput, error) {
+	rev, output, err := uc.grade(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	replayed, err := uc.replay(ctx, rev, output)
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		return output, nil
+	}
+
+	var sess *session.Session
+	if input.SessionID != "" {
+		sess, err = loadSession(ctx, uc.sessionRepo, input.UserID, input.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		item := sess.Current()
+		if item == nil || item.Match != nil || item.WordID != input.WordID || item.ReviewType != input.ReviewType {
+			return nil, ErrConflict
+		}
+		rev.SessionID = &sess.ID
+	}
+
+	// The review, its statistics and its schedule are recorded together
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.reviewRepo.Create(ctx, rev); err != nil {
+			return err
+		}
+
+		// Update review statistics
+		if err := uc.reviewRepo.UpdateStats(ctx, rev.WordID, review.Outcome{Correct: rev.Result, Score: rev.Score}); err != nil {
+			return err
+		}
+
+		rating := schedule.RatingFor(rev.Result, rev.Hesitant, rev.Quality)
+		return uc.scheduler.Record(ctx, input.UserID, input.WordID, rating, rev.ReviewedAt)
+	})
+	if errors.Is(err, domain.ErrReviewKeyUsed) {
+		// A concurrent retry was recorded since the key was looked up
+		replayed, err := uc.replay(ctx, rev, output)
+		if err != nil {
+			return nil, err
+		}
+		if !replayed {
+			return nil, ErrConflict
+		}
+		return output, nil
+	}
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	if sess != nil {
+		output.Session, err = uc.moveOn(ctx, sess)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return output, nil
+}
+
+// grade checks that the word belongs to the user and grades the answer,
+// returning the review to record
+func (uc *ReviewUseCase) grade(ctx context.Context, input SubmitReviewInput) (*review.Review, *SubmitReviewOutput, error) {
 	// Verify word belongs to user
 	word, err := uc.wordRepo.GetByID(ctx, input.WordID, input.UserID)
 	if err != nil {
-		return nil, err
+		return nil, nil, mapDomainError(err)
 	}
 
 	if word.UserID != input.UserID {
-		return nil, ErrForbidden
+		return nil, nil, ErrForbidden
+	}
+
+	var quality *review.Quality
+	if input.Quality != nil {
+		q, ok := review.ParseQuality(*input.Quality)
+		if !ok {
+			return nil, nil, ErrBadRequest
+		}
+		quality = &q
+	}
+	var latency *time.Duration
+	if input.LatencyMs != nil {
+		d, ok := review.ParseLatency(*input.LatencyMs)
+		if !ok {
+			return nil, nil, ErrBadRequest
+		}
+		latency = &d
+	}
+
+	// A blank is graded against the form the word takes in its sentence
+	// ("ran"), falling back to the word itself ("run")
+	accepted := []string{word.Text}
+	if input.ReviewType == "fill_blank" && input.Answer != nil {
+		cloze, err := uc.clozeRepo.Get(ctx, word.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cloze != nil && cloze.Text == word.Text {
+			accepted = []string{cloze.Answer, word.Text}
+		}
+	}
+
+	output, err := gradeReview(input, word, accepted)
+	if err != nil {
+		return nil, nil, err
 	}
+	outcome := review.Outcome{Correct: output.Correct, Score: output.Score}.WithQuality(quality)
 
-	review := &review.Review{
+	rev := &review.Review{
 		ID:         uuid.NewString(),
 		WordID:     input.WordID,
 		UserID:     input.UserID,
-		Result:     input.Result,
+		Result:     outcome.Correct,
+		Score:      outcome.Score,
+		Answer:     input.Answer,
+		Quality:    quality,
+		Latency:    latency,
+		Hesitant:   outcome.Correct && review.Hesitant(input.ReviewType, quality, latency),
 		ReviewType: input.ReviewType,
+		ReviewedAt: time.Now(),
+	}
+	if input.IdempotencyKey != "" {
+		rev.IdempotencyKey = &input.IdempotencyKey
+	}
+	return rev, output, nil
+}
+
+// replay reports whether the idempotency key of the graded review rev was
+// used before, and then answers output as the first submission was. A
+// key used for another word, review type, answer, quality or
+// self-reported result is a conflict.
+func (uc *ReviewUseCase) replay(ctx context.Context, rev *review.Review, output *SubmitReviewOutput) (bool, error) {
+	if rev.IdempotencyKey == nil {
+		return false, nil
+	}
+	prev, err := uc.reviewRepo.GetByKey(ctx, rev.UserID, *rev.IdempotencyKey)
+	if err != nil || prev == nil {
+		return false, err
+	}
+	if !sameSubmission(prev, rev) {
+		return false, ErrConflict
 	}
 
-	if err := uc.reviewRepo.Create(ctx, review); err != nil {
+	// A right answer rated "again" was answered as graded, and recorded
+	// as a miss; the same answer grades the same way again
+	if prev.Quality == nil || *prev.Quality != review.QualityAgain {
+		output.Correct = prev.Result
+		output.Score = prev.Score
+	}
+	output.Replayed = true
+	if prev.SessionID != nil {
+		// The session may have expired since; it then has no progress to report
+		sess, err := uc.sessionRepo.Get(ctx, *prev.SessionID)
+		if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+			return false, err
+		}
+		if sess != nil {
+			output.Session = &SessionProgress{Position: sess.Index, Done: sess.Done()}
+		}
+	}
+	return true, nil
+}
+
+// rebuild recomputes the statistics and schedule of a word from its full
+// history, together
+func (uc *ReviewUseCase) rebuild(ctx context.Context, userID, wordID string) error {
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.reviewRepo.RecomputeStats(ctx, wordID); err != nil {
+			return err
+		}
+		history, err := uc.reviewRepo.ListByWord(ctx, wordID)
+		if err != nil {
+			return err
+		}
+		return uc.scheduler.Rebuild(ctx, userID, wordID, history)
+	})
+}
+
+// sameSubmission reports whether rev was submitted as prev was
+func sameSubmission(prev, rev *review.Review) bool {
+	if prev.WordID != rev.WordID || prev.ReviewType != rev.ReviewType {
+		return false
+	}
+	if !equalPtr(prev.Answer, rev.Answer) || !equalPtr(prev.Quality, rev.Quality) {
+		return false
+	}
+	// Without an answer to grade, the result is the client's own
+	return prev.Answer != nil || prev.Result == rev.Result
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// moveOn advances a session past the item just answered. A session that
+// another request already moved on is left where it is.
+func (uc *ReviewUseCase) moveOn(ctx context.Context, sess *session.Session) (*SessionProgress, error) {
+	index, err := uc.sessionRepo.Advance(ctx, sess.ID, sess.Index, uc.expiry.At(sess, sess.Index+1))
+	if errors.Is(err, domain.ErrSessionMoved) {
+		index = sess.Index + 1
+	} else if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &SessionProgress{Position: index, Done: index >= len(sess.Items)}, nil
+}
+
+// gradeReview grades the submitted answer on the server. Multiple-choice
+// and typed answers must be sent, and typed ones are graded against the
+// accepted answers; for other review types the self-reported result is
+// recorded as is.
+func gradeReview(input SubmitReviewInput, w *word.Word, accepted []string) (*SubmitReviewOutput, error) {
+	output := &SubmitReviewOutput{Success: true}
+
+	switch {
+	case input.ReviewType == "mcq":
+		if input.Answer == nil {
+			return nil, ErrBadRequest
+		}
+		outcome := review.BinaryOutcome(exercise.CheckChoice(*input.Answer, w.Text))
+		output.Correct = outcome.Correct
+		output.Score = outcome.Score
+		output.Match = string(grading.MatchWrong)
+		if outcome.Correct {
+			output.Match = string(grading.MatchExact)
+		}
+		output.Expected = w.Text
+
+	case input.ReviewType == "typing" || input.ReviewType == "fill_blank":
+		if input.Answer == nil {
+			return nil, ErrBadRequest
+		}
+		result := grading.Grade(*input.Answer, accepted...)
+		output.Correct = result.Correct
+		output.Score = result.Score
+		output.Match = string(result.Match)
+		output.Expected = result.Expected
+
+	default:
+		outcome := review.BinaryOutcome(input.Result)
+		output.Correct = outcome.Correct
+		output.Score = outcome.Score
+	}
+
+	return output, nil
+}
+
+// SubmitMatchInput represents input for submitting a matching board
+type SubmitMatchInput struct {
+	UserID    string
+	SessionID string            // the session whose current item is the board
+	Pairs     map[string]string // word ID -> definition ID
+}
+
+// MatchPairResult is the outcome for one word of a matching board
+type MatchPairResult struct {
+	WordID   string
+	Text     string
+	Correct  bool
+	Expected string // the word's definition
+}
+
+// SubmitMatchOutput represents output from submitting a matching board
+type SubmitMatchOutput struct {
+	Success bool
+	Results []MatchPairResult // in board order
+	Correct int
+	Session *SessionProgress
+}
+
+// SubmitMatch grades the matching board that is the current item of a
+// session, records one "match" review per word on it and moves the
+// session on. Words deleted since the board was built are skipped.
+func (uc *ReviewUseCase) SubmitMatch(ctx context.Context, input SubmitMatchInput) (*SubmitMatchOutput, error) {
+	sess, err := loadSession(ctx, uc.sessionRepo, input.UserID, input.SessionID)
+	if err != nil {
 		return nil, err
 	}
+	item := sess.Current()
+	if item == nil || item.Match == nil {
+		return nil, ErrConflict
+	}
+	board := item.Match
 
-	// Update review statistics
-	if err := uc.reviewRepo.UpdateStats(ctx, input.WordID, input.Result); err != nil {
+	results, ok := board.Check(input.Pairs)
+	if !ok {
+		return nil, ErrBadRequest
+	}
+
+	// The board is recorded as a whole or not at all
+	output := &SubmitMatchOutput{Success: true}
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for _, card := range board.Words {
+			if _, err := uc.wordRepo.GetByID(ctx, card.ID, input.UserID); err != nil {
+				if errors.Is(err, domain.ErrWordNotFound) {
+					continue
+				}
+				return err
+			}
+
+			outcome := review.BinaryOutcome(results[card.ID])
+			var answer *string
+			if chosen, ok := board.Definition(input.Pairs[card.ID]); ok {
+				answer = &chosen.Text
+			}
+
+			if err := uc.reviewRepo.Create(ctx, &review.Review{
+				ID:         uuid.NewString(),
+				WordID:     card.ID,
+				UserID:     input.UserID,
+				Result:     outcome.Correct,
+				Score:      outcome.Score,
+				Answer:     answer,
+				ReviewType: "match",
+				SessionID:  &sess.ID,
+			}); err != nil {
+				return mapDomainError(err)
+			}
+			if err := uc.reviewRepo.UpdateStats(ctx, card.ID, outcome); err != nil {
+				return err
+			}
+
+			rating := schedule.RatingFor(outcome.Correct, false, nil)
+			if err := uc.scheduler.Record(ctx, input.UserID, card.ID, rating, time.Now()); err != nil {
+				return err
+			}
+
+			expected, _ := board.Definition(board.Key[card.ID])
+			output.Results = append(output.Results, MatchPairResult{
+				WordID:   card.ID,
+				Text:     card.Text,
+				Correct:  outcome.Correct,
+				Expected: expected.Text,
+			})
+			if outcome.Correct {
+				output.Correct++
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &SubmitReviewOutput{Success: true}, nil
+	output.Session, err = uc.moveOn(ctx, sess)
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
 }
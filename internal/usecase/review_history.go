@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+)
+
+// ListReviewsInput represents input for listing reviews
+type ListReviewsInput struct {
+	UserID string
+	WordID string     // only reviews of this word, if set
+	From   *time.Time // reviewed at or after
+	To     *time.Time // reviewed before
+	Limit  int
+	Cursor string // next_cursor of the previous page; empty for the first page
+}
+
+// ListReviewsOutput represents output from listing reviews
+type ListReviewsOutput struct {
+	Reviews    []*review.Review
+	NextCursor string // empty on the last page
+}
+
+// ListReviews returns a page of the user's reviews, newest first, of one
+// word or of all of them
+func (uc *ReviewUseCase) ListReviews(ctx context.Context, input ListReviewsInput) (*ListReviewsOutput, error) {
+	if input.From != nil && input.To != nil && !input.From.Before(*input.To) {
+		return nil, ErrBadRequest
+	}
+
+	filter := review.ListFilter{
+		UserID: input.UserID,
+		WordID: input.WordID,
+		From:   input.From,
+		To:     input.To,
+	}
+
+	limit, err := pageSize(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = limit
+
+	if input.Cursor != "" {
+		after, err := decodeReviewCursor(input.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	// Verify word belongs to user
+	if input.WordID != "" {
+		if _, err := uc.wordRepo.GetByID(ctx, input.WordID, input.UserID); err != nil {
+			return nil, mapDomainError(err)
+		}
+	}
+
+	page, err := uc.reviewRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListReviewsOutput{
+		Reviews:    page.Reviews,
+		NextCursor: encodeReviewCursor(page.Next),
+	}, nil
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+)
+
+const (
+	// MaxSyncReviews is the most reviews one sync may carry
+	MaxSyncReviews = 200
+	// maxClockSkew is how far ahead of the server's clock a client's
+	// review time may be
+	maxClockSkew = 5 * time.Minute
+)
+
+// OfflineReviewInput is a review made while offline. Its IdempotencyKey
+// is required, and it cannot be made in a session.
+type OfflineReviewInput struct {
+	SubmitReviewInput
+	ReviewedAt time.Time // when the review was made, on the client's clock
+}
+
+// SyncReviewsInput represents input for syncing offline reviews
+type SyncReviewsInput struct {
+	UserID  string
+	Reviews []OfflineReviewInput
+}
+
+// SyncStatus is what became of a synced review
+type SyncStatus string
+
+const (
+	SyncRecorded  SyncStatus = "recorded"  // the review was recorded
+	SyncDuplicate SyncStatus = "duplicate" // a review with the key was recorded before
+	SyncRejected  SyncStatus = "rejected"  // the review cannot be recorded
+)
+
+// SyncedReview is the outcome of one synced review
+type SyncedReview struct {
+	IdempotencyKey string
+	Status         SyncStatus
+	Reason         string              // why the review was rejected
+	Output         *SubmitReviewOutput // the graded review, unless rejected
+}
+
+// SyncReviewsOutput represents output from syncing offline reviews
+type SyncReviewsOutput struct {
+	Results []SyncedReview // in input order
+}
+
+// SyncReviews records reviews made offline at their original times. They
+// are applied oldest first, whatever order they are sent in, and reviews
+// already recorded under the same key are skipped, so a sync can be
+// retried as a whole. One review that cannot be recorded does not stop
+// the others. The statistics and schedule of every word reviewed are
+// then rebuilt from its full history, so that a review older than the
+// word's last one still counts in its place. A sync that fails partway
+// through is completed by retrying it: the words of its duplicates are
+// rebuilt again.
+func (uc *ReviewUseCase) SyncReviews(ctx context.Context, input SyncReviewsInput) (*SyncReviewsOutput, error) {
+	if len(input.Reviews) == 0 || len(input.Reviews) > MaxSyncReviews {
+		return nil, ErrBadRequest
+	}
+	latest := time.Now().Add(maxClockSkew)
+	for _, r := range input.Reviews {
+		if r.IdempotencyKey == "" || r.SessionID != "" || r.ReviewedAt.IsZero() || r.ReviewedAt.After(latest) {
+			return nil, ErrBadRequest
+		}
+	}
+
+	order := make([]int, len(input.Reviews))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return input.Reviews[a].ReviewedAt.Compare(input.Reviews[b].ReviewedAt)
+	})
+
+	output := &SyncReviewsOutput{Results: make([]SyncedReview, len(input.Reviews))}
+	var touched []string
+	for _, i := range order {
+		r := input.Reviews[i]
+		r.UserID = input.UserID
+
+		result, err := uc.syncReview(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		output.Results[i] = *result
+		if result.Status != SyncRejected && !slices.Contains(touched, r.WordID) {
+			touched = append(touched, r.WordID)
+		}
+	}
+
+	for _, wordID := range touched {
+		if err := uc.rebuild(ctx, input.UserID, wordID); err != nil {
+			return nil, err
+		}
+	}
+
+	return output, nil
+}
+
+// syncReview records one offline review. Errors that concern the review
+// alone reject it rather than fail the sync.
+func (uc *ReviewUseCase) syncReview(ctx context.Context, input OfflineReviewInput) (*SyncedReview, error) {
+	result := &SyncedReview{IdempotencyKey: input.IdempotencyKey, Status: SyncRejected}
+
+	rev, output, err := uc.grade(ctx, input.SubmitReviewInput)
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrForbidden):
+		result.Reason = "word not found"
+		return result, nil
+	case errors.Is(err, ErrBadRequest):
+		result.Reason = "answer is required for this review type, quality must be valid and latency_ms 0-86400000"
+		return result, nil
+	case err != nil:
+		return nil, err
+	}
+
+	done, err := uc.syncReplay(ctx, rev, output, result)
+	if err != nil {
+		return nil, err
+	}
+	if done {
+		return result, nil
+	}
+
+	// Statistics and schedules are rebuilt once the whole sync is recorded
+	rev.ReviewedAt = input.ReviewedAt
+	err = uc.reviewRepo.Create(ctx, rev)
+	if errors.Is(err, domain.ErrReviewKeyUsed) {
+		// Recorded by a concurrent sync since it was looked up
+		done, err := uc.syncReplay(ctx, rev, output, result)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return result, nil
+		}
+		result.Reason = "idempotency key was used for another review"
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Status = SyncRecorded
+	result.Output = output
+	return result, nil
+}
+
+// syncReplay settles an offline review whose idempotency key was used
+// before, as a duplicate or, if it differs from the review recorded
+// then, as rejected. It reports whether the review was settled.
+func (uc *ReviewUseCase) syncReplay(ctx context.Context, rev *review.Review, output *SubmitReviewOutput, result *SyncedReview) (bool, error) {
+	replayed, err := uc.replay(ctx, rev, output)
+	switch {
+	case errors.Is(err, ErrConflict):
+		result.Reason = "idempotency key was used for another review"
+		return true, nil
+	case err != nil:
+		return false, err
+	case replayed:
+		result.Status = SyncDuplicate
+		result.Output = output
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,622 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+	"github.com/sonsonha/eng-noting/internal/domain/schedule"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+	"github.com/sonsonha/eng-noting/internal/domain/settings"
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+var errSaveFailed = errors.New("save failed")
+
+// store is the data the fake repositories share, so that fakeTx can
+// undo all their changes at once
+type store struct {
+	reviews  []*review.Review
+	stats    map[string]review.ReviewStats
+	states   map[string]schedule.State
+	failSave bool
+	// staleKeys is how many GetByKey lookups miss a recorded key, as
+	// they do for a request racing another with the same key
+	staleKeys int
+}
+
+func newStore() *store {
+	return &store{stats: map[string]review.ReviewStats{}, states: map[string]schedule.State{}}
+}
+
+// fakeTx restores the store as it was if the unit of work fails
+type fakeTx struct{ s *store }
+
+func (tx fakeTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	reviews := append([]*review.Review(nil), tx.s.reviews...)
+	stats := maps.Clone(tx.s.stats)
+	states := maps.Clone(tx.s.states)
+	if err := fn(ctx); err != nil {
+		tx.s.reviews, tx.s.stats, tx.s.states = reviews, stats, states
+		return err
+	}
+	return nil
+}
+
+type fakeWordRepo struct {
+	word.WordRepository
+	s *store
+}
+
+func (fakeWordRepo) GetByID(ctx context.Context, wordID, userID string) (*word.Word, error) {
+	if wordID != "w1" {
+		return nil, domain.ErrWordNotFound
+	}
+	return &word.Word{ID: wordID, UserID: userID, Text: "run"}, nil
+}
+
+// Merge moves the source's reviews over to the target
+func (r fakeWordRepo) Merge(ctx context.Context, userID, targetID, sourceID string) error {
+	for _, rev := range r.s.reviews {
+		if rev.WordID == sourceID {
+			rev.WordID = targetID
+		}
+	}
+	return nil
+}
+
+type fakeReviewRepo struct {
+	review.ReviewRepository
+	s *store
+}
+
+func (r fakeReviewRepo) Create(ctx context.Context, rev *review.Review) error {
+	if rev.IdempotencyKey != nil {
+		for _, prev := range r.s.reviews {
+			if prev.UserID == rev.UserID && prev.IdempotencyKey != nil && *prev.IdempotencyKey == *rev.IdempotencyKey {
+				return domain.ErrReviewKeyUsed
+			}
+		}
+	}
+	r.s.reviews = append(r.s.reviews, rev)
+	return nil
+}
+
+func (r fakeReviewRepo) GetByKey(ctx context.Context, userID, key string) (*review.Review, error) {
+	if r.s.staleKeys > 0 {
+		r.s.staleKeys--
+		return nil, nil
+	}
+	for _, rev := range r.s.reviews {
+		if rev.UserID == userID && rev.IdempotencyKey != nil && *rev.IdempotencyKey == key {
+			return rev, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r fakeReviewRepo) UpdateStats(ctx context.Context, wordID string, outcome review.Outcome) error {
+	stats := r.s.stats[wordID]
+	stats.TotalReviews++
+	if outcome.Correct {
+		stats.CorrectReviews++
+	}
+	r.s.stats[wordID] = stats
+	return nil
+}
+
+func (r fakeReviewRepo) RecomputeStats(ctx context.Context, wordID string) error {
+	var stats review.ReviewStats
+	for _, rev := range r.s.reviews {
+		if rev.WordID == wordID {
+			stats.TotalReviews++
+			if rev.Result {
+				stats.CorrectReviews++
+			}
+		}
+	}
+	if stats.TotalReviews == 0 {
+		delete(r.s.stats, wordID)
+		return nil
+	}
+	r.s.stats[wordID] = stats
+	return nil
+}
+
+func (r fakeReviewRepo) ListByWord(ctx context.Context, wordID string) ([]*review.Review, error) {
+	var reviews []*review.Review
+	for _, rev := range r.s.reviews {
+		if rev.WordID == wordID {
+			reviews = append(reviews, rev)
+		}
+	}
+	slices.SortStableFunc(reviews, func(a, b *review.Review) int { return a.ReviewedAt.Compare(b.ReviewedAt) })
+	return reviews, nil
+}
+
+// Latest returns the review of the word made last
+func (r fakeReviewRepo) Latest(ctx context.Context, wordID string) (*review.Review, error) {
+	reviews, _ := r.ListByWord(ctx, wordID)
+	if len(reviews) == 0 {
+		return nil, nil
+	}
+	return reviews[len(reviews)-1], nil
+}
+
+// UpdateOutcome replaces the review rather than changing it, so that
+// fakeTx can undo it
+func (r fakeReviewRepo) UpdateOutcome(ctx context.Context, reviewID string, outcome review.Outcome) error {
+	for i, rev := range r.s.reviews {
+		if rev.ID == reviewID {
+			updated := *rev
+			updated.Result, updated.Score = outcome.Correct, outcome.Score
+			updated.Hesitant = rev.Hesitant && outcome.Correct
+			r.s.reviews[i] = &updated
+			return nil
+		}
+	}
+	return domain.ErrReviewNotFound
+}
+
+func (r fakeReviewRepo) Delete(ctx context.Context, reviewID string) error {
+	for i, rev := range r.s.reviews {
+		if rev.ID == reviewID {
+			r.s.reviews = slices.Delete(slices.Clone(r.s.reviews), i, i+1)
+			return nil
+		}
+	}
+	return domain.ErrReviewNotFound
+}
+
+// List pages through the store's reviews as they were created
+func (r fakeReviewRepo) List(ctx context.Context, filter review.ListFilter) (*review.Page, error) {
+	page := &review.Page{}
+	for _, rev := range r.s.reviews {
+		if filter.After != nil && rev.ID <= filter.After.ID {
+			continue
+		}
+		if len(page.Reviews) == filter.Limit {
+			last := page.Reviews[len(page.Reviews)-1]
+			page.Next = &review.Cursor{ReviewedAt: last.ReviewedAt, ID: last.ID}
+			break
+		}
+		page.Reviews = append(page.Reviews, rev)
+	}
+	return page, nil
+}
+
+func (r fakeReviewRepo) ListBySession(ctx context.Context, sessionID string) ([]*review.Review, error) {
+	var reviews []*review.Review
+	for _, rev := range r.s.reviews {
+		if rev.SessionID != nil && *rev.SessionID == sessionID {
+			reviews = append(reviews, rev)
+		}
+	}
+	return reviews, nil
+}
+
+func (r fakeReviewRepo) SessionAccuracy(ctx context.Context, sessionID string) (map[string]review.AccuracyChange, error) {
+	return map[string]review.AccuracyChange{}, nil
+}
+
+func (r fakeReviewRepo) ListStats(ctx context.Context, wordIDs []string) (map[string]*review.ReviewStats, error) {
+	stats := make(map[string]*review.ReviewStats, len(wordIDs))
+	for _, id := range wordIDs {
+		st := r.s.stats[id]
+		st.WordID = id
+		stats[id] = &st
+	}
+	return stats, nil
+}
+
+func (r fakeReviewRepo) LastReviewTypes(ctx context.Context, wordIDs []string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+// fakeSessionRepo holds sessions; those given a ttl expire once elapse
+// has moved the clock past it, the others never do
+type fakeSessionRepo struct {
+	session.SessionRepository
+	sessions map[string]*session.Session
+	ttls     map[string]time.Duration // time left before each session expires
+}
+
+func newFakeSessionRepo(sessions ...*session.Session) *fakeSessionRepo {
+	r := &fakeSessionRepo{sessions: map[string]*session.Session{}, ttls: map[string]time.Duration{}}
+	for _, sess := range sessions {
+		r.sessions[sess.ID] = sess
+	}
+	return r
+}
+
+// elapse moves the clock on by d, dropping the sessions that expire
+func (r *fakeSessionRepo) elapse(d time.Duration) {
+	for id, ttl := range r.ttls {
+		if ttl <= d {
+			delete(r.sessions, id)
+			delete(r.ttls, id)
+			continue
+		}
+		r.ttls[id] = ttl - d
+	}
+}
+
+func (r *fakeSessionRepo) Advance(ctx context.Context, sessionID string, from int, ttl time.Duration) (int, error) {
+	sess, ok := r.sessions[sessionID]
+	if !ok {
+		return 0, domain.ErrSessionNotFound
+	}
+	if sess.Index != from || from >= len(sess.Items) {
+		return 0, domain.ErrSessionMoved
+	}
+	sess.Index++
+	r.ttls[sessionID] = ttl
+	return sess.Index, nil
+}
+
+// List returns a page of a user's sessions, newest first
+func (r *fakeSessionRepo) List(ctx context.Context, filter session.ListFilter) (*session.Page, error) {
+	var summaries []session.Summary
+	for _, sess := range r.sessions {
+		summary := session.Summary{
+			ID:          sess.ID,
+			TagID:       sess.TagID,
+			Index:       sess.Index,
+			Total:       len(sess.Items),
+			CreatedAt:   sess.CreatedAt,
+			AbandonedAt: sess.AbandonedAt,
+		}
+		if sess.UserID != filter.UserID || filter.Status != "" && summary.Status() != filter.Status {
+			continue
+		}
+		if a := filter.After; a != nil && !summary.CreatedAt.Before(a.CreatedAt) {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	slices.SortFunc(summaries, func(a, b session.Summary) int { return b.CreatedAt.Compare(a.CreatedAt) })
+
+	page := &session.Page{Sessions: summaries}
+	if len(summaries) > filter.Limit {
+		page.Sessions = summaries[:filter.Limit]
+		last := page.Sessions[len(page.Sessions)-1]
+		page.Next = &session.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	return page, nil
+}
+
+func (r *fakeSessionRepo) Abandon(ctx context.Context, sessionID string, ttl time.Duration) error {
+	sess, ok := r.sessions[sessionID]
+	if !ok {
+		return domain.ErrSessionNotFound
+	}
+	if sess.AbandonedAt == nil {
+		now := time.Now()
+		sess.AbandonedAt = &now
+		r.ttls[sessionID] = ttl
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) Create(ctx context.Context, sess *session.Session, ttl time.Duration) error {
+	sess.CreatedAt = time.Now()
+	r.sessions[sess.ID] = sess
+	r.ttls[sess.ID] = ttl
+	return nil
+}
+
+func (r *fakeSessionRepo) Get(ctx context.Context, sessionID string) (*session.Session, error) {
+	sess, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	copied := *sess
+	return &copied, nil
+}
+
+func (r *fakeSessionRepo) Rewind(ctx context.Context, sessionID string, from int, ttl time.Duration) (int, error) {
+	sess, ok := r.sessions[sessionID]
+	if !ok {
+		return 0, domain.ErrSessionNotFound
+	}
+	if sess.Index != from || from == 0 {
+		return 0, domain.ErrSessionMoved
+	}
+	sess.Index--
+	r.ttls[sessionID] = ttl
+	return sess.Index, nil
+}
+
+type fakeSettingsRepo struct{ settings.SettingsRepository }
+
+func (fakeSettingsRepo) Get(ctx context.Context, userID string) (*settings.Settings, error) {
+	return settings.Default(userID), nil
+}
+
+type fakeScheduleRepo struct {
+	schedule.Repository
+	s *store
+}
+
+func (r fakeScheduleRepo) Get(ctx context.Context, wordID string) (schedule.State, error) {
+	return r.s.states[wordID], nil
+}
+
+func (r fakeScheduleRepo) List(ctx context.Context, userID string) (map[string]schedule.State, error) {
+	return r.s.states, nil
+}
+
+func (r fakeScheduleRepo) Delete(ctx context.Context, wordID string) error {
+	delete(r.s.states, wordID)
+	return nil
+}
+
+func (r fakeScheduleRepo) Save(ctx context.Context, wordID string, state schedule.State) error {
+	if r.s.failSave {
+		return errSaveFailed
+	}
+	r.s.states[wordID] = state
+	return nil
+}
+
+func newReviewUseCase(s *store) *ReviewUseCase {
+	scheduler := NewReviewScheduler(fakeSettingsRepo{}, fakeScheduleRepo{s: s})
+	return NewReviewUseCase(fakeReviewRepo{s: s}, fakeWordRepo{s: s}, nil, nil, session.Expiry{}, scheduler, fakeTx{s: s})
+}
+
+func submitInput(key string) SubmitReviewInput {
+	answer := "run"
+	return SubmitReviewInput{UserID: "u1", WordID: "w1", Answer: &answer, ReviewType: "mcq", IdempotencyKey: key}
+}
+
+func TestSubmitReviewCountsOnce(t *testing.T) {
+	s := newStore()
+	uc := newReviewUseCase(s)
+
+	if _, err := uc.SubmitReview(context.Background(), submitInput("")); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	stats := s.stats["w1"]
+	if len(s.reviews) != 1 || stats.TotalReviews != 1 || stats.CorrectReviews != 1 {
+		t.Fatalf("expected 1 review counted once, got %d reviews and %+v", len(s.reviews), stats)
+	}
+	if s.states["w1"].LastReviewedAt == nil {
+		t.Fatal("expected the word to be scheduled")
+	}
+}
+
+func TestSubmitReviewRetryCountsOnce(t *testing.T) {
+	s := newStore()
+	uc := newReviewUseCase(s)
+
+	for i := range 2 {
+		output, err := uc.SubmitReview(context.Background(), submitInput("k1"))
+		if err != nil {
+			t.Fatalf("submit %d: %v", i, err)
+		}
+		if output.Replayed != (i == 1) {
+			t.Fatalf("submit %d: expected replayed %v", i, i == 1)
+		}
+	}
+
+	if stats := s.stats["w1"]; len(s.reviews) != 1 || stats.TotalReviews != 1 {
+		t.Fatalf("expected the retry to record nothing, got %d reviews and %+v", len(s.reviews), stats)
+	}
+}
+
+func TestSubmitReviewRetryAnswersAsRecorded(t *testing.T) {
+	s := newStore()
+	uc := newReviewUseCase(s)
+
+	if _, err := uc.SubmitReview(context.Background(), submitInput("k1")); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	// As if the word had been graded differently the first time
+	s.reviews[0].Score = 0.8
+
+	output, err := uc.SubmitReview(context.Background(), submitInput("k1"))
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if !output.Replayed || !output.Correct || output.Score != 0.8 {
+		t.Fatalf("expected the recorded outcome replayed, got %+v", output)
+	}
+}
+
+func TestSubmitReviewRetryWithDifferentPayload(t *testing.T) {
+	s := newStore()
+	uc := newReviewUseCase(s)
+
+	if _, err := uc.SubmitReview(context.Background(), submitInput("k1")); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	other := "walk"
+	hard := "hard"
+	changes := map[string]func(*SubmitReviewInput){
+		"answer":  func(in *SubmitReviewInput) { in.Answer = &other },
+		"quality": func(in *SubmitReviewInput) { in.Quality = &hard },
+	}
+	for name, change := range changes {
+		input := submitInput("k1")
+		change(&input)
+		if _, err := uc.SubmitReview(context.Background(), input); err != ErrConflict {
+			t.Errorf("%s: expected a conflict, got %v", name, err)
+		}
+	}
+
+	// Without an answer to grade, the self-reported result must match
+	selfReported := SubmitReviewInput{UserID: "u1", WordID: "w1", ReviewType: "match", IdempotencyKey: "k2"}
+	if _, err := uc.SubmitReview(context.Background(), selfReported); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	selfReported.Result = true
+	if _, err := uc.SubmitReview(context.Background(), selfReported); err != ErrConflict {
+		t.Errorf("result: expected a conflict, got %v", err)
+	}
+
+	if stats := s.stats["w1"]; len(s.reviews) != 2 || stats.TotalReviews != 2 {
+		t.Fatalf("expected nothing recorded again, got %d reviews and %+v", len(s.reviews), stats)
+	}
+}
+
+func TestSubmitReviewConcurrentDuplicate(t *testing.T) {
+	s := newStore()
+	uc := newReviewUseCase(s)
+
+	if _, err := uc.SubmitReview(context.Background(), submitInput("k1")); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	// The duplicate looks the key up before the first one is recorded
+	s.staleKeys = 1
+	output, err := uc.SubmitReview(context.Background(), submitInput("k1"))
+	if err != nil {
+		t.Fatalf("duplicate: %v", err)
+	}
+	if !output.Replayed {
+		t.Fatalf("expected the duplicate to be replayed, got %+v", output)
+	}
+	if stats := s.stats["w1"]; len(s.reviews) != 1 || stats.TotalReviews != 1 {
+		t.Fatalf("expected the duplicate to record nothing, got %d reviews and %+v", len(s.reviews), stats)
+	}
+}
+
+func TestSubmitReviewRollsBackOnFailure(t *testing.T) {
+	s := newStore()
+	s.failSave = true
+	uc := newReviewUseCase(s)
+
+	if _, err := uc.SubmitReview(context.Background(), submitInput("")); !errors.Is(err, errSaveFailed) {
+		t.Fatalf("expected the scheduling error, got %v", err)
+	}
+
+	if stats := s.stats["w1"]; len(s.reviews) != 0 || stats.TotalReviews != 0 {
+		t.Fatalf("expected nothing recorded, got %d reviews and %+v", len(s.reviews), stats)
+	}
+}
+
+func TestListReviewsPages(t *testing.T) {
+	s := newStore()
+	for _, id := range []string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000003"} {
+		s.reviews = append(s.reviews, &review.Review{ID: id, WordID: "w1", UserID: "u1", ReviewedAt: time.Now()})
+	}
+	uc := newReviewUseCase(s)
+
+	var got []string
+	input := ListReviewsInput{UserID: "u1", WordID: "w1", Limit: 2}
+	for range 3 {
+		output, err := uc.ListReviews(context.Background(), input)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		for _, rev := range output.Reviews {
+			got = append(got, rev.ID)
+		}
+		if output.NextCursor == "" {
+			break
+		}
+		input.Cursor = output.NextCursor
+	}
+
+	if len(got) != 3 || got[2] != s.reviews[2].ID {
+		t.Fatalf("expected every review once, got %v", got)
+	}
+}
+
+func TestListReviewsRejectsBadInput(t *testing.T) {
+	uc := newReviewUseCase(newStore())
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	before := day.AddDate(0, 0, -1)
+
+	cases := []struct {
+		name  string
+		input ListReviewsInput
+		want  error
+	}{
+		{"empty range", ListReviewsInput{UserID: "u1", From: &day, To: &before}, ErrBadRequest},
+		{"bad cursor", ListReviewsInput{UserID: "u1", Cursor: "nope"}, ErrBadRequest},
+		{"too large", ListReviewsInput{UserID: "u1", Limit: MaxPageSize + 1}, ErrBadRequest},
+		{"unknown word", ListReviewsInput{UserID: "u1", WordID: "w2"}, ErrNotFound},
+	}
+
+	for _, c := range cases {
+		if _, err := uc.ListReviews(context.Background(), c.input); err != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, err)
+		}
+	}
+}
+
+func TestSyncReviewsReplaysLateReviewsInOrder(t *testing.T) {
+	s := newStore()
+	uc := newReviewUseCase(s)
+
+	if _, err := uc.SubmitReview(context.Background(), submitInput("k1")); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	// Missed an hour before the review just made, and synced after it
+	wrong := "walk"
+	late := OfflineReviewInput{SubmitReviewInput: submitInput("k2"), ReviewedAt: time.Now().Add(-time.Hour)}
+	late.Answer = &wrong
+	output, err := uc.SyncReviews(context.Background(), SyncReviewsInput{UserID: "u1", Reviews: []OfflineReviewInput{late}})
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if output.Results[0].Status != SyncRecorded {
+		t.Fatalf("expected the review recorded, got %+v", output.Results[0])
+	}
+
+	scheduler, err := uc.scheduler.For(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("scheduler: %v", err)
+	}
+	want := schedule.Replay(scheduler, []schedule.Rated{
+		{Rating: schedule.RatingFor(false, false, nil), At: late.ReviewedAt},
+		{Rating: schedule.RatingFor(true, false, nil), At: s.reviews[0].ReviewedAt},
+	})
+	if got := s.states["w1"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the schedule replayed in order %+v, got %+v", want, got)
+	}
+	if stats := s.stats["w1"]; stats.TotalReviews != 2 || stats.CorrectReviews != 1 {
+		t.Fatalf("expected both reviews counted, got %+v", stats)
+	}
+}
+
+func TestSubmitReviewRequiresTypedAnswer(t *testing.T) {
+	s := newStore()
+	uc := newReviewUseCase(s)
+
+	for _, reviewType := range []string{"mcq", "typing", "fill_blank"} {
+		input := SubmitReviewInput{UserID: "u1", WordID: "w1", ReviewType: reviewType, Result: true}
+		if _, err := uc.SubmitReview(context.Background(), input); err != ErrBadRequest {
+			t.Errorf("%s: expected a bad request without an answer, got %v", reviewType, err)
+		}
+	}
+	if len(s.reviews) != 0 {
+		t.Fatalf("expected nothing recorded, got %d reviews", len(s.reviews))
+	}
+}
+
+func TestSubmitReviewRejectsInvalidLatency(t *testing.T) {
+	s := newStore()
+	uc := newReviewUseCase(s)
+
+	for _, ms := range []int{-1, 1 << 40} {
+		input := submitInput("")
+		input.LatencyMs = &ms
+		if _, err := uc.SubmitReview(context.Background(), input); err != ErrBadRequest {
+			t.Errorf("%d ms: expected a bad request, got %v", ms, err)
+		}
+	}
+	if len(s.reviews) != 0 {
+		t.Fatalf("expected nothing recorded, got %d reviews", len(s.reviews))
+	}
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+)
+
+// UndoReviewInput represents input for undoing a review
+type UndoReviewInput struct {
+	UserID string
+	WordID string
+	Flip   bool // keep the review with the opposite result, rather than remove it
+}
+
+// UndoReviewOutput represents output from undoing a review
+type UndoReviewOutput struct {
+	ReviewID string
+	Flipped  bool
+	Correct  bool             // the review's result after a flip
+	Session  *SessionProgress // for a removed review whose session moved back
+}
+
+// UndoReview removes or flips the most recent review of a word, if it
+// was made in the last review.UndoWindow, and recomputes the word's
+// statistics and schedule from its remaining history.
+//
+// Removing the answer to a session's last item moves the session back to
+// that item, so that it can be answered again. A matching board is
+// answered as a whole, so its reviews can only be flipped.
+func (uc *ReviewUseCase) UndoReview(ctx context.Context, input UndoReviewInput) (*UndoReviewOutput, error) {
+	// Verify word belongs to user
+	if _, err := uc.wordRepo.GetByID(ctx, input.WordID, input.UserID); err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	rev, err := uc.reviewRepo.Latest(ctx, input.WordID)
+	if err != nil {
+		return nil, err
+	}
+	if rev == nil || rev.UserID != input.UserID || time.Since(rev.ReviewedAt) > review.UndoWindow {
+		return nil, ErrConflict
+	}
+
+	if !input.Flip && rev.ReviewType == "match" && rev.SessionID != nil {
+		return nil, ErrConflict
+	}
+
+	// The review, its statistics and its schedule change together
+	output := &UndoReviewOutput{ReviewID: rev.ID, Flipped: input.Flip}
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if input.Flip {
+			outcome := rev.Flipped()
+			if err := uc.reviewRepo.UpdateOutcome(ctx, rev.ID, outcome); err != nil {
+				return mapDomainError(err)
+			}
+			output.Correct = outcome.Correct
+		} else if err := uc.reviewRepo.Delete(ctx, rev.ID); err != nil {
+			return mapDomainError(err)
+		}
+
+		return uc.rebuild(ctx, input.UserID, input.WordID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !input.Flip && rev.SessionID != nil {
+		output.Session, err = uc.moveBack(ctx, *rev.SessionID, rev.WordID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return output, nil
+}
+
+// moveBack moves a session back to the item of the word whose review was
+// removed, if that is the item just answered. A session that expired,
+// was abandoned or has moved on since is left as it is.
+func (uc *ReviewUseCase) moveBack(ctx context.Context, sessionID, wordID string) (*SessionProgress, error) {
+	sess, err := uc.sessionRepo.Get(ctx, sessionID)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sess.AbandonedAt != nil || sess.Index == 0 {
+		return nil, nil
+	}
+	if last := sess.Items[sess.Index-1]; last.Match != nil || last.WordID != wordID {
+		return nil, nil
+	}
+
+	index, err := uc.sessionRepo.Rewind(ctx, sess.ID, sess.Index, uc.expiry.At(sess, sess.Index-1))
+	if errors.Is(err, domain.ErrSessionMoved) || errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SessionProgress{Position: index}, nil
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+	"github.com/sonsonha/eng-noting/internal/domain/schedule"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+)
+
+func newUndoUseCase(s *store, sessions *fakeSessionRepo) *ReviewUseCase {
+	scheduler := NewReviewScheduler(fakeSettingsRepo{}, fakeScheduleRepo{s: s})
+	return NewReviewUseCase(fakeReviewRepo{s: s}, fakeWordRepo{s: s}, nil, sessions, testExpiry, scheduler, fakeTx{s: s})
+}
+
+// addReview records a review of w1 made ago
+func addReview(s *store, id string, ago time.Duration, result bool, score float64) *review.Review {
+	rev := &review.Review{
+		ID:         id,
+		WordID:     "w1",
+		UserID:     "u1",
+		Result:     result,
+		Score:      score,
+		ReviewType: "typing",
+		ReviewedAt: time.Now().Add(-ago),
+	}
+	s.reviews = append(s.reviews, rev)
+	return rev
+}
+
+func TestUndoReviewRemoves(t *testing.T) {
+	s := newStore()
+	uc := newUndoUseCase(s, newFakeSessionRepo())
+	first := addReview(s, "r1", time.Hour, false, 0)
+	addReview(s, "r2", time.Minute, true, 1)
+
+	out, err := uc.UndoReview(context.Background(), UndoReviewInput{UserID: "u1", WordID: "w1"})
+	if err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+
+	if out.ReviewID != "r2" || out.Flipped || out.Session != nil {
+		t.Errorf("unexpected output %+v", out)
+	}
+	if len(s.reviews) != 1 || s.reviews[0].ID != "r1" {
+		t.Fatalf("expected only r1 left, got %+v", s.reviews)
+	}
+	if stats := s.stats["w1"]; stats.TotalReviews != 1 || stats.CorrectReviews != 0 {
+		t.Errorf("expected the stats recomputed from r1, got %+v", stats)
+	}
+	scheduler, err := uc.scheduler.For(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("scheduler: %v", err)
+	}
+	want := schedule.Replay(scheduler, []schedule.Rated{{Rating: schedule.RatingFor(false, false, nil), At: first.ReviewedAt}})
+	if got := s.states["w1"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the schedule rebuilt from r1 %+v, got %+v", want, got)
+	}
+}
+
+func TestUndoReviewFlips(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      bool
+		score       float64
+		wantCorrect bool
+		wantScore   float64
+	}{
+		{"right to wrong", true, 1, false, 0},
+		{"wrong to right", false, 0, true, 1},
+		// Partial credit was graded from the answer and stays
+		{"typo to wrong", true, 0.8, false, 0.8},
+		{"near miss to right", false, 0.3, true, 0.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore()
+			uc := newUndoUseCase(s, newFakeSessionRepo())
+			addReview(s, "r1", time.Minute, tt.result, tt.score)
+
+			out, err := uc.UndoReview(context.Background(), UndoReviewInput{UserID: "u1", WordID: "w1", Flip: true})
+			if err != nil {
+				t.Fatalf("undo: %v", err)
+			}
+
+			if !out.Flipped || out.Correct != tt.wantCorrect {
+				t.Errorf("unexpected output %+v", out)
+			}
+			rev := s.reviews[0]
+			if rev.Result != tt.wantCorrect || rev.Score != tt.wantScore {
+				t.Errorf("expected result %v with score %v, got %v with %v", tt.wantCorrect, tt.wantScore, rev.Result, rev.Score)
+			}
+			if stats := s.stats["w1"]; stats.TotalReviews != 1 {
+				t.Errorf("expected the stats recomputed, got %+v", stats)
+			}
+		})
+	}
+}
+
+func TestUndoReviewWindow(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(s *store)
+	}{
+		{"no review", func(s *store) {}},
+		{"too old", func(s *store) { addReview(s, "r1", review.UndoWindow+time.Minute, true, 1) }},
+		{"another user's", func(s *store) { addReview(s, "r1", time.Minute, true, 1).UserID = "u2" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore()
+			uc := newUndoUseCase(s, newFakeSessionRepo())
+			tt.setup(s)
+
+			if _, err := uc.UndoReview(context.Background(), UndoReviewInput{UserID: "u1", WordID: "w1"}); err != ErrConflict {
+				t.Fatalf("expected ErrConflict, got %v", err)
+			}
+		})
+	}
+
+	s := newStore()
+	if _, err := newUndoUseCase(s, newFakeSessionRepo()).UndoReview(context.Background(), UndoReviewInput{UserID: "u1", WordID: "w2"}); err != ErrNotFound {
+		t.Fatalf("unknown word: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUndoReviewKeepsMatchingBoards(t *testing.T) {
+	s := newStore()
+	uc := newUndoUseCase(s, newFakeSessionRepo())
+	rev := addReview(s, "r1", time.Minute, true, 1)
+	sessionID := "s1"
+	rev.ReviewType, rev.SessionID = "match", &sessionID
+
+	if _, err := uc.UndoReview(context.Background(), UndoReviewInput{UserID: "u1", WordID: "w1"}); err != ErrConflict {
+		t.Fatalf("remove: expected ErrConflict, got %v", err)
+	}
+	if len(s.reviews) != 1 {
+		t.Fatalf("expected the board's review kept")
+	}
+
+	// A board's review can still be flipped
+	if _, err := uc.UndoReview(context.Background(), UndoReviewInput{UserID: "u1", WordID: "w1", Flip: true}); err != nil {
+		t.Fatalf("flip: %v", err)
+	}
+	if s.reviews[0].Result {
+		t.Errorf("expected the review flipped")
+	}
+}
+
+func TestUndoReviewMovesSessionBack(t *testing.T) {
+	items := []session.SessionItem{{WordID: "w1", ReviewType: "typing"}, {WordID: "w2", ReviewType: "typing"}}
+	tests := []struct {
+		name      string
+		index     int
+		abandoned bool
+		flip      bool
+		wantMoved bool
+	}{
+		{"last item answered", 1, false, false, true},
+		{"session moved on", 2, false, false, false},
+		{"abandoned", 1, true, false, false},
+		{"flipped", 1, false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore()
+			sess := &session.Session{ID: "s1", UserID: "u1", Items: items, Index: tt.index}
+			if tt.abandoned {
+				at := time.Now()
+				sess.AbandonedAt = &at
+			}
+			uc := newUndoUseCase(s, newFakeSessionRepo(sess))
+			sessionID := "s1"
+			addReview(s, "r1", time.Minute, true, 1).SessionID = &sessionID
+
+			out, err := uc.UndoReview(context.Background(), UndoReviewInput{UserID: "u1", WordID: "w1", Flip: tt.flip})
+			if err != nil {
+				t.Fatalf("undo: %v", err)
+			}
+
+			if tt.wantMoved {
+				if out.Session == nil || out.Session.Position != 0 || sess.Index != 0 {
+					t.Fatalf("expected the session back at item 0, got %+v (index %d)", out.Session, sess.Index)
+				}
+				return
+			}
+			if out.Session != nil || sess.Index != tt.index {
+				t.Fatalf("expected the session left at %d, got %+v (index %d)", tt.index, out.Session, sess.Index)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+	"github.com/sonsonha/eng-noting/internal/domain/schedule"
+	"github.com/sonsonha/eng-noting/internal/domain/settings"
+)
+
+// ReviewScheduler applies the scheduling algorithm each user has chosen
+type ReviewScheduler struct {
+	settingsRepo settings.SettingsRepository
+	scheduleRepo schedule.Repository
+}
+
+// NewReviewScheduler creates a new ReviewScheduler
+func NewReviewScheduler(settingsRepo settings.SettingsRepository, scheduleRepo schedule.Repository) *ReviewScheduler {
+	return &ReviewScheduler{
+		settingsRepo: settingsRepo,
+		scheduleRepo: scheduleRepo,
+	}
+}
+
+// For returns the scheduler of a user
+func (s *ReviewScheduler) For(ctx context.Context, userID string) (schedule.Scheduler, error) {
+	userSettings, err := s.settingsRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler, ok := schedule.New(userSettings.Scheduler)
+	if !ok {
+		scheduler, _ = schedule.New(schedule.DefaultAlgorithm)
+	}
+	return scheduler, nil
+}
+
+// States returns the memory states of all scheduled words of a user
+func (s *ReviewScheduler) States(ctx context.Context, userID string) (map[string]schedule.State, error) {
+	return s.scheduleRepo.List(ctx, userID)
+}
+
+// Record moves a word's memory state on after a review made at now. A
+// review older than the last one the state saw, synced late from an
+// offline client, is left out: the schedule cannot be moved back in time.
+func (s *ReviewScheduler) Record(ctx context.Context, userID, wordID string, rating schedule.Rating, now time.Time) error {
+	scheduler, err := s.For(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	state, err := s.scheduleRepo.Get(ctx, wordID)
+	if err != nil {
+		return err
+	}
+	if state.LastReviewedAt != nil && now.Before(*state.LastReviewedAt) {
+		return nil
+	}
+
+	return s.scheduleRepo.Save(ctx, wordID, scheduler.Next(state, rating, now))
+}
+
+// Rebuild replays a word's reviews, oldest first, through the user's
+// current scheduler and stores the state it reaches. A word with no
+// reviews left is no longer scheduled.
+func (s *ReviewScheduler) Rebuild(ctx context.Context, userID, wordID string, reviews []*review.Review) error {
+	if len(reviews) == 0 {
+		return s.scheduleRepo.Delete(ctx, wordID)
+	}
+
+	scheduler, err := s.For(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	rated := make([]schedule.Rated, len(reviews))
+	for i, r := range reviews {
+		rated[i] = schedule.Rated{
+			Rating: schedule.RatingFor(r.Result, r.Hesitant, r.Quality),
+			At:     r.ReviewedAt,
+		}
+	}
+	return s.scheduleRepo.Save(ctx, wordID, schedule.Replay(scheduler, rated))
+}
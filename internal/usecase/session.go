@@ -2,38 +2,84 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/exercise"
 	"github.com/sonsonha/eng-noting/internal/domain/review"
+	"github.com/sonsonha/eng-noting/internal/domain/schedule"
 	"github.com/sonsonha/eng-noting/internal/domain/session"
+	"github.com/sonsonha/eng-noting/internal/domain/settings"
 	"github.com/sonsonha/eng-noting/internal/domain/word"
 )
 
 // SessionUseCase handles session-related business logic
 type SessionUseCase struct {
+	sessionRepo   session.SessionRepository
+	expiry        session.Expiry
 	queueRepo     session.ReviewQueueRepository
 	wordStatsRepo word.WordStatsRepository
 	reviewRepo    review.ReviewRepository
 	mpsService    *MPSService
+	exercises     *ExerciseBuilder
+	scheduler     *ReviewScheduler
+	settingsRepo  settings.SettingsRepository
 }
 
-// NewSessionUseCase creates a new SessionUseCase
+// NewSessionUseCase creates a new SessionUseCase. Sessions are kept as
+// long as expiry says.
 func NewSessionUseCase(
+	sessionRepo session.SessionRepository,
+	expiry session.Expiry,
 	queueRepo session.ReviewQueueRepository,
 	wordStatsRepo word.WordStatsRepository,
 	reviewRepo review.ReviewRepository,
 	mpsService *MPSService,
+	exercises *ExerciseBuilder,
+	scheduler *ReviewScheduler,
+	settingsRepo settings.SettingsRepository,
 ) *SessionUseCase {
 	return &SessionUseCase{
+		sessionRepo:   sessionRepo,
+		expiry:        expiry,
 		queueRepo:     queueRepo,
 		wordStatsRepo: wordStatsRepo,
 		reviewRepo:    reviewRepo,
 		mpsService:    mpsService,
+		exercises:     exercises,
+		scheduler:     scheduler,
+		settingsRepo:  settingsRepo,
 	}
 }
 
 // StartSessionInput represents input for starting a session
 type StartSessionInput struct {
-	UserID string
+	UserID   string
+	TagID    string // restrict the session to one deck, if set
+	ForceNew bool   // start a new session even if one is in progress
+	Options  SessionOptions
+}
+
+// SessionOptions override the user's default session plan for one
+// session; zero fields keep the defaults
+type SessionOptions struct {
+	Size          *int
+	Minutes       *int
+	NewRatio      *float64
+	ReviewTypes   []string
+	TagIDs        []string
+	CEFRLevels    []string
+	PartsOfSpeech []string
+}
+
+// set reports whether any option was given
+func (o SessionOptions) set() bool {
+	return o.Size != nil || o.Minutes != nil || o.NewRatio != nil ||
+		len(o.ReviewTypes) > 0 || len(o.TagIDs) > 0 || len(o.CEFRLevels) > 0 || len(o.PartsOfSpeech) > 0
 }
 
 // StartSessionOutput represents output from starting a session
@@ -41,118 +87,398 @@ type StartSessionOutput struct {
 	SessionID string
 	Items     []session.SessionItem
 	Total     int
+	Index     int  // the item to continue from
+	Resumed   bool // an unfinished session was picked up rather than started
 }
 
-// StartSession starts a new review session
+// StartSession resumes the user's latest unfinished session over the same
+// deck, or starts a new review session if there is none or ForceNew is set.
+// A session asked for with options is always new.
 func (uc *SessionUseCase) StartSession(ctx context.Context, input StartSessionInput) (*StartSessionOutput, error) {
+	if !input.ForceNew && !input.Options.set() {
+		sess, err := uc.findActiveSession(ctx, input.UserID, input.TagID)
+		if err != nil {
+			return nil, err
+		}
+		if sess != nil {
+			return &StartSessionOutput{
+				SessionID: sess.ID,
+				Items:     sess.Items,
+				Total:     len(sess.Items),
+				Index:     sess.Index,
+				Resumed:   true,
+			}, nil
+		}
+	}
+
+	plan, err := uc.plan(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
 	// Rebuild review queue
 	if err := uc.rebuildReviewQueue(ctx, input.UserID); err != nil {
 		return nil, err
 	}
 
 	// Build session from queue
-	session, err := uc.buildSession(ctx, input.UserID)
+	session, err := uc.buildSession(ctx, input.UserID, plan)
 	if err != nil {
 		return nil, err
 	}
 
+	session.ID = uuid.NewString()
+	session.TagID = input.TagID
+	if err := uc.sessionRepo.Create(ctx, session, uc.expiry.At(session, session.Index)); err != nil {
+		return nil, err
+	}
+
 	return &StartSessionOutput{
-		SessionID: "", // Will be set by handler if needed
+		SessionID: session.ID,
 		Items:     session.Items,
 		Total:     len(session.Items),
 	}, nil
 }
 
-// rebuildReviewQueue rebuilds the review queue for a user
-func (uc *SessionUseCase) rebuildReviewQueue(ctx context.Context, userID string) error {
-	// Load word stats
-	stats, err := uc.wordStatsRepo.LoadStats(ctx, userID)
+// plan returns the user's default session plan with the input's options
+// applied
+func (uc *SessionUseCase) plan(ctx context.Context, input StartSessionInput) (session.Plan, error) {
+	userSettings, err := uc.settingsRepo.Get(ctx, input.UserID)
+	if err != nil {
+		return session.Plan{}, err
+	}
+
+	plan := userSettings.Session
+	opts := input.Options
+	if opts.Size != nil {
+		plan.Size = *opts.Size
+	}
+	if opts.Minutes != nil {
+		plan.Minutes = *opts.Minutes
+	}
+	if opts.NewRatio != nil {
+		plan.NewRatio = opts.NewRatio
+	}
+	if len(opts.ReviewTypes) > 0 {
+		plan.ReviewTypes = opts.ReviewTypes
+	}
+	if input.TagID != "" || len(opts.TagIDs) > 0 {
+		plan.Filter.TagIDs = opts.TagIDs
+		if input.TagID != "" {
+			plan.Filter.TagIDs = append([]string{input.TagID}, opts.TagIDs...)
+		}
+	}
+	if len(opts.CEFRLevels) > 0 {
+		plan.Filter.CEFRLevels = opts.CEFRLevels
+	}
+	if len(opts.PartsOfSpeech) > 0 {
+		plan.Filter.PartsOfSpeech = opts.PartsOfSpeech
+	}
+
+	if !plan.Valid() {
+		return session.Plan{}, ErrBadRequest
+	}
+	return plan, nil
+}
+
+// resumeScanLimit bounds how many active sessions are looked through for
+// one over the requested deck
+const resumeScanLimit = 20
+
+// findActiveSession returns the user's newest active session over the
+// deck, or nil if there is none
+func (uc *SessionUseCase) findActiveSession(ctx context.Context, userID, tagID string) (*session.Session, error) {
+	page, err := uc.sessionRepo.List(ctx, session.ListFilter{
+		UserID: userID,
+		Status: session.StatusActive,
+		Limit:  resumeScanLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, summary := range page.Sessions {
+		if summary.TagID != tagID {
+			continue
+		}
+		sess, err := uc.sessionRepo.Get(ctx, summary.ID)
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			// Expired since it was listed
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return sess, nil
+	}
+	return nil, nil
+}
+
+// ListSessionsInput represents input for listing sessions
+type ListSessionsInput struct {
+	UserID string
+	Status string // "active", "completed" or "abandoned"; empty for all
+	Limit  int
+	Cursor string // next_cursor of the previous page; empty for the first page
+}
+
+// ListSessionsOutput represents output from listing sessions
+type ListSessionsOutput struct {
+	Sessions   []session.Summary
+	NextCursor string // empty on the last page
+}
+
+// ListSessions returns a page of the user's sessions that have not
+// expired, newest first
+func (uc *SessionUseCase) ListSessions(ctx context.Context, input ListSessionsInput) (*ListSessionsOutput, error) {
+	filter := session.ListFilter{UserID: input.UserID, Status: session.Status(input.Status)}
+	if filter.Status != "" && !filter.Status.Valid() {
+		return nil, ErrBadRequest
+	}
+
+	limit, err := pageSize(input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = limit
+
+	if input.Cursor != "" {
+		after, err := decodeSessionCursor(input.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	page, err := uc.sessionRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListSessionsOutput{
+		Sessions:   page.Sessions,
+		NextCursor: encodeSessionCursor(page.Next),
+	}, nil
+}
+
+// AbandonSession gives up on a session so that starting a session no
+// longer resumes it. Completed sessions cannot be abandoned.
+func (uc *SessionUseCase) AbandonSession(ctx context.Context, userID, sessionID string) error {
+	sess, err := loadSession(ctx, uc.sessionRepo, userID, sessionID)
 	if err != nil {
 		return err
 	}
+	if sess.Status() == session.StatusCompleted {
+		return ErrConflict
+	}
 
-	// Calculate MPS for each word and build queue items
-	var queueItems []session.ReviewQueueItem
-	for _, stat := range stats {
-		mpsInput := CalculateMPSInput{
-			WordStats: stat,
+	return mapDomainError(uc.sessionRepo.Abandon(ctx, sess.ID, uc.expiry.Finished))
+}
+
+// CurrentItemOutput represents the current item of a session
+type CurrentItemOutput struct {
+	Item *session.SessionItem // nil once the session is done
+}
+
+// GetCurrentItem returns the item a session is at
+func (uc *SessionUseCase) GetCurrentItem(ctx context.Context, userID, sessionID string) (*CurrentItemOutput, error) {
+	sess, err := loadSession(ctx, uc.sessionRepo, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &CurrentItemOutput{Item: sess.Current()}, nil
+}
+
+// AdvanceSessionOutput represents output from advancing a session
+type AdvanceSessionOutput struct {
+	Done bool
+}
+
+// AdvanceSession moves a session past its current item and keeps it
+// alive. Answering an item already moves the session on, so this only
+// recovers a session whose current item was answered but which did not
+// move on; an item that was not answered cannot be skipped.
+func (uc *SessionUseCase) AdvanceSession(ctx context.Context, userID, sessionID string) (*AdvanceSessionOutput, error) {
+	sess, err := loadSession(ctx, uc.sessionRepo, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if sess.AbandonedAt != nil {
+		return nil, ErrConflict
+	}
+	item := sess.Current()
+	if item == nil {
+		return &AdvanceSessionOutput{Done: true}, nil
+	}
+
+	answered, err := uc.answered(ctx, sess.ID, item)
+	if err != nil {
+		return nil, err
+	}
+	if !answered {
+		return nil, ErrConflict
+	}
+
+	index, err := uc.sessionRepo.Advance(ctx, sess.ID, sess.Index, uc.expiry.At(sess, sess.Index+1))
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	return &AdvanceSessionOutput{Done: index >= len(sess.Items)}, nil
+}
+
+// answered reports whether a review of the item was made in the session
+func (uc *SessionUseCase) answered(ctx context.Context, sessionID string, item *session.SessionItem) (bool, error) {
+	reviews, err := uc.reviewRepo.ListBySession(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range reviews {
+		if slices.Contains(item.WordIDs(), r.WordID) {
+			return true, nil
 		}
-		mpsOutput, mpsReason := uc.mpsService.CalculateMPS(mpsInput)
+	}
+	return false, nil
+}
 
+// loadSession returns a session of the user
+func loadSession(ctx context.Context, sessionRepo session.SessionRepository, userID, sessionID string) (*session.Session, error) {
+	sess, err := sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+	if sess.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return sess, nil
+}
+
+// rebuildReviewQueue rebuilds the review queue for a user, ranking words
+// with the user's scheduler
+func (uc *SessionUseCase) rebuildReviewQueue(ctx context.Context, userID string) error {
+	ranked, err := uc.rankWords(ctx, userID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var queueItems []session.ReviewQueueItem
+	for _, w := range ranked {
 		// Skip low priority words
-		if mpsOutput.Score < 30 {
+		if w.priority < session.QueueCutoff {
 			continue
 		}
 
 		queueItems = append(queueItems, session.ReviewQueueItem{
 			UserID:        userID,
-			WordID:        stat.WordID,
-			PriorityScore: mpsOutput.Score,
-			Reason:        mpsReason,
+			WordID:        w.wordID,
+			PriorityScore: w.priority,
+			Reason:        w.reason,
 		})
 	}
 
 	return uc.queueRepo.Rebuild(ctx, userID, queueItems)
 }
 
-// buildSession builds a session from the review queue
-func (uc *SessionUseCase) buildSession(ctx context.Context, userID string) (*session.Session, error) {
-	queueItems, err := uc.queueRepo.GetQueueItems(ctx, userID)
+// rankedWord is a word ranked for review
+type rankedWord struct {
+	wordID   string
+	priority float64
+	reason   string
+	state    schedule.State
+}
+
+// rankWords ranks each of the user's words for review at now
+func (uc *SessionUseCase) rankWords(ctx context.Context, userID string, now time.Time) ([]rankedWord, error) {
+	scheduler, err := uc.scheduler.For(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	const (
-		MaxCritical = 5
-		MaxNormal   = 5
-	)
+	states, err := uc.scheduler.States(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	var critical []session.SessionItem
-	var normal []session.SessionItem
+	// Load word stats
+	stats, err := uc.wordStatsRepo.LoadStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]rankedWord, len(stats))
+	for i, stat := range stats {
+		card := schedule.Card{
+			State: states[stat.WordID],
+			Stats: uc.mpsService.Stats(stat),
+		}
+		priority, reason := scheduler.Priority(card, now)
+		ranked[i] = rankedWord{wordID: stat.WordID, priority: priority, reason: reason, state: card.State}
+	}
+	return ranked, nil
+}
+
+// buildSession builds a session from the review queue following plan
+func (uc *SessionUseCase) buildSession(ctx context.Context, userID string, plan session.Plan) (*session.Session, error) {
+	queueItems, err := uc.queueRepo.GetQueueItems(ctx, userID, plan.Filter)
+	if err != nil {
+		return nil, err
+	}
 
+	// Load the statistics of every eligible word at once rather than per word
+	var wordIDs []string
 	for _, item := range queueItems {
-		// Get review stats for this word
-		stats, err := uc.reviewRepo.GetStats(ctx, item.WordID)
-		if err != nil {
-			continue
+		if item.PriorityScore >= session.NormalPriority {
+			wordIDs = append(wordIDs, item.WordID)
 		}
+	}
+	allStats, err := uc.reviewRepo.ListStats(ctx, wordIDs)
+	if err != nil {
+		return nil, err
+	}
+	lastTypes, err := uc.reviewRepo.LastReviewTypes(ctx, wordIDs)
+	if err != nil {
+		return nil, err
+	}
 
-		// Get last review type
-		lastReviewType, _ := uc.reviewRepo.GetLastReviewType(ctx, item.WordID)
+	var candidates []session.Candidate
+	selections := make(map[string]typeSelection)
 
-		// Calculate review type
+	for _, item := range queueItems {
+		if item.PriorityScore < session.NormalPriority {
+			continue
+		}
+		stats := allStats[item.WordID]
+		lastReviewType := lastTypes[item.WordID]
+
+		// Calculate review type, keeping to the plan's types
 		reviewCtx := review.Context{
 			MPS:            item.PriorityScore,
 			AccuracyRate:   stats.AccuracyRate,
 			TotalReviews:   stats.TotalReviews,
+			HesitationRate: stats.HesitationRate,
 			LastReviewType: lastReviewType,
 		}
-		reviewType := review.SelectType(reviewCtx)
+		reviewType := review.Restrict(review.SelectType(reviewCtx), plan.ReviewTypes)
 
 		// Enhance reason with review-specific reason
-		reviewReason := review.Reason(reviewCtx, reviewType)
-		enhancedReason := item.Reason + ". " + reviewReason
-
-		sessionItem := session.SessionItem{
-			WordID:        item.WordID,
-			ReviewType:    reviewType,
-			PriorityScore: item.PriorityScore,
-			Reason:        enhancedReason,
-		}
-
-		switch {
-		case item.PriorityScore >= 60 && len(critical) < MaxCritical:
-			critical = append(critical, sessionItem)
-		case item.PriorityScore >= 40 && len(normal) < MaxNormal:
-			normal = append(normal, sessionItem)
-		}
+		selection := typeSelection{queueReason: item.Reason, reviewCtx: reviewCtx}
+		selections[item.WordID] = selection
 
-		if len(critical) == MaxCritical && len(normal) == MaxNormal {
-			break
-		}
+		candidates = append(candidates, session.Candidate{
+			Item: session.SessionItem{
+				WordID:        item.WordID,
+				ReviewType:    reviewType,
+				PriorityScore: item.PriorityScore,
+				Reason:        selection.reason(reviewType),
+			},
+			New: stats.TotalReviews == 0,
+		})
 	}
 
-	items := append(critical, normal...)
+	items, err := uc.bundleMatches(ctx, userID, plan.Pick(candidates), selections, plan.ReviewTypes)
+	if err != nil {
+		return nil, err
+	}
+	uc.attachExercises(ctx, userID, items, selections, plan.ReviewTypes)
 
 	return &session.Session{
 		UserID: userID,
@@ -160,3 +486,135 @@ func (uc *SessionUseCase) buildSession(ctx context.Context, userID string) (*ses
 		Index:  0,
 	}, nil
 }
+
+// typeSelection is what a session item's review type was chosen from
+type typeSelection struct {
+	queueReason string
+	reviewCtx   review.Context
+}
+
+// reason combines why the word is due with why it gets this review type
+func (s typeSelection) reason(reviewType string) string {
+	return s.queueReason + ". " + review.Reason(s.reviewCtx, reviewType)
+}
+
+// matchBoardReason explains a matching board, which covers several words
+const matchBoardReason = "You recognize these words — match each one with its meaning"
+
+// bundleMatches replaces the "match" items with matching boards, each
+// placed where its first word was. Match words that fit on no board are
+// reviewed as the nearest other allowed type instead, "mcq" if any is.
+func (uc *SessionUseCase) bundleMatches(
+	ctx context.Context,
+	userID string,
+	items []session.SessionItem,
+	selections map[string]typeSelection,
+	allowed []string,
+) ([]session.SessionItem, error) {
+	var matchIDs []string
+	for _, item := range items {
+		if item.ReviewType == "match" {
+			matchIDs = append(matchIDs, item.WordID)
+		}
+	}
+	if len(matchIDs) == 0 {
+		return items, nil
+	}
+
+	boards, _, err := uc.exercises.BuildMatchBoards(ctx, userID, matchIDs)
+	if err != nil {
+		return nil, err
+	}
+	boardOf := make(map[string]*exercise.MatchBoard)
+	for _, board := range boards {
+		for _, w := range board.Words {
+			boardOf[w.ID] = board
+		}
+	}
+
+	bundled := make([]session.SessionItem, 0, len(items))
+	placed := make(map[*exercise.MatchBoard]int) // board -> index in bundled
+	for _, item := range items {
+		if item.ReviewType != "match" {
+			bundled = append(bundled, item)
+			continue
+		}
+
+		board, ok := boardOf[item.WordID]
+		if !ok {
+			item.ReviewType = unbundledType(allowed)
+			item.Reason = selections[item.WordID].reason(item.ReviewType)
+			bundled = append(bundled, item)
+			continue
+		}
+
+		if i, ok := placed[board]; ok {
+			bundled[i].PriorityScore = max(bundled[i].PriorityScore, item.PriorityScore)
+			continue
+		}
+		placed[board] = len(bundled)
+		bundled = append(bundled, session.SessionItem{
+			ReviewType:    "match",
+			PriorityScore: item.PriorityScore,
+			Reason:        matchBoardReason,
+			Match:         board,
+		})
+	}
+
+	return bundled, nil
+}
+
+// unbundledType is the review type of a match word that fits on no board
+func unbundledType(allowed []string) string {
+	for _, t := range review.Types {
+		if t != "match" && (len(allowed) == 0 || slices.Contains(allowed, t)) {
+			return t
+		}
+	}
+	// Only matching is allowed; a single word can still be asked as a
+	// multiple choice question
+	return "mcq"
+}
+
+// attachExercises builds the exercise content of each item. An item whose
+// content cannot be built is given a review type that needs none, so it is
+// never served as an exercise the client cannot show.
+func (uc *SessionUseCase) attachExercises(
+	ctx context.Context,
+	userID string,
+	items []session.SessionItem,
+	selections map[string]typeSelection,
+	allowed []string,
+) {
+	for i := range items {
+		item := &items[i]
+		switch item.ReviewType {
+		case "mcq":
+			q, err := uc.exercises.BuildMCQ(ctx, userID, item.WordID)
+			if err != nil {
+				log.Printf("[WARN] failed to build the question for word %s: %v", item.WordID, err)
+			}
+			if item.MCQ = q; q != nil {
+				continue
+			}
+		case "fill_blank":
+			c, err := uc.exercises.BuildCloze(ctx, userID, item.WordID)
+			if err != nil {
+				log.Printf("[WARN] failed to build the cloze for word %s: %v", item.WordID, err)
+			}
+			if item.Cloze = c; c != nil {
+				continue
+			}
+		default:
+			continue
+		}
+
+		reviewType, err := uc.exercises.PlainType(ctx, userID, item.WordID, allowed)
+		if err != nil {
+			log.Printf("[WARN] failed to pick a review type for word %s: %v", item.WordID, err)
+			reviewType = review.Recall
+		}
+		item.ReviewType = reviewType
+		item.Reason = selections[item.WordID].reason(reviewType)
+	}
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+)
+
+// SessionCleaner deletes expired review sessions in the background.
+// Expired sessions are already hidden from reads; this only reclaims
+// their rows.
+type SessionCleaner struct {
+	sessionRepo session.SessionRepository
+	interval    time.Duration
+}
+
+// NewSessionCleaner creates a new SessionCleaner that runs every interval
+func NewSessionCleaner(sessionRepo session.SessionRepository, interval time.Duration) *SessionCleaner {
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	return &SessionCleaner{sessionRepo: sessionRepo, interval: interval}
+}
+
+// Run deletes expired sessions until ctx is cancelled
+func (c *SessionCleaner) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.interval):
+		}
+
+		n, err := c.sessionRepo.DeleteExpired(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("[ERROR] failed to delete expired sessions: %v", err)
+			}
+			continue
+		}
+		if n > 0 {
+			log.Printf("[INFO] deleted %d expired sessions", n)
+		}
+	}
+}
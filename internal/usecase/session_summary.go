@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+)
+
+// SessionSummaryOutput represents the summary of a session
+type SessionSummaryOutput struct {
+	SessionID string
+	Status    session.Status
+	Items     []session.ItemOutcome
+	Reviewed  int            // words reviewed
+	Correct   int            // words whose last answer was right
+	TimeSpent *time.Duration // answer time summed over the session, if reported
+	Mastered  []string       // IDs of words answered right that no longer need reviewing
+	Slipped   []string       // IDs of known words that were missed and lost accuracy
+	Next      session.Suggestion
+}
+
+// GetSessionSummary sums up how each item of a session went. It can be
+// asked for at any point; items not reviewed yet are reported as such.
+func (uc *SessionUseCase) GetSessionSummary(ctx context.Context, userID, sessionID string) (*SessionSummaryOutput, error) {
+	sess, err := loadSession(ctx, uc.sessionRepo, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := uc.reviewRepo.ListBySession(ctx, sess.ID)
+	if err != nil {
+		return nil, err
+	}
+	accuracy, err := uc.reviewRepo.SessionAccuracy(ctx, sess.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ranked, err := uc.rankWords(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	priorities := make(map[string]float64, len(ranked))
+	dueNow := 0
+	var nextDueAt *time.Time
+	for _, w := range ranked {
+		priorities[w.wordID] = w.priority
+		if w.priority >= session.QueueCutoff {
+			dueNow++
+		}
+		if due := w.state.DueAt; due != nil && due.After(now) && (nextDueAt == nil || due.Before(*nextDueAt)) {
+			nextDueAt = due
+		}
+	}
+
+	// Each word appears in one item; a board's words share its priority
+	output := &SessionSummaryOutput{SessionID: sess.ID, Status: sess.Status()}
+	for i, item := range sess.Items {
+		itemOutcome := session.ItemOutcome{Position: i, ReviewType: item.ReviewType}
+		for _, wordID := range item.WordIDs() {
+			o := session.WordOutcome{
+				WordID:         wordID,
+				PriorityBefore: item.PriorityScore,
+				PriorityAfter:  priorities[wordID],
+			}
+			if change, ok := accuracy[wordID]; ok {
+				o.AccuracyBefore = change.Before
+				o.AccuracyAfter = change.After
+			}
+			itemOutcome.Words = append(itemOutcome.Words, o)
+		}
+		output.Items = append(output.Items, itemOutcome)
+	}
+
+	outcomes := make(map[string]*session.WordOutcome)
+	for i := range output.Items {
+		for j := range output.Items[i].Words {
+			o := &output.Items[i].Words[j]
+			outcomes[o.WordID] = o
+		}
+	}
+
+	for _, r := range reviews {
+		if o, ok := outcomes[r.WordID]; ok {
+			o.Record(r.Result, r.Score, r.Latency)
+		}
+	}
+
+	for _, item := range output.Items {
+		for _, o := range item.Words {
+			if !o.Reviewed() {
+				continue
+			}
+			output.Reviewed++
+			if o.Correct {
+				output.Correct++
+			}
+			if o.TimeSpent != nil {
+				spent := *o.TimeSpent
+				if output.TimeSpent != nil {
+					spent += *output.TimeSpent
+				}
+				output.TimeSpent = &spent
+			}
+			if o.Mastered() {
+				output.Mastered = append(output.Mastered, o.WordID)
+			}
+			if o.Slipped() {
+				output.Slipped = append(output.Slipped, o.WordID)
+			}
+		}
+	}
+
+	output.Next = session.Suggest(len(output.Slipped), dueNow, nextDueAt)
+	return output, nil
+}
@@ -0,0 +1,381 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// testExpiry keeps idle sessions an hour and finished ones a day
+var testExpiry = session.Expiry{Idle: time.Hour, Finished: 24 * time.Hour}
+
+// fakeWordStats has no words to rank
+type fakeWordStats struct{}
+
+func (fakeWordStats) LoadStats(ctx context.Context, userID string) ([]word.WordStats, error) {
+	return nil, nil
+}
+
+func newSessionUseCase(s *store, sessions *fakeSessionRepo) *SessionUseCase {
+	scheduler := NewReviewScheduler(fakeSettingsRepo{}, fakeScheduleRepo{s: s})
+	return NewSessionUseCase(sessions, testExpiry, nil, fakeWordStats{}, fakeReviewRepo{s: s}, NewMPSService(), nil, scheduler, fakeSettingsRepo{})
+}
+
+// answeredSession is a session of u1 over w1 then w2 whose first item was
+// answered right
+func answeredSession(s *store) *session.Session {
+	sessionID := "s1"
+	addReview(s, "r1", time.Minute, true, 1).SessionID = &sessionID
+	return &session.Session{
+		ID:     sessionID,
+		UserID: "u1",
+		Items:  []session.SessionItem{{WordID: "w1", ReviewType: "typing"}, {WordID: "w2", ReviewType: "typing"}},
+	}
+}
+
+func TestSessionSummaryOutlivesIdleTTL(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(uc *SessionUseCase) error
+		want   session.Status
+	}{
+		{"completed", func(uc *SessionUseCase) error {
+			_, err := uc.AdvanceSession(context.Background(), "u1", "s1")
+			return err
+		}, session.StatusCompleted},
+		{"abandoned", func(uc *SessionUseCase) error {
+			return uc.AbandonSession(context.Background(), "u1", "s1")
+		}, session.StatusAbandoned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore()
+			sess := answeredSession(s)
+			sess.Items = sess.Items[:1]
+			sessions := newFakeSessionRepo(sess)
+			uc := newSessionUseCase(s, sessions)
+			if err := tt.finish(uc); err != nil {
+				t.Fatalf("finish: %v", err)
+			}
+
+			sessions.elapse(2 * testExpiry.Idle)
+			out, err := uc.GetSessionSummary(context.Background(), "u1", "s1")
+			if err != nil {
+				t.Fatalf("summary: %v", err)
+			}
+			if out.Status != tt.want || out.Reviewed != 1 || out.Correct != 1 {
+				t.Errorf("unexpected summary %+v", out)
+			}
+
+			sessions.elapse(testExpiry.Finished)
+			if _, err := uc.GetSessionSummary(context.Background(), "u1", "s1"); err != ErrNotFound {
+				t.Fatalf("expected ErrNotFound after retention, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSessionSummaryExpiresWhenIdle(t *testing.T) {
+	s := newStore()
+	sessions := newFakeSessionRepo(answeredSession(s))
+	uc := newSessionUseCase(s, sessions)
+
+	// Moving on to the second item keeps the session for the idle TTL only
+	out, err := uc.AdvanceSession(context.Background(), "u1", "s1")
+	if err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if out.Done {
+		t.Fatal("expected an item left")
+	}
+	sessions.elapse(testExpiry.Idle)
+
+	if _, err := uc.GetSessionSummary(context.Background(), "u1", "s1"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSessionSummaryCountsReviews(t *testing.T) {
+	s := newStore()
+	sess := answeredSession(s)
+	sess.Index = 1
+	uc := newSessionUseCase(s, newFakeSessionRepo(sess))
+
+	out, err := uc.GetSessionSummary(context.Background(), "u1", "s1")
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if out.Status != session.StatusActive || len(out.Items) != 2 || out.Reviewed != 1 || out.Correct != 1 {
+		t.Fatalf("unexpected summary %+v", out)
+	}
+	if w := out.Items[1].Words[0]; w.WordID != "w2" || w.Reviewed() {
+		t.Errorf("expected w2 not reviewed yet, got %+v", w)
+	}
+
+	if _, err := uc.GetSessionSummary(context.Background(), "u2", "s1"); err != ErrForbidden {
+		t.Fatalf("another user: expected ErrForbidden, got %v", err)
+	}
+}
+
+// listedSession is a session of u1 over one word, created ago
+func listedSession(id string, ago time.Duration) *session.Session {
+	return &session.Session{
+		ID:        id,
+		UserID:    "u1",
+		Items:     []session.SessionItem{{WordID: "w1", ReviewType: "typing"}},
+		CreatedAt: time.Now().Add(-ago),
+	}
+}
+
+const (
+	sessionA = "00000000-0000-0000-0000-00000000000a"
+	sessionB = "00000000-0000-0000-0000-00000000000b"
+	sessionC = "00000000-0000-0000-0000-00000000000c"
+)
+
+func TestListSessionsKeepsFinishedSessions(t *testing.T) {
+	s := newStore()
+	sessions := newFakeSessionRepo(
+		listedSession(sessionA, 3*time.Minute),
+		listedSession(sessionB, 2*time.Minute),
+		listedSession(sessionC, time.Minute),
+	)
+	sessions.ttls[sessionA] = testExpiry.Idle
+	uc := newSessionUseCase(s, sessions)
+
+	// B is completed and C abandoned; A is left idle
+	sessionID := sessionB
+	addReview(s, "r1", time.Minute, true, 1).SessionID = &sessionID
+	if _, err := uc.AdvanceSession(context.Background(), "u1", sessionB); err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if err := uc.AbandonSession(context.Background(), "u1", sessionC); err != nil {
+		t.Fatalf("abandon: %v", err)
+	}
+	sessions.elapse(2 * testExpiry.Idle)
+
+	out, err := uc.ListSessions(context.Background(), ListSessionsInput{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(out.Sessions) != 2 || out.Sessions[0].ID != sessionC || out.Sessions[1].ID != sessionB {
+		t.Fatalf("expected the finished sessions listed, got %+v", out.Sessions)
+	}
+	if out.Sessions[0].Status() != session.StatusAbandoned || out.Sessions[1].Status() != session.StatusCompleted {
+		t.Errorf("unexpected statuses %+v", out.Sessions)
+	}
+}
+
+func TestListSessionsPages(t *testing.T) {
+	sessions := newFakeSessionRepo(
+		listedSession(sessionA, 3*time.Minute),
+		listedSession(sessionB, 2*time.Minute),
+		listedSession(sessionC, time.Minute),
+	)
+	uc := newSessionUseCase(newStore(), sessions)
+
+	first, err := uc.ListSessions(context.Background(), ListSessionsInput{UserID: "u1", Limit: 2})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(first.Sessions) != 2 || first.Sessions[0].ID != sessionC || first.NextCursor == "" {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+
+	second, err := uc.ListSessions(context.Background(), ListSessionsInput{UserID: "u1", Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(second.Sessions) != 1 || second.Sessions[0].ID != sessionA || second.NextCursor != "" {
+		t.Fatalf("unexpected second page %+v", second)
+	}
+
+	for _, input := range []ListSessionsInput{
+		{UserID: "u1", Status: "paused"},
+		{UserID: "u1", Cursor: "not-a-cursor"},
+	} {
+		if _, err := uc.ListSessions(context.Background(), input); err != ErrBadRequest {
+			t.Errorf("%+v: expected ErrBadRequest, got %v", input, err)
+		}
+	}
+}
+
+func TestStartSessionResumesActiveSessionOverDeck(t *testing.T) {
+	otherDeck := listedSession(sessionC, time.Minute)
+	otherDeck.TagID = "t2"
+	completed := listedSession(sessionB, 2*time.Minute)
+	completed.TagID, completed.Index = "t1", 1
+	active := listedSession(sessionA, 3*time.Minute)
+	active.TagID = "t1"
+	active.Items = append(active.Items, session.SessionItem{WordID: "w2", ReviewType: "typing"})
+	active.Index = 1
+	uc := newSessionUseCase(newStore(), newFakeSessionRepo(otherDeck, completed, active))
+
+	out, err := uc.StartSession(context.Background(), StartSessionInput{UserID: "u1", TagID: "t1"})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if !out.Resumed || out.SessionID != sessionA || out.Index != 1 || out.Total != 2 {
+		t.Fatalf("expected the active session over t1 resumed, got %+v", out)
+	}
+}
+
+// queuedWords is a review queue of fixed items
+type queuedWords []session.ReviewQueueItem
+
+func (q queuedWords) Rebuild(ctx context.Context, userID string, items []session.ReviewQueueItem) error {
+	return nil
+}
+
+func (q queuedWords) GetQueueItems(ctx context.Context, userID string, filter session.QueueFilter) ([]session.ReviewQueueItem, error) {
+	return q, nil
+}
+
+// knownWords holds the user's words by ID
+type knownWords struct {
+	word.WordRepository
+	words map[string]*word.Word
+}
+
+func (r knownWords) GetByID(ctx context.Context, wordID, userID string) (*word.Word, error) {
+	w, ok := r.words[wordID]
+	if !ok {
+		return nil, domain.ErrWordNotFound
+	}
+	return w, nil
+}
+
+// startSessionOver starts a session over words that have no distractors
+// to offer, allowing reviewTypes; their review stats are those in s
+func startSessionOver(t *testing.T, s *store, words map[string]*word.Word, reviewTypes []string) []session.SessionItem {
+	t.Helper()
+	var queue queuedWords
+	for id := range words {
+		queue = append(queue, session.ReviewQueueItem{UserID: "u1", WordID: id, PriorityScore: 50, Reason: "New word"})
+	}
+	scheduler := NewReviewScheduler(fakeSettingsRepo{}, fakeScheduleRepo{s: s})
+	exercises := NewExerciseBuilder(knownWords{words: words}, &distractorCache{}, &clozeCache{}, nil)
+	uc := NewSessionUseCase(newFakeSessionRepo(), testExpiry, queue, fakeWordStats{}, fakeReviewRepo{s: s}, NewMPSService(), exercises, scheduler, fakeSettingsRepo{})
+
+	out, err := uc.StartSession(context.Background(), StartSessionInput{
+		UserID:  "u1",
+		Options: SessionOptions{ReviewTypes: reviewTypes},
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if len(out.Items) != len(words) {
+		t.Fatalf("expected %d items, got %+v", len(words), out.Items)
+	}
+	return out.Items
+}
+
+func TestSessionAsksNewWordWithoutQuestionHonestly(t *testing.T) {
+	tests := []struct {
+		name        string
+		word        *word.Word
+		reviewTypes []string
+		want        string
+	}{
+		{"no definition", &word.Word{ID: "w1", Text: "resilient"}, nil, review.Recall},
+		{"definition only", &word.Word{ID: "w1", Text: "resilient", AIData: &word.WordAIData{Definition: "able to recover quickly"}}, nil, "typing"},
+		{"typing not allowed", &word.Word{ID: "w1", Text: "resilient", AIData: &word.WordAIData{Definition: "able to recover quickly"}}, []string{"mcq"}, review.Recall},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := startSessionOver(t, newStore(), map[string]*word.Word{"w1": tt.word}, tt.reviewTypes)
+
+			// Without distractors no question can be built, and the word
+			// is not served as a question with nothing to choose from
+			item := items[0]
+			if item.ReviewType != tt.want || item.MCQ != nil {
+				t.Fatalf("expected a %s item, got %+v", tt.want, item)
+			}
+			if want := "New word. " + review.Reason(review.Context{}, tt.want); item.Reason != want {
+				t.Errorf("expected reason %q, got %q", want, item.Reason)
+			}
+		})
+	}
+}
+
+func TestSessionAsksWordWithoutClozeAsTyping(t *testing.T) {
+	// A well-known word whose only context is too short for a cloze, and
+	// no AI to write a sentence
+	s := newStore()
+	s.stats["w1"] = review.ReviewStats{TotalReviews: 6, CorrectReviews: 6, AccuracyRate: 1}
+	w := &word.Word{
+		ID:       "w1",
+		Text:     "resilient",
+		AIData:   &word.WordAIData{Definition: "able to recover quickly"},
+		Contexts: []word.WordContext{{Sentence: "So resilient."}},
+	}
+	items := startSessionOver(t, s, map[string]*word.Word{"w1": w}, nil)
+
+	item := items[0]
+	if item.ReviewType != "typing" || item.Cloze != nil {
+		t.Fatalf("expected a typing item, got %+v", item)
+	}
+	if !strings.HasSuffix(item.Reason, review.Reason(review.Context{}, "typing")) {
+		t.Errorf("expected the typing reason, got %q", item.Reason)
+	}
+}
+
+// deckQueue is a review queue of fixed items that records the filters it
+// was asked for
+type deckQueue struct {
+	queuedWords
+	filters []session.QueueFilter
+}
+
+func (q *deckQueue) GetQueueItems(ctx context.Context, userID string, filter session.QueueFilter) ([]session.ReviewQueueItem, error) {
+	q.filters = append(q.filters, filter)
+	return q.queuedWords, nil
+}
+
+const deckA = "00000000-0000-0000-0000-0000000000da"
+
+func TestDeckSessionResumesOnlyOverSameDeck(t *testing.T) {
+	s := newStore()
+	words := map[string]*word.Word{"w1": {ID: "w1", Text: "resilient"}}
+	queue := &deckQueue{queuedWords: queuedWords{{UserID: "u1", WordID: "w1", PriorityScore: 50, Reason: "New word"}}}
+	scheduler := NewReviewScheduler(fakeSettingsRepo{}, fakeScheduleRepo{s: s})
+	exercises := NewExerciseBuilder(knownWords{words: words}, &distractorCache{}, &clozeCache{}, nil)
+	uc := NewSessionUseCase(newFakeSessionRepo(), testExpiry, queue, fakeWordStats{}, fakeReviewRepo{s: s}, NewMPSService(), exercises, scheduler, fakeSettingsRepo{})
+	ctx := context.Background()
+
+	deck, err := uc.StartSession(ctx, StartSessionInput{UserID: "u1", TagID: deckA})
+	if err != nil {
+		t.Fatalf("start deck session: %v", err)
+	}
+	if len(queue.filters) != 1 || !slices.Equal(queue.filters[0].TagIDs, []string{deckA}) {
+		t.Fatalf("expected the queue restricted to the deck, got %+v", queue.filters)
+	}
+
+	all, err := uc.StartSession(ctx, StartSessionInput{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("start session: %v", err)
+	}
+	if all.Resumed || all.SessionID == deck.SessionID {
+		t.Fatalf("expected a new session over all words, got %+v", all)
+	}
+	if len(queue.filters) != 2 || len(queue.filters[1].TagIDs) != 0 {
+		t.Fatalf("expected the queue unrestricted, got %+v", queue.filters)
+	}
+
+	again, err := uc.StartSession(ctx, StartSessionInput{UserID: "u1", TagID: deckA})
+	if err != nil {
+		t.Fatalf("resume deck session: %v", err)
+	}
+	if !again.Resumed || again.SessionID != deck.SessionID {
+		t.Fatalf("expected the deck session resumed, got %+v", again)
+	}
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/sonsonha/eng-noting/internal/domain/schedule"
+	"github.com/sonsonha/eng-noting/internal/domain/session"
+	"github.com/sonsonha/eng-noting/internal/domain/settings"
+)
+
+// SettingsUseCase handles user settings
+type SettingsUseCase struct {
+	settingsRepo settings.SettingsRepository
+}
+
+// NewSettingsUseCase creates a new SettingsUseCase
+func NewSettingsUseCase(settingsRepo settings.SettingsRepository) *SettingsUseCase {
+	return &SettingsUseCase{settingsRepo: settingsRepo}
+}
+
+// SettingsOutput represents the settings returned by the use case
+type SettingsOutput struct {
+	Settings *settings.Settings
+}
+
+// GetSettings returns the settings of a user
+func (uc *SettingsUseCase) GetSettings(ctx context.Context, userID string) (*SettingsOutput, error) {
+	s, err := uc.settingsRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &SettingsOutput{Settings: s}, nil
+}
+
+// UpdateSettingsInput represents input for updating settings; nil fields
+// are left unchanged
+type UpdateSettingsInput struct {
+	UserID    string
+	Scheduler *string
+	Session   *session.Plan // replaces the whole default session plan
+}
+
+// UpdateSettings changes the settings of a user. Switching the scheduler
+// keeps past reviews; words are scheduled by the new algorithm from their
+// next review on.
+func (uc *SettingsUseCase) UpdateSettings(ctx context.Context, input UpdateSettingsInput) (*SettingsOutput, error) {
+	s, err := uc.settingsRepo.Get(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Scheduler != nil {
+		alg, ok := schedule.ParseAlgorithm(*input.Scheduler)
+		if !ok {
+			return nil, ErrBadRequest
+		}
+		s.Scheduler = alg
+	}
+
+	if input.Session != nil {
+		if !input.Session.Valid() {
+			return nil, ErrBadRequest
+		}
+		s.Session = *input.Session
+	}
+
+	if err := uc.settingsRepo.Save(ctx, s); err != nil {
+		return nil, err
+	}
+	return &SettingsOutput{Settings: s}, nil
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/sonsonha/eng-noting/internal/domain/tag"
+	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// TagUseCase handles tag- and deck-related business logic
+type TagUseCase struct {
+	tagRepo tag.TagRepository
+}
+
+// NewTagUseCase creates a new TagUseCase
+func NewTagUseCase(tagRepo tag.TagRepository) *TagUseCase {
+	return &TagUseCase{tagRepo: tagRepo}
+}
+
+// CreateTagInput represents input for creating a tag
+type CreateTagInput struct {
+	UserID string
+	Name   string
+}
+
+// TagOutput represents a single tag returned by the use case
+type TagOutput struct {
+	Tag *tag.Tag
+}
+
+// CreateTag creates a new tag. Names are unique per user, ignoring case.
+func (uc *TagUseCase) CreateTag(ctx context.Context, input CreateTagInput) (*TagOutput, error) {
+	name, err := cleanTagName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tag.Tag{
+		ID:        uuid.NewString(),
+		UserID:    input.UserID,
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+
+	if err := uc.tagRepo.Create(ctx, t); err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	return &TagOutput{Tag: t}, nil
+}
+
+// ListTagsOutput represents output from listing tags
+type ListTagsOutput struct {
+	Tags []*tag.Tag
+}
+
+// ListTags returns all tags of a user with their word counts
+func (uc *TagUseCase) ListTags(ctx context.Context, userID string) (*ListTagsOutput, error) {
+	tags, err := uc.tagRepo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &ListTagsOutput{Tags: tags}, nil
+}
+
+// RenameTagInput represents input for renaming a tag
+type RenameTagInput struct {
+	UserID string
+	TagID  string
+	Name   string
+}
+
+// RenameTag changes the name of a tag
+func (uc *TagUseCase) RenameTag(ctx context.Context, input RenameTagInput) (*TagOutput, error) {
+	name, err := cleanTagName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.tagRepo.Rename(ctx, input.TagID, input.UserID, name); err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	t, err := uc.tagRepo.GetByID(ctx, input.TagID, input.UserID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	return &TagOutput{Tag: t}, nil
+}
+
+// TagRefInput identifies a single tag owned by a user
+type TagRefInput struct {
+	UserID string
+	TagID  string
+}
+
+// DeleteTag removes a tag without touching the words in it
+func (uc *TagUseCase) DeleteTag(ctx context.Context, input TagRefInput) error {
+	return mapDomainError(uc.tagRepo.Delete(ctx, input.TagID, input.UserID))
+}
+
+// WordTagInput identifies a word and a tag owned by the same user
+type WordTagInput struct {
+	UserID string
+	WordID string
+	TagID  string
+}
+
+// TagWord adds a word to a tag
+func (uc *TagUseCase) TagWord(ctx context.Context, input WordTagInput) error {
+	return mapDomainError(uc.tagRepo.AddWord(ctx, input.TagID, input.WordID, input.UserID))
+}
+
+// UntagWord removes a word from a tag
+func (uc *TagUseCase) UntagWord(ctx context.Context, input WordTagInput) error {
+	return mapDomainError(uc.tagRepo.RemoveWord(ctx, input.TagID, input.WordID, input.UserID))
+}
+
+func cleanTagName(name string) (string, error) {
+	name = wordDomain.CleanText(name)
+	if name == "" || utf8.RuneCountInString(name) > tag.MaxNameLength {
+		return "", ErrBadRequest
+	}
+	return name, nil
+}
@@ -0,0 +1,10 @@
+package usecase
+
+import "context"
+
+// TxManager runs units of work atomically: the changes repositories make
+// through the context fn receives are all applied, or none is if fn
+// returns an error
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
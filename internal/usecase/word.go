@@ -2,87 +2,189 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
-	"github.com/sonsonha/eng-noting/internal/domain/ai"
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/job"
+	"github.com/sonsonha/eng-noting/internal/domain/review"
 	wordDomain "github.com/sonsonha/eng-noting/internal/domain/word"
 )
 
 // WordUseCase handles word-related business logic
 type WordUseCase struct {
-	wordRepo wordDomain.WordRepository
-	aiSvc    ai.AIService
+	wordRepo   wordDomain.WordRepository
+	jobRepo    job.JobRepository
+	reviewRepo review.ReviewRepository
+	scheduler  *ReviewScheduler
+	txManager  TxManager
 }
 
 // NewWordUseCase creates a new WordUseCase
-func NewWordUseCase(wordRepo wordDomain.WordRepository, aiSvc ai.AIService) *WordUseCase {
+func NewWordUseCase(
+	wordRepo wordDomain.WordRepository,
+	jobRepo job.JobRepository,
+	reviewRepo review.ReviewRepository,
+	scheduler *ReviewScheduler,
+	txManager TxManager,
+) *WordUseCase {
 	return &WordUseCase{
-		wordRepo: wordRepo,
-		aiSvc:    aiSvc,
+		wordRepo:   wordRepo,
+		jobRepo:    jobRepo,
+		reviewRepo: reviewRepo,
+		scheduler:  scheduler,
+		txManager:  txManager,
 	}
 }
 
 // CreateWordInput represents input for creating a word
 type CreateWordInput struct {
-	UserID  string
-	Text    string
-	Context string
+	UserID      string
+	Text        string
+	Context     string
+	SourceURL   *string
+	SourceTitle *string
 }
 
 // CreateWordOutput represents output from creating a word
 type CreateWordOutput struct {
 	WordID string
+	// Existing is true when the capture matched a word the user already has
+	Existing bool
 }
 
-// CreateWord creates a new word and triggers AI explanation asynchronously
+// CreateWord creates a new word and queues its AI explanation.
+// If the user already has a word with the same lemma, the new context is
+// appended to it instead and the existing word is returned.
 func (uc *WordUseCase) CreateWord(ctx context.Context, input CreateWordInput) (*CreateWordOutput, error) {
-	wordID := uuid.NewString()
-	now := time.Now()
-	confidence := 3
-
-	word := &wordDomain.Word{
-		ID:         wordID,
-		UserID:     input.UserID,
-		Text:       input.Text,
-		Context:    &input.Context,
-		Confidence: &confidence,
-		CreatedAt:  now,
-		UpdatedAt:  now,
+	text := wordDomain.CleanText(input.Text)
+	if text == "" {
+		return nil, ErrBadRequest
 	}
+	lemma := wordDomain.Lemma(text)
+
+	newContext := newWordContext(input.Context, input.SourceURL, input.SourceTitle)
+
+	var out *CreateWordOutput
+	// The lemma is locked so that two captures of the same word at once
+	// cannot both miss it and save it twice
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.wordRepo.LockLemma(ctx, input.UserID, lemma); err != nil {
+			return err
+		}
+
+		existing, err := uc.wordRepo.FindByLemma(ctx, input.UserID, lemma)
+		if err == nil {
+			out, err = uc.recapture(ctx, existing, newContext)
+			return err
+		}
+		if !errors.Is(err, domain.ErrWordNotFound) {
+			return err
+		}
 
-	if err := uc.wordRepo.Create(ctx, word); err != nil {
+		now := time.Now()
+		confidence := 3
+
+		created := &wordDomain.Word{
+			ID:         uuid.NewString(),
+			UserID:     input.UserID,
+			Text:       text,
+			Lemma:      lemma,
+			Confidence: &confidence,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if newContext != nil {
+			newContext.WordID = created.ID
+			created.Contexts = []wordDomain.WordContext{*newContext}
+		}
+		if err := uc.wordRepo.Create(ctx, created); err != nil {
+			return err
+		}
+		// Queued with the word, so that a word is never saved without a
+		// job to explain it
+		if err := uc.enqueueExplanation(ctx, created, nil); err != nil {
+			return err
+		}
+		out = &CreateWordOutput{WordID: created.ID}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Trigger AI explanation asynchronously (non-blocking)
-	go uc.generateAIExplanation(wordID, input.Text, input.Context)
+	return out, nil
+}
 
-	return &CreateWordOutput{WordID: wordID}, nil
+// recapture records a new encounter with a word the user already saved
+func (uc *WordUseCase) recapture(ctx context.Context, word *wordDomain.Word, newContext *wordDomain.WordContext) (*CreateWordOutput, error) {
+	if newContext != nil && !hasContext(word, newContext.Sentence) {
+		newContext.WordID = word.ID
+		if err := uc.wordRepo.AddContext(ctx, word.UserID, newContext); err != nil {
+			return nil, err
+		}
+	}
+
+	// Meeting an archived word again means it is worth reviewing again
+	if word.Archived() {
+		if err := uc.wordRepo.Unarchive(ctx, word.ID, word.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &CreateWordOutput{WordID: word.ID, Existing: true}, nil
 }
 
-// generateAIExplanation generates and stores AI explanation for a word
-func (uc *WordUseCase) generateAIExplanation(wordID, word, wordContext string) {
-	exp, err := uc.aiSvc.ExplainWord(word, wordContext)
-	if err != nil {
-		// Log error but don't fail - word is already created
-		return
+// newWordContext builds a context from a captured sentence, or returns nil
+// if the sentence is empty
+func newWordContext(sentence string, sourceURL, sourceTitle *string) *wordDomain.WordContext {
+	sentence = wordDomain.CleanText(sentence)
+	if sentence == "" {
+		return nil
 	}
+	return &wordDomain.WordContext{
+		ID:          uuid.NewString(),
+		Sentence:    sentence,
+		SourceURL:   sourceURL,
+		SourceTitle: sourceTitle,
+		CapturedAt:  time.Now(),
+	}
+}
 
-	aiData := &wordDomain.WordAIData{
-		WordID:       wordID,
-		Definition:   exp.Definition,
-		ExampleGood:  exp.ExampleGood,
-		ExampleBad:   &exp.ExampleBad,
-		PartOfSpeech: &exp.PartOfSpeech,
-		CEFRLevel:    &exp.CEFRLevel,
-		GeneratedAt:  time.Now(),
+func hasContext(word *wordDomain.Word, sentence string) bool {
+	for _, c := range word.Contexts {
+		if strings.EqualFold(c.Sentence, sentence) {
+			return true
+		}
 	}
+	return false
+}
 
-	// Use background context since this is async
-	ctx := context.Background()
-	_ = uc.wordRepo.StoreAIData(ctx, wordID, aiData)
+// maxExplanationAttempts is how often a word's explanation is attempted
+// before the job is marked as failed
+const maxExplanationAttempts = 5
+
+// enqueueExplanation queues the generation of a word's AI explanation; the
+// ExplanationWorker picks it up
+func (uc *WordUseCase) enqueueExplanation(ctx context.Context, word *wordDomain.Word, hint *string) error {
+	err := uc.jobRepo.Enqueue(ctx, &job.Job{
+		ID:          uuid.NewString(),
+		WordID:      word.ID,
+		UserID:      word.UserID,
+		MaxAttempts: maxExplanationAttempts,
+		Hint:        hint,
+		BaseVersion: aiVersion(word),
+	})
+	if err != nil {
+		return err
+	}
+
+	word.AIStatus = job.StatusPending
+	word.AIError = nil
+	return nil
 }
 
 // GetWordInput represents input for getting a word
@@ -100,7 +202,7 @@ type GetWordOutput struct {
 func (uc *WordUseCase) GetWord(ctx context.Context, input GetWordInput) (*GetWordOutput, error) {
 	word, err := uc.wordRepo.GetByID(ctx, input.WordID, input.UserID)
 	if err != nil {
-		return nil, err
+		return nil, mapDomainError(err)
 	}
 
 	return &GetWordOutput{Word: word}, nil
@@ -109,31 +211,327 @@ func (uc *WordUseCase) GetWord(ctx context.Context, input GetWordInput) (*GetWor
 // ListWordsInput represents input for listing words
 type ListWordsInput struct {
 	UserID string
-	Limit  int
-	Offset int
+	Filter wordDomain.ListFilter
+	Cursor string // next_cursor of the previous page; empty for the first page
 }
 
 // ListWordsOutput represents output from listing words
 type ListWordsOutput struct {
-	Words []*wordDomain.Word
-	Total int
+	Words      []*wordDomain.Word
+	Total      int
+	NextCursor string // empty on the last page
 }
 
-// ListWords retrieves a list of words for a user
+// ListWords retrieves a filtered, sorted page of a user's words
 func (uc *WordUseCase) ListWords(ctx context.Context, input ListWordsInput) (*ListWordsOutput, error) {
-	words, err := uc.wordRepo.List(ctx, input.UserID, input.Limit, input.Offset)
+	filter := input.Filter
+	filter.UserID = input.UserID
+	if filter.Match == "" {
+		filter.Match = wordDomain.MatchSubstring
+	}
+	if filter.Sort == "" {
+		filter.Sort = wordDomain.SortCreatedAt
+		filter.Descending = true
+	}
+
+	if !filter.Match.Valid() || !filter.Sort.Valid() {
+		return nil, ErrBadRequest
+	}
+	if filter.MinConfidence != nil && filter.MaxConfidence != nil && *filter.MinConfidence > *filter.MaxConfidence {
+		return nil, ErrBadRequest
+	}
+	if filter.MinAccuracy != nil && filter.MaxAccuracy != nil && *filter.MinAccuracy > *filter.MaxAccuracy {
+		return nil, ErrBadRequest
+	}
+
+	limit, err := pageSize(filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = limit
+
+	filter.After = nil
+	if input.Cursor != "" {
+		after, err := decodeWordCursor(input.Cursor, filter)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	page, err := uc.wordRepo.List(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	total, err := uc.wordRepo.Count(ctx, input.UserID)
+	// Total counts every matching word, not just the ones after the cursor
+	countFilter := filter
+	countFilter.After = nil
+	total, err := uc.wordRepo.Count(ctx, countFilter)
 	if err != nil {
 		// Fallback to length if count fails
-		total = len(words)
+		total = len(page.Words)
 	}
 
 	return &ListWordsOutput{
-		Words: words,
-		Total: total,
+		Words:      page.Words,
+		Total:      total,
+		NextCursor: encodeWordCursor(page.Next),
 	}, nil
 }
+
+// UpdateWordInput represents input for updating a word.
+// Nil fields are left unchanged.
+type UpdateWordInput struct {
+	WordID     string
+	UserID     string
+	Text       *string
+	Source     *string
+	Confidence *int
+}
+
+// UpdateWordOutput represents output from updating a word
+type UpdateWordOutput struct {
+	Word *wordDomain.Word
+}
+
+// UpdateWord edits a word. Changing the text discards the AI explanation
+// and regenerates it, since it described the old spelling.
+func (uc *WordUseCase) UpdateWord(ctx context.Context, input UpdateWordInput) (*UpdateWordOutput, error) {
+	word, err := uc.wordRepo.GetByID(ctx, input.WordID, input.UserID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	textChanged, lemmaChanged := false, false
+	if input.Text != nil {
+		text := strings.TrimSpace(*input.Text)
+		if text == "" {
+			return nil, ErrBadRequest
+		}
+		text = wordDomain.CleanText(text)
+		textChanged = text != word.Text
+		word.Text = text
+		lemma := wordDomain.Lemma(text)
+		lemmaChanged = lemma != word.Lemma
+		word.Lemma = lemma
+	}
+	if input.Source != nil {
+		word.Source = input.Source
+	}
+	if input.Confidence != nil {
+		if *input.Confidence < 1 || *input.Confidence > 5 {
+			return nil, ErrBadRequest
+		}
+		word.Confidence = input.Confidence
+	}
+	word.UpdatedAt = time.Now()
+
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// A new lemma must not turn the word into a second copy of one the
+		// user already has; those are combined by merging instead
+		if lemmaChanged {
+			if err := uc.wordRepo.LockLemma(ctx, word.UserID, word.Lemma); err != nil {
+				return err
+			}
+			other, err := uc.wordRepo.FindByLemma(ctx, word.UserID, word.Lemma)
+			if err == nil && other.ID != word.ID {
+				return ErrConflict
+			}
+			if err != nil && !errors.Is(err, domain.ErrWordNotFound) {
+				return err
+			}
+		}
+		if err := uc.wordRepo.Update(ctx, word); err != nil {
+			return mapDomainError(err)
+		}
+
+		// The old explanation is of the old text; it goes with the edit,
+		// and a new one is queued with it
+		if textChanged {
+			if err := uc.wordRepo.DeleteAIData(ctx, word.ID); err != nil {
+				return err
+			}
+			word.AIData = nil
+
+			if err := uc.enqueueExplanation(ctx, word, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdateWordOutput{Word: word}, nil
+}
+
+// WordRefInput identifies a single word owned by a user
+type WordRefInput struct {
+	WordID string
+	UserID string
+}
+
+// ArchiveWord hides a word from listings and reviews without losing its history
+func (uc *WordUseCase) ArchiveWord(ctx context.Context, input WordRefInput) error {
+	return mapDomainError(uc.wordRepo.Archive(ctx, input.WordID, input.UserID))
+}
+
+// RetryExplanation queues a new attempt at a word's AI explanation after
+// its job failed. Words whose explanation is queued, running or done are
+// left alone.
+func (uc *WordUseCase) RetryExplanation(ctx context.Context, input WordRefInput) error {
+	word, err := uc.wordRepo.GetByID(ctx, input.WordID, input.UserID)
+	if err != nil {
+		return mapDomainError(err)
+	}
+
+	switch word.AIStatus {
+	case job.StatusFailed:
+		_, err = uc.jobRepo.RetryFailed(ctx, input.UserID, input.WordID)
+		return err
+	case "":
+		// No job was ever recorded, e.g. enqueueing failed after the word was saved
+		return uc.enqueueExplanation(ctx, word, nil)
+	}
+	return ErrConflict
+}
+
+// RetryFailedExplanations queues a new attempt for every failed explanation
+// of a user and returns how many were queued
+func (uc *WordUseCase) RetryFailedExplanations(ctx context.Context, userID string) (int, error) {
+	return uc.jobRepo.RetryFailed(ctx, userID, "")
+}
+
+// UnarchiveWord brings an archived word back into listings and reviews
+func (uc *WordUseCase) UnarchiveWord(ctx context.Context, input WordRefInput) error {
+	return mapDomainError(uc.wordRepo.Unarchive(ctx, input.WordID, input.UserID))
+}
+
+// DeleteWord permanently removes a word together with its AI data and review history
+func (uc *WordUseCase) DeleteWord(ctx context.Context, input WordRefInput) error {
+	return mapDomainError(uc.wordRepo.Delete(ctx, input.WordID, input.UserID))
+}
+
+// MergeWordsInput represents input for merging two words
+type MergeWordsInput struct {
+	UserID       string
+	TargetWordID string
+	SourceWordID string
+}
+
+// MergeWordsOutput represents output from merging two words
+type MergeWordsOutput struct {
+	Word *wordDomain.Word
+}
+
+// MergeWords folds the source word into the target, combining their
+// contexts and review history, and deletes the source. The target's
+// schedule is rebuilt from the combined history.
+func (uc *WordUseCase) MergeWords(ctx context.Context, input MergeWordsInput) (*MergeWordsOutput, error) {
+	if input.SourceWordID == "" || input.SourceWordID == input.TargetWordID {
+		return nil, ErrBadRequest
+	}
+
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.wordRepo.Merge(ctx, input.UserID, input.TargetWordID, input.SourceWordID); err != nil {
+			return mapDomainError(err)
+		}
+		history, err := uc.reviewRepo.ListByWord(ctx, input.TargetWordID)
+		if err != nil {
+			return err
+		}
+		return uc.scheduler.Rebuild(ctx, input.UserID, input.TargetWordID, history)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	word, err := uc.wordRepo.GetByID(ctx, input.TargetWordID, input.UserID)
+	if err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	return &MergeWordsOutput{Word: word}, nil
+}
+
+// AddContextInput represents input for adding a context to a word
+type AddContextInput struct {
+	UserID      string
+	WordID      string
+	Sentence    string
+	SourceURL   *string
+	SourceTitle *string
+}
+
+// AddContextOutput represents output from adding a context
+type AddContextOutput struct {
+	Context *wordDomain.WordContext
+}
+
+// AddContext records another sentence the user met the word in
+func (uc *WordUseCase) AddContext(ctx context.Context, input AddContextInput) (*AddContextOutput, error) {
+	wordContext := newWordContext(input.Sentence, input.SourceURL, input.SourceTitle)
+	if wordContext == nil {
+		return nil, ErrBadRequest
+	}
+	wordContext.WordID = input.WordID
+
+	if err := uc.wordRepo.AddContext(ctx, input.UserID, wordContext); err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	return &AddContextOutput{Context: wordContext}, nil
+}
+
+// UpdateContextInput represents input for editing a context
+type UpdateContextInput struct {
+	UserID      string
+	WordID      string
+	ContextID   string
+	Sentence    string
+	SourceURL   *string
+	SourceTitle *string
+}
+
+// UpdateContext replaces the sentence and source of a context
+func (uc *WordUseCase) UpdateContext(ctx context.Context, input UpdateContextInput) (*AddContextOutput, error) {
+	sentence := wordDomain.CleanText(input.Sentence)
+	if sentence == "" {
+		return nil, ErrBadRequest
+	}
+
+	wordContext := &wordDomain.WordContext{
+		ID:          input.ContextID,
+		WordID:      input.WordID,
+		Sentence:    sentence,
+		SourceURL:   input.SourceURL,
+		SourceTitle: input.SourceTitle,
+	}
+
+	if err := uc.wordRepo.UpdateContext(ctx, input.UserID, wordContext); err != nil {
+		return nil, mapDomainError(err)
+	}
+
+	return &AddContextOutput{Context: wordContext}, nil
+}
+
+// DeleteContextInput represents input for removing a context
+type DeleteContextInput struct {
+	UserID    string
+	WordID    string
+	ContextID string
+}
+
+// DeleteContext removes a context from a word
+func (uc *WordUseCase) DeleteContext(ctx context.Context, input DeleteContextInput) error {
+	return mapDomainError(uc.wordRepo.DeleteContext(ctx, input.UserID, input.WordID, input.ContextID))
+}
+
+// RefreshLemmas recomputes the duplicate-detection keys of words stored
+// under older lemmatization rules, so that new captures keep matching
+// them. It returns how many words it updated.
+func (uc *WordUseCase) RefreshLemmas(ctx context.Context) (int, error) {
+	return uc.wordRepo.RefreshLemmas(ctx)
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sonsonha/eng-noting/internal/domain"
+	"github.com/sonsonha/eng-noting/internal/domain/job"
+	"github.com/sonsonha/eng-noting/internal/domain/review"
+	"github.com/sonsonha/eng-noting/internal/domain/schedule"
+	"github.com/sonsonha/eng-noting/internal/domain/word"
+)
+
+// recordingTx runs fn directly and notes whether a transaction is open
+type recordingTx struct{ open bool }
+
+func (tx *recordingTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx.open = true
+	defer func() { tx.open = false }()
+	return fn(ctx)
+}
+
+// lemmaWords is a word store that logs the calls that decide whether a
+// capture or edit collides with another word, with "(tx)" when made within
+// a transaction
+type lemmaWords struct {
+	word.WordRepository
+	tx    *recordingTx
+	words map[string]*word.Word
+	calls []string
+}
+
+func (r *lemmaWords) record(call string) {
+	if r.tx.open {
+		call += " (tx)"
+	}
+	r.calls = append(r.calls, call)
+}
+
+func (r *lemmaWords) LockLemma(ctx context.Context, userID, lemma string) error {
+	r.record("lock " + lemma)
+	return nil
+}
+
+func (r *lemmaWords) FindByLemma(ctx context.Context, userID, lemma string) (*word.Word, error) {
+	r.record("find " + lemma)
+	for _, w := range r.words {
+		if w.UserID == userID && w.Lemma == lemma {
+			return w, nil
+		}
+	}
+	return nil, domain.ErrWordNotFound
+}
+
+func (r *lemmaWords) GetByID(ctx context.Context, wordID, userID string) (*word.Word, error) {
+	w, ok := r.words[wordID]
+	if !ok || w.UserID != userID {
+		return nil, domain.ErrWordNotFound
+	}
+	copied := *w
+	return &copied, nil
+}
+
+func (r *lemmaWords) Create(ctx context.Context, w *word.Word) error {
+	r.record("create " + w.Lemma)
+	r.words[w.ID] = w
+	return nil
+}
+
+func (r *lemmaWords) Update(ctx context.Context, w *word.Word) error {
+	r.record("update " + w.Lemma)
+	r.words[w.ID] = w
+	return nil
+}
+
+func (r *lemmaWords) DeleteAIData(ctx context.Context, wordID string) error {
+	r.record("delete explanation")
+	return nil
+}
+
+// lemmaJobs logs enqueued explanations alongside the word writes
+type lemmaJobs struct {
+	job.JobRepository
+	words *lemmaWords
+}
+
+func (q lemmaJobs) Enqueue(ctx context.Context, j *job.Job) error {
+	q.words.record("enqueue")
+	return nil
+}
+
+func TestMergeWordsRebuildsTargetSchedule(t *testing.T) {
+	s := newStore()
+	reviews := newReviewUseCase(s)
+	uc := NewWordUseCase(fakeWordRepo{s: s}, nil, fakeReviewRepo{s: s}, reviews.scheduler, fakeTx{s: s})
+
+	if _, err := reviews.SubmitReview(context.Background(), submitInput("")); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	// The source was missed before the target was reviewed
+	missed := time.Now().Add(-time.Hour)
+	s.reviews = append(s.reviews, &review.Review{ID: "r2", WordID: "w2", UserID: "u1", ReviewType: "mcq", ReviewedAt: missed})
+
+	if _, err := uc.MergeWords(context.Background(), MergeWordsInput{UserID: "u1", TargetWordID: "w1", SourceWordID: "w2"}); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	scheduler, err := reviews.scheduler.For(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("scheduler: %v", err)
+	}
+	want := schedule.Replay(scheduler, []schedule.Rated{
+		{Rating: schedule.RatingFor(false, false, nil), At: missed},
+		{Rating: schedule.RatingFor(true, false, nil), At: s.reviews[0].ReviewedAt},
+	})
+	if got := s.states["w1"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the schedule rebuilt from both words' reviews %+v, got %+v", want, got)
+	}
+}
+
+func newLemmaWords(words ...*word.Word) *lemmaWords {
+	r := &lemmaWords{tx: &recordingTx{}, words: map[string]*word.Word{}}
+	for _, w := range words {
+		r.words[w.ID] = w
+	}
+	return r
+}
+
+func TestCreateWordLooksUpLemmaUnderLock(t *testing.T) {
+	words := newLemmaWords()
+	uc := NewWordUseCase(words, lemmaJobs{words: words}, nil, nil, words.tx)
+
+	if _, err := uc.CreateWord(context.Background(), CreateWordInput{UserID: "u1", Text: "Resilient"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	want := []string{"lock resilient (tx)", "find resilient (tx)", "create resilient (tx)", "enqueue (tx)"}
+	if !reflect.DeepEqual(words.calls, want) {
+		t.Fatalf("expected the lookup, insert and job under one lock %v, got %v", want, words.calls)
+	}
+}
+
+func TestUpdateWordRejectsLemmaOfAnotherWord(t *testing.T) {
+	words := newLemmaWords(
+		&word.Word{ID: "w1", UserID: "u1", Text: "plan", Lemma: "plan"},
+		&word.Word{ID: "w2", UserID: "u1", Text: "plane", Lemma: "plane"},
+	)
+	uc := NewWordUseCase(words, lemmaJobs{words: words}, nil, nil, words.tx)
+	text := "planned"
+
+	_, err := uc.UpdateWord(context.Background(), UpdateWordInput{WordID: "w2", UserID: "u1", Text: &text})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if got := words.words["w2"].Text; got != "plane" {
+		t.Fatalf("expected the word left unchanged, got %q", got)
+	}
+	want := []string{"lock plan (tx)", "find plan (tx)"}
+	if !reflect.DeepEqual(words.calls, want) {
+		t.Fatalf("expected only the collision check %v, got %v", want, words.calls)
+	}
+}
+
+func TestUpdateWordRequeuesExplanationWithEdit(t *testing.T) {
+	words := newLemmaWords(&word.Word{ID: "w1", UserID: "u1", Text: "plan", Lemma: "plan"})
+	uc := NewWordUseCase(words, lemmaJobs{words: words}, nil, nil, words.tx)
+	text := "plane"
+
+	if _, err := uc.UpdateWord(context.Background(), UpdateWordInput{WordID: "w1", UserID: "u1", Text: &text}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	want := []string{"lock plane (tx)", "find plane (tx)", "update plane (tx)", "delete explanation (tx)", "enqueue (tx)"}
+	if !reflect.DeepEqual(words.calls, want) {
+		t.Fatalf("expected the new text and its explanation job saved together %v, got %v", want, words.calls)
+	}
+}
@@ -0,0 +1,20 @@
+ALTER TABLE review_queue DROP CONSTRAINT review_queue_word_id_fkey;
+
+ALTER TABLE review_stats
+    DROP CONSTRAINT review_stats_word_id_fkey,
+    ADD CONSTRAINT review_stats_word_id_fkey
+        FOREIGN KEY (word_id) REFERENCES words(id);
+
+ALTER TABLE reviews
+    DROP CONSTRAINT reviews_word_id_fkey,
+    ADD CONSTRAINT reviews_word_id_fkey
+        FOREIGN KEY (word_id) REFERENCES words(id);
+
+ALTER TABLE word_ai_data
+    DROP CONSTRAINT word_ai_data_word_id_fkey,
+    ADD CONSTRAINT word_ai_data_word_id_fkey
+        FOREIGN KEY (word_id) REFERENCES words(id);
+
+DROP INDEX IF EXISTS idx_words_user_active;
+
+ALTER TABLE words DROP COLUMN archived_at;
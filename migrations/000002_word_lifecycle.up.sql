@@ -0,0 +1,26 @@
+-- Soft archive: archived words keep their history but leave the review loop
+ALTER TABLE words ADD COLUMN archived_at TIMESTAMP;
+
+CREATE INDEX idx_words_user_active ON words(user_id) WHERE archived_at IS NULL;
+
+-- Hard delete: everything hanging off a word goes with it
+ALTER TABLE word_ai_data
+    DROP CONSTRAINT word_ai_data_word_id_fkey,
+    ADD CONSTRAINT word_ai_data_word_id_fkey
+        FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE;
+
+ALTER TABLE reviews
+    DROP CONSTRAINT reviews_word_id_fkey,
+    ADD CONSTRAINT reviews_word_id_fkey
+        FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE;
+
+ALTER TABLE review_stats
+    DROP CONSTRAINT review_stats_word_id_fkey,
+    ADD CONSTRAINT review_stats_word_id_fkey
+        FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE;
+
+DELETE FROM review_queue WHERE word_id NOT IN (SELECT id FROM words);
+
+ALTER TABLE review_queue
+    ADD CONSTRAINT review_queue_word_id_fkey
+        FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE;
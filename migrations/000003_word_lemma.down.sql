@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_words_user_lemma;
+
+CREATE INDEX idx_words_text ON words(text);
+
+ALTER TABLE words DROP COLUMN lemma;
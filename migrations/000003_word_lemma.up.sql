@@ -0,0 +1,12 @@
+-- Matching key for duplicate detection, computed by word.Lemma in the app.
+-- Existing rows get the normalized text; RefreshLemmas recomputes it at startup.
+ALTER TABLE words ADD COLUMN lemma TEXT;
+
+UPDATE words SET lemma = lower(regexp_replace(btrim(text), '\s+', ' ', 'g'));
+
+ALTER TABLE words ALTER COLUMN lemma SET NOT NULL;
+
+-- Lookups are always per user, so the bare text index was never used
+DROP INDEX IF EXISTS idx_words_text;
+
+CREATE INDEX idx_words_user_lemma ON words(user_id, lemma);
@@ -0,0 +1,10 @@
+ALTER TABLE words ADD COLUMN context TEXT;
+
+UPDATE words w
+SET context = (
+    SELECT string_agg(c.sentence, E'\n' ORDER BY c.captured_at)
+    FROM word_contexts c
+    WHERE c.word_id = w.id
+);
+
+DROP TABLE word_contexts;
@@ -0,0 +1,25 @@
+CREATE EXTENSION IF NOT EXISTS pgcrypto; -- gen_random_uuid() before PostgreSQL 13
+
+CREATE TABLE word_contexts ( -- Every sentence the word was met in
+    id UUID PRIMARY KEY,
+    word_id UUID NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+    sentence TEXT NOT NULL,
+    source_url TEXT,
+    source_title TEXT,
+    captured_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_word_contexts_word ON word_contexts(word_id, captured_at);
+
+-- words.context held one sentence per line; keep their order
+INSERT INTO word_contexts (id, word_id, sentence, captured_at)
+SELECT
+    gen_random_uuid(),
+    w.id,
+    btrim(line.sentence),
+    w.created_at + (line.n - 1) * interval '1 millisecond'
+FROM words w
+CROSS JOIN LATERAL regexp_split_to_table(w.context, E'\n') WITH ORDINALITY AS line(sentence, n)
+WHERE btrim(line.sentence) <> '';
+
+ALTER TABLE words DROP COLUMN context;
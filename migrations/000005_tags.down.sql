@@ -0,0 +1,2 @@
+DROP TABLE word_tags;
+DROP TABLE tags;
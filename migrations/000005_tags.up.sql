@@ -0,0 +1,17 @@
+CREATE TABLE tags ( -- User-defined tags; a deck is just a tag used to scope reviews
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_tags_user_name ON tags(user_id, lower(name));
+
+CREATE TABLE word_tags (
+    word_id UUID NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (word_id, tag_id)
+);
+
+CREATE INDEX idx_word_tags_tag ON word_tags(tag_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_words_user_created;
+DROP INDEX IF EXISTS idx_word_ai_data_cefr;
+DROP INDEX IF EXISTS idx_word_ai_data_definition_fts;
+DROP INDEX IF EXISTS idx_word_contexts_sentence_fts;
+DROP INDEX IF EXISTS idx_words_text_fts;
+DROP INDEX IF EXISTS idx_word_ai_data_definition_trgm;
+DROP INDEX IF EXISTS idx_word_contexts_sentence_trgm;
+DROP INDEX IF EXISTS idx_words_text_trgm;
@@ -0,0 +1,15 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Substring / prefix search (ILIKE)
+CREATE INDEX idx_words_text_trgm ON words USING gin (text gin_trgm_ops);
+CREATE INDEX idx_word_contexts_sentence_trgm ON word_contexts USING gin (sentence gin_trgm_ops);
+CREATE INDEX idx_word_ai_data_definition_trgm ON word_ai_data USING gin (definition gin_trgm_ops);
+
+-- Full-text search
+CREATE INDEX idx_words_text_fts ON words USING gin (to_tsvector('english', text));
+CREATE INDEX idx_word_contexts_sentence_fts ON word_contexts USING gin (to_tsvector('english', sentence));
+CREATE INDEX idx_word_ai_data_definition_fts ON word_ai_data USING gin (to_tsvector('english', definition));
+
+-- Filters
+CREATE INDEX idx_word_ai_data_cefr ON word_ai_data(cefr_level);
+CREATE INDEX idx_words_user_created ON words(user_id, created_at);
@@ -0,0 +1,24 @@
+CREATE TABLE ai_jobs ( -- One explanation job per word; re-enqueueing resets it
+    id UUID PRIMARY KEY,
+    word_id UUID NOT NULL UNIQUE REFERENCES words(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'failed', 'done')),
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    run_at TIMESTAMP NOT NULL DEFAULT now(),
+    locked_at TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- Workers only look at jobs that can still run
+CREATE INDEX idx_ai_jobs_runnable ON ai_jobs(run_at) WHERE status IN ('pending', 'running');
+CREATE INDEX idx_ai_jobs_user_status ON ai_jobs(user_id, status);
+
+-- Words captured before the queue existed and still missing an explanation
+INSERT INTO ai_jobs (id, word_id, user_id, max_attempts)
+SELECT gen_random_uuid(), w.id, w.user_id, 5
+FROM words w
+WHERE NOT EXISTS (SELECT 1 FROM word_ai_data ai WHERE ai.word_id = w.id);
@@ -0,0 +1,7 @@
+ALTER TABLE ai_jobs DROP COLUMN IF EXISTS hint;
+
+ALTER TABLE word_ai_data
+    DROP COLUMN IF EXISTS source,
+    DROP COLUMN IF EXISTS version;
+
+DROP TABLE IF EXISTS word_ai_data_versions;
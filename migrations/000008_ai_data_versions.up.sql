@@ -0,0 +1,30 @@
+CREATE TABLE word_ai_data_versions ( -- Every explanation a word ever had; word_ai_data holds the current one
+    id UUID PRIMARY KEY,
+    word_id UUID NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    definition TEXT NOT NULL,
+    example_good TEXT NOT NULL,
+    example_bad TEXT,
+    pos TEXT,
+    cefr_level TEXT,
+    source TEXT NOT NULL CHECK (source IN ('ai', 'manual', 'restore')),
+    prompt_version TEXT, -- AI only
+    model TEXT,          -- AI only
+    hint TEXT,           -- AI only, the meaning the user asked for
+    restored_from INT,   -- restore only
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (word_id, version)
+);
+
+ALTER TABLE word_ai_data
+    ADD COLUMN version INT NOT NULL DEFAULT 1,
+    ADD COLUMN source TEXT NOT NULL DEFAULT 'ai';
+
+-- Existing explanations become version 1; their prompt and model are unknown
+INSERT INTO word_ai_data_versions (
+    id, word_id, version, definition, example_good, example_bad, pos, cefr_level, source, created_at
+)
+SELECT gen_random_uuid(), word_id, 1, definition, example_good, example_bad, pos, cefr_level, 'ai', generated_at
+FROM word_ai_data;
+
+ALTER TABLE ai_jobs ADD COLUMN hint TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_word_ai_data_pos;
+DROP TABLE IF EXISTS word_distractors;
@@ -0,0 +1,8 @@
+CREATE TABLE word_distractors ( -- AI-generated wrong options for multiple-choice questions
+    word_id UUID NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (word_id, text)
+);
+
+CREATE INDEX idx_word_ai_data_pos ON word_ai_data(lower(pos));
@@ -0,0 +1,8 @@
+ALTER TABLE review_stats DROP COLUMN IF EXISTS score_sum;
+
+UPDATE review_stats SET accuracy_rate = correct_reviews::float / NULLIF(total_reviews, 0)
+WHERE total_reviews > 0;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS answer,
+    DROP COLUMN IF EXISTS score;
@@ -0,0 +1,13 @@
+-- Graded reviews carry partial credit and the raw answer
+ALTER TABLE reviews
+    ADD COLUMN score FLOAT,
+    ADD COLUMN answer TEXT;
+
+UPDATE reviews SET score = CASE WHEN result THEN 1.0 ELSE 0.0 END;
+
+ALTER TABLE reviews ALTER COLUMN score SET NOT NULL;
+
+-- accuracy_rate becomes the mean score rather than the share of correct reviews
+ALTER TABLE review_stats ADD COLUMN score_sum FLOAT NOT NULL DEFAULT 0;
+
+UPDATE review_stats SET score_sum = correct_reviews;
@@ -0,0 +1,9 @@
+CREATE TABLE word_cloze ( -- Cached fill-in-the-blank exercise of each word
+    word_id UUID PRIMARY KEY REFERENCES words(id) ON DELETE CASCADE,
+    text TEXT NOT NULL, -- word text the cloze was built for
+    sentence TEXT NOT NULL, -- with the word blanked out
+    answer TEXT NOT NULL, -- the word as it appeared in the sentence
+    hint TEXT NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('context', 'ai')),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
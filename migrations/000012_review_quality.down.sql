@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_reviews_word_reviewed_at;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS hesitant,
+    DROP COLUMN IF EXISTS latency_ms,
+    DROP COLUMN IF EXISTS quality;
@@ -0,0 +1,7 @@
+-- How the learner rated their recall and how long they took to answer
+ALTER TABLE reviews
+    ADD COLUMN quality TEXT CHECK (quality IN ('again', 'hard', 'good', 'easy')),
+    ADD COLUMN latency_ms INTEGER CHECK (latency_ms >= 0),
+    ADD COLUMN hesitant BOOLEAN NOT NULL DEFAULT false; -- right but rated hard or slow
+
+CREATE INDEX idx_reviews_word_reviewed_at ON reviews(word_id, reviewed_at DESC);
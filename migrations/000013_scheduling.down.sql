@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS word_schedules;
+DROP TABLE IF EXISTS user_settings;
@@ -0,0 +1,19 @@
+CREATE TABLE user_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    scheduler TEXT NOT NULL DEFAULT 'mps' CHECK (scheduler IN ('mps', 'sm2', 'fsrs')),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE word_schedules ( -- Memory state of each word under the user's scheduler
+    word_id UUID PRIMARY KEY REFERENCES words(id) ON DELETE CASCADE,
+    scheduler TEXT NOT NULL CHECK (scheduler IN ('mps', 'sm2', 'fsrs')),
+    stability FLOAT NOT NULL DEFAULT 0, -- days; the interval for sm2
+    difficulty FLOAT NOT NULL DEFAULT 0, -- 1-10 for fsrs; the ease factor for sm2
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    lapses INTEGER NOT NULL DEFAULT 0,
+    due_at TIMESTAMP, -- NULL for mps, which sets no due dates
+    last_reviewed_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_word_schedules_due_at ON word_schedules(due_at);
@@ -0,0 +1,21 @@
+CREATE TABLE sessions ( -- Review sessions, shared by all API instances
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0, -- index of the current item
+    item_count INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
+
+CREATE TABLE session_items (
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    word_id UUID, -- NULL for matching boards; not a foreign key so deleting a word keeps positions intact
+    review_type TEXT NOT NULL,
+    priority_score FLOAT NOT NULL,
+    reason TEXT NOT NULL,
+    exercise JSONB, -- multiple-choice question, cloze or matching board
+    PRIMARY KEY (session_id, position)
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_sessions_user_created;
+
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS abandoned_at,
+    DROP COLUMN IF EXISTS tag_id;
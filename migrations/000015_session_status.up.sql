@@ -0,0 +1,6 @@
+ALTER TABLE sessions
+    ADD COLUMN tag_id UUID, -- the deck the session was built from; not a foreign key so deleting the tag keeps the session
+    ADD COLUMN abandoned_at TIMESTAMP;
+
+-- Listing a user's sessions, newest first
+CREATE INDEX idx_sessions_user_created ON sessions(user_id, created_at DESC, id DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reviews_session;
+
+ALTER TABLE reviews DROP COLUMN IF EXISTS session_id;
@@ -0,0 +1,5 @@
+-- The session a review was made in. Not a foreign key: sessions expire
+-- and are deleted, their reviews are kept.
+ALTER TABLE reviews ADD COLUMN session_id UUID;
+
+CREATE INDEX idx_reviews_session ON reviews(session_id, reviewed_at) WHERE session_id IS NOT NULL;
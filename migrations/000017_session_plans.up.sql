@@ -0,0 +1,3 @@
+-- Default size, time budget, composition and filters of the user's
+-- sessions; NULL for the built-in defaults
+ALTER TABLE user_settings ADD COLUMN session_plan JSONB;
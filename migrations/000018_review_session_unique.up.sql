@@ -0,0 +1,13 @@
+-- Each word is answered once per session. Unlink repeat answers made
+-- before this was enforced, keeping the first.
+UPDATE reviews SET session_id = NULL
+WHERE id IN (
+	SELECT id FROM (
+		SELECT id, row_number() OVER (PARTITION BY session_id, word_id ORDER BY reviewed_at, id) AS n
+		FROM reviews
+		WHERE session_id IS NOT NULL
+	) ranked
+	WHERE n > 1
+);
+
+CREATE UNIQUE INDEX idx_reviews_session_word ON reviews(session_id, word_id) WHERE session_id IS NOT NULL;
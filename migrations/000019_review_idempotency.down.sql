@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reviews_idempotency;
+
+ALTER TABLE reviews DROP COLUMN IF EXISTS idempotency_key;
@@ -0,0 +1,5 @@
+-- Client-supplied key of a review submission, so that retries and
+-- re-synced offline reviews are recorded once
+ALTER TABLE reviews ADD COLUMN idempotency_key TEXT;
+
+CREATE UNIQUE INDEX idx_reviews_idempotency ON reviews(user_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
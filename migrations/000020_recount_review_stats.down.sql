@@ -0,0 +1 @@
+-- The recount corrects data and is not undone
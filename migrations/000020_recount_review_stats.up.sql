@@ -0,0 +1,18 @@
+-- Reviews used to be counted twice in review_stats; recount every word
+-- from its reviews
+UPDATE review_stats
+SET
+    total_reviews = counted.total_reviews,
+    correct_reviews = counted.correct_reviews,
+    score_sum = counted.score_sum,
+    accuracy_rate = counted.score_sum / counted.total_reviews
+FROM (
+    SELECT
+        word_id,
+        COUNT(*) AS total_reviews,
+        COUNT(*) FILTER (WHERE result = true) AS correct_reviews,
+        COALESCE(SUM(score), 0) AS score_sum
+    FROM reviews
+    GROUP BY word_id
+) AS counted
+WHERE review_stats.word_id = counted.word_id;
@@ -0,0 +1,2 @@
+-- A user's review history is read newest first
+CREATE INDEX idx_reviews_user_reviewed_at ON reviews(user_id, reviewed_at DESC, id DESC);
@@ -0,0 +1 @@
+ALTER TABLE words DROP COLUMN IF EXISTS lemma_version;
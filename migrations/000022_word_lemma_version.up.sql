@@ -0,0 +1,4 @@
+-- Version of the word.Lemma rules each lemma was computed with. Existing
+-- lemmas were computed with older rules (or are plain normalized text,
+-- from 000003); the app recomputes lemmas of an older version on startup.
+ALTER TABLE words ADD COLUMN lemma_version SMALLINT NOT NULL DEFAULT 0;
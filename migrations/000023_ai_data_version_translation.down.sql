@@ -0,0 +1 @@
+ALTER TABLE word_ai_data_versions DROP COLUMN IF EXISTS translation;
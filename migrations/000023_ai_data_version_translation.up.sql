@@ -0,0 +1,9 @@
+-- Translations are part of an explanation's history, so that restoring a
+-- version brings its translation back. Only the current version's
+-- translation is known.
+ALTER TABLE word_ai_data_versions ADD COLUMN translation TEXT;
+
+UPDATE word_ai_data_versions v
+SET translation = ai.translation
+FROM word_ai_data ai
+WHERE ai.word_id = v.word_id AND ai.version = v.version;
@@ -0,0 +1 @@
+ALTER TABLE ai_jobs DROP COLUMN IF EXISTS base_version;
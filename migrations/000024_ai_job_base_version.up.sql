@@ -0,0 +1,9 @@
+-- Explanation version a job was queued against (0 for none). A job whose
+-- word's explanation changed since, e.g. by a manual edit, does not
+-- overwrite it.
+ALTER TABLE ai_jobs ADD COLUMN base_version INT NOT NULL DEFAULT 0;
+
+UPDATE ai_jobs j
+SET base_version = ai.version
+FROM word_ai_data ai
+WHERE ai.word_id = j.word_id;
@@ -0,0 +1,36 @@
+// Package cursor encodes pagination positions as opaque tokens.
+//
+// A token is the base64url-encoded JSON of a position struct owned by the
+// caller, so clients cannot depend on its shape and the server can change
+// it without breaking them.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalid is returned when a token cannot be decoded
+var ErrInvalid = errors.New("invalid cursor")
+
+// Encode turns a position into an opaque token. v must be JSON-serializable.
+func Encode(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode reads a token produced by Encode into v
+func Decode(token string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrInvalid
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return ErrInvalid
+	}
+	return nil
+}
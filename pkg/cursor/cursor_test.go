@@ -0,0 +1,29 @@
+package cursor
+
+import "testing"
+
+type position struct {
+	Key string `json:"k"`
+	ID  string `json:"id"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := position{Key: "2024-01-15 10:30:00.123456", ID: "8f14e45f-ceea-4e7a-a3b1-8b0e1c1f5a10"}
+
+	var out position
+	if err := Decode(Encode(in), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != in {
+		t.Fatalf("expected %+v, got %+v", in, out)
+	}
+}
+
+func TestDecodeRejectsGarbage(t *testing.T) {
+	var out position
+	for _, token := range []string{"not base64!", "bm90IGpzb24"} {
+		if err := Decode(token, &out); err != ErrInvalid {
+			t.Fatalf("expected ErrInvalid for %q, got %v", token, err)
+		}
+	}
+}